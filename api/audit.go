@@ -0,0 +1,154 @@
+package api
+
+import (
+	"github.com/labstack/echo"
+	"github.com/pintobikez/popmeet/api/models"
+	er "github.com/pintobikez/popmeet/errors"
+	repo "github.com/pintobikez/popmeet/repository"
+	stru "github.com/pintobikez/popmeet/secure/structures"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 200
+	maxUserAgent      = 255
+)
+
+type AuditApi struct {
+	rp repo.Repository
+}
+
+func (a *AuditApi) New(rpo repo.Repository) {
+	a.rp = rpo
+}
+
+func (a *AuditApi) SetRepository(rpo repo.Repository) {
+	a.rp = rpo
+}
+
+// GetActivity Handler to GET the recent activity of the account of the logged User
+func (a *AuditApi) GetActivity() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		f, err := auditFilter(c)
+		if err != nil {
+			return err
+		}
+
+		cl := c.Get("claims").(*stru.TokenClaims)
+		f.UserID = cl.ID
+
+		resp, err := traced(c, a.rp).GetAuditEntries(f)
+		if err != nil {
+			return er.From(err)
+		}
+
+		return c.JSON(http.StatusOK, resp)
+	}
+}
+
+// GetAuditLog Handler to GET the entries of the audit log, filtered by user, actor, action and dates
+func (a *AuditApi) GetAuditLog() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		f, err := auditFilter(c)
+		if err != nil {
+			return err
+		}
+
+		for name, v := range map[string]*int64{"user": &f.UserID, "actor": &f.ActorID} {
+			if c.QueryParam(name) == "" {
+				continue
+			}
+			if *v, err = strconv.ParseInt(c.QueryParam(name), 10, 64); err != nil || *v <= 0 {
+				return er.ErrBadRequest.WithDetail("Invalid %s", name)
+			}
+		}
+
+		f.Action = c.QueryParam("action")
+		for name, v := range map[string]**time.Time{"from": &f.From, "to": &f.To} {
+			if c.QueryParam(name) == "" {
+				continue
+			}
+			t, err := time.Parse(time.RFC3339, c.QueryParam(name))
+			if err != nil {
+				return er.ErrBadRequest.WithDetail("Invalid %s, expected a RFC 3339 date", name)
+			}
+			*v = &t
+		}
+
+		resp, err := traced(c, a.rp).GetAuditEntries(f)
+		if err != nil {
+			return er.From(err)
+		}
+
+		return c.JSON(http.StatusOK, resp)
+	}
+}
+
+// auditFilter Reads the limit and before parameters of the audit log pages
+func auditFilter(c echo.Context) (*models.AuditFilter, error) {
+	var err error
+
+	f := &models.AuditFilter{Limit: defaultAuditLimit}
+	if c.QueryParam("limit") != "" {
+		if f.Limit, err = strconv.Atoi(c.QueryParam("limit")); err != nil || f.Limit <= 0 {
+			return nil, er.ErrBadRequest.WithDetail("Invalid limit")
+		}
+		if f.Limit > maxAuditLimit {
+			f.Limit = maxAuditLimit
+		}
+	}
+	if c.QueryParam("before") != "" {
+		if f.Before, err = strconv.ParseInt(c.QueryParam("before"), 10, 64); err != nil || f.Before <= 0 {
+			return nil, er.ErrBadRequest.WithDetail("Invalid before")
+		}
+	}
+
+	return f, nil
+}
+
+// clientIP Gets the ip of the client resolved by the ClientIP middleware, the forwarded headers are
+// only honoured from the trusted proxies. Without the middleware it is the direct peer
+func clientIP(c echo.Context) string {
+	if ip, ok := c.Get("client_ip").(string); ok {
+		return ip
+	}
+	host, _, err := net.SplitHostPort(c.Request().RemoteAddr)
+	if err != nil {
+		return c.Request().RemoteAddr
+	}
+	return host
+}
+
+// audit Appends an action to the audit log with who did it and from where, never with the values changed.
+// The user is the account the action is about, also the actor when nobody is logged in as in the logins.
+// The action is already done, so an entry that can't be written is only logged
+func audit(c echo.Context, rp repo.Repository, action string, userID int64, target string, targetID int64) {
+
+	e := &models.AuditEntry{
+		Action:    action,
+		UserID:    userID,
+		ActorID:   userID,
+		Target:    target,
+		TargetID:  targetID,
+		IP:        clientIP(c),
+		UserAgent: c.Request().UserAgent(),
+		RequestID: c.Response().Header().Get(echo.HeaderXRequestID),
+		CreatedAt: time.Now(),
+	}
+	if cl, ok := c.Get("claims").(*stru.TokenClaims); ok {
+		e.ActorID = cl.ID
+	}
+	if len(e.UserAgent) > maxUserAgent {
+		e.UserAgent = e.UserAgent[:maxUserAgent]
+	}
+
+	if err := traced(c, rp).InsertAuditEntry(e); err != nil {
+		c.Logger().Errorj(map[string]interface{}{"audit": action, "user_id": userID, "target": target, "target_id": targetID, "error": err.Error()})
+	}
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"github.com/labstack/echo"
+	"github.com/pintobikez/popmeet/api/models"
+	er "github.com/pintobikez/popmeet/errors"
+	repo "github.com/pintobikez/popmeet/repository"
+	stru "github.com/pintobikez/popmeet/secure/structures"
+	"net/http"
+	"strconv"
+)
+
+type BlockApi struct {
+	rp repo.Repository
+}
+
+func (a *BlockApi) New(rpo repo.Repository) {
+	a.rp = rpo
+}
+
+func (a *BlockApi) SetRepository(rpo repo.Repository) {
+	a.rp = rpo
+}
+
+// GetBlockedUsers Handler to GET the Users blocked by the logged User
+func (a *BlockApi) GetBlockedUsers() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		cl := c.Get("claims").(*stru.TokenClaims)
+
+		resp, err := traced(c, a.rp).GetBlockedUsersByUserId(cl.ID)
+		if err != nil {
+			return er.From(err)
+		}
+
+		return c.JSON(http.StatusOK, resp)
+	}
+}
+
+// BlockUser Handler to PUT a block on a User
+func (a *BlockApi) BlockUser() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			return er.ErrBadRequest.WithDetail("Invalid id")
+		}
+
+		cl := c.Get("claims").(*stru.TokenClaims)
+		if cl.ID == id {
+			return er.ErrSelfAction.WithDetail("Can't block yourself")
+		}
+
+		//Check if the user exists
+		if _, err = traced(c, a.rp).GetUserById(id); err != nil {
+			return er.From(err, er.ErrUserNotFound)
+		}
+
+		if err = traced(c, a.rp).BlockUser(cl.ID, id); err != nil {
+			return er.From(err)
+		}
+
+		return c.NoContent(http.StatusOK)
+	}
+}
+
+// UnblockUser Handler to DELETE a block on a User
+func (a *BlockApi) UnblockUser() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			return er.ErrBadRequest.WithDetail("Invalid id")
+		}
+
+		cl := c.Get("claims").(*stru.TokenClaims)
+
+		if err = traced(c, a.rp).UnblockUser(cl.ID, id); err != nil {
+			return er.From(err)
+		}
+
+		return c.NoContent(http.StatusOK)
+	}
+}
+
+// containsId Checks if the id is in the given list
+func containsId(ids []int64, id int64) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
+// filterBlockedUsers Removes the blocked users from the given list
+func filterBlockedUsers(users []*models.User, blocked []int64) []*models.User {
+	if len(blocked) == 0 {
+		return users
+	}
+
+	resp := []*models.User{}
+	for _, u := range users {
+		if !containsId(blocked, u.ID) {
+			resp = append(resp, u)
+		}
+	}
+	return resp
+}
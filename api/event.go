@@ -4,21 +4,34 @@ import (
 	"github.com/labstack/echo"
 	"github.com/pintobikez/popmeet/api/models"
 	er "github.com/pintobikez/popmeet/errors"
+	"github.com/pintobikez/popmeet/metrics"
+	"github.com/pintobikez/popmeet/notification"
 	repo "github.com/pintobikez/popmeet/repository"
 	stru "github.com/pintobikez/popmeet/secure/structures"
 	"gopkg.in/go-playground/validator.v9"
 	"net/http"
 	"strconv"
+	"time"
+)
+
+const (
+	defaultFeedLimit = 20
+	maxFeedLimit     = 100
 )
 
 type EventApi struct {
 	rp       repo.Repository
 	validate *validator.Validate
+	notifier notification.Notifier
 }
 
-func (a *EventApi) New(rpo repo.Repository) {
+func (a *EventApi) New(rpo repo.Repository, n notification.Notifier) {
 	a.rp = rpo
 	a.validate = validator.New()
+	a.notifier = n
+	if err := registerTextValidation(a.validate); err != nil {
+		panic(err)
+	}
 }
 
 func (a *EventApi) SetRepository(rpo repo.Repository) {
@@ -31,14 +44,25 @@ func (a *EventApi) GetEvent() echo.HandlerFunc {
 
 		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 		if err != nil {
-			return c.JSON(http.StatusBadRequest, er.GeneralErrorJson(http.StatusBadRequest, err.Error()))
+			return er.ErrBadRequest.WithDetail("Invalid id")
 		}
 
-		resp, err := a.rp.GetEventById(id)
+		resp, err := traced(c, a.rp).GetEventById(id)
 		if err != nil {
-			return c.JSON(http.StatusNotFound, er.GeneralErrorJson(er.ErrorEventNotFound, err.Error()))
+			return er.From(err, er.ErrEventNotFound)
 		}
 
+		// Hide the event and its attendees from blocked users
+		cl := c.Get("claims").(*stru.TokenClaims)
+		blocked, err := traced(c, a.rp).GetBlockRelatedUserIds(cl.ID)
+		if err != nil {
+			return er.From(err)
+		}
+		if containsId(blocked, resp.CreatedBy.ID) {
+			return er.ErrEventNotFound.WithDetail("Event with id %d not found", id)
+		}
+		resp.Users = filterBlockedUsers(resp.Users, blocked)
+
 		return c.JSON(http.StatusOK, resp)
 	}
 }
@@ -49,38 +73,119 @@ func (a *EventApi) PutEvent() echo.HandlerFunc {
 
 		u := new(models.NewEvent)
 		if err := c.Bind(u); err != nil {
-			return c.JSON(http.StatusBadRequest, er.GeneralErrorJson(http.StatusBadRequest, err.Error()))
+			return er.From(err)
 		}
 
 		if err := a.validate.Struct(u); err != nil {
-			return c.JSON(http.StatusUnprocessableEntity, er.ValidationErrorJson(http.StatusUnprocessableEntity, err))
+			return er.Validation(err)
 		}
 
 		cl := c.Get("claims").(*stru.TokenClaims)
 		// Get the user by the claim ID
-		ur, err := a.rp.GetUserById(cl.ID)
+		ur, err := traced(c, a.rp).GetUserById(cl.ID)
 		if err != nil {
-			return c.JSON(http.StatusNotFound, er.GeneralErrorJson(er.ErrorUserNotFound, err.Error()))
+			return er.From(err, er.ErrUserNotFound)
+		}
+		if !ur.EmailVerified {
+			return er.ErrEmailNotVerified.WithDetail("Email must be verified to create events")
 		}
 
 		ev := &models.Event{StartDate: u.StartDate, EndDate: u.EndDate, Location: u.Location, Longitude: u.Longitude, Latitude: u.Latitude, Active: u.Active, CreatedBy: ur}
 
 		//Save the event
-		err = a.rp.InsertEvent(ev)
+		err = traced(c, a.rp).InsertEvent(ev)
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, er.GeneralErrorJson(http.StatusInternalServerError, err.Error()))
+			return er.From(err)
 		}
+		metrics.EventsCreated.Inc()
+		audit(c, a.rp, models.AuditEventCreate, cl.ID, "event", ev.ID)
 
 		//Get the complete info from the event to return it
-		ev, err = a.rp.GetEventById(ev.ID)
+		ev, err = traced(c, a.rp).GetEventById(ev.ID)
 		if err != nil {
-			return c.JSON(http.StatusNotFound, er.GeneralErrorJson(er.ErrorEventNotFound, err.Error()))
+			return er.From(err, er.ErrEventNotFound)
 		}
 
+		//Notify the followers of the creator in a new go routine
+		a.notifyAsync(c, func() error { return a.notifyFollowers(ev) })
+
 		return c.JSON(http.StatusOK, ev)
 	}
 }
 
+// PostEvent Handler to POST changes to an Event, only allowed to its creator
+func (a *EventApi) PostEvent() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			return er.ErrBadRequest.WithDetail("Invalid id")
+		}
+
+		u := new(models.NewEvent)
+		if err := c.Bind(u); err != nil {
+			return er.From(err)
+		}
+
+		if err := a.validate.Struct(u); err != nil {
+			return er.Validation(err)
+		}
+
+		ev, err := a.getOwnEvent(c, id)
+		if err != nil {
+			return err
+		}
+
+		ev.StartDate, ev.EndDate, ev.Location, ev.Longitude, ev.Latitude, ev.Active = u.StartDate, u.EndDate, u.Location, u.Longitude, u.Latitude, u.Active
+		if err = traced(c, a.rp).UpdateEvent(ev); err != nil {
+			return er.From(err)
+		}
+		audit(c, a.rp, models.AuditEventUpdate, ev.CreatedBy.ID, "event", ev.ID)
+
+		//Get the complete info from the event to return it
+		ev, err = traced(c, a.rp).GetEventById(ev.ID)
+		if err != nil {
+			return er.From(err, er.ErrEventNotFound)
+		}
+
+		//Notify the attendees in a new go routine
+		a.notifyAsync(c, func() error {
+			return a.notifyAttendees(ev, notification.TypeEventUpdated)
+		})
+
+		return c.JSON(http.StatusOK, ev)
+	}
+}
+
+// CancelEvent Handler to DELETE an Event, only allowed to its creator
+func (a *EventApi) CancelEvent() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			return er.ErrBadRequest.WithDetail("Invalid id")
+		}
+
+		ev, err := a.getOwnEvent(c, id)
+		if err != nil {
+			return err
+		}
+
+		ev.Active = false
+		if err = traced(c, a.rp).UpdateEvent(ev); err != nil {
+			return er.From(err)
+		}
+		audit(c, a.rp, models.AuditEventCancel, ev.CreatedBy.ID, "event", ev.ID)
+
+		//Notify the attendees in a new go routine
+		a.notifyAsync(c, func() error {
+			return a.notifyAttendees(ev, notification.TypeEventCancelled)
+		})
+
+		return c.NoContent(http.StatusOK)
+	}
+}
+
 // AddUserToEvent Handler to PUT a User in an Event
 func (a *EventApi) AddUserToEvent() echo.HandlerFunc {
 	return func(c echo.Context) error {
@@ -88,38 +193,40 @@ func (a *EventApi) AddUserToEvent() echo.HandlerFunc {
 		// gets the event id
 		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 		if err != nil {
-			return c.JSON(http.StatusBadRequest, er.GeneralErrorJson(http.StatusBadRequest, err.Error()))
+			return er.ErrBadRequest.WithDetail("Invalid id")
 		}
 
 		// Get the user by the claim ID
 		cl := c.Get("claims").(*stru.TokenClaims)
 
 		//Check if the event exists and its active
-		ex, err := a.rp.FindEventById(id)
+		ex, err := traced(c, a.rp).FindEventById(id)
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, er.GeneralErrorJson(http.StatusInternalServerError, err.Error()))
+			return er.From(err)
 		}
 		if !ex {
-			return c.JSON(http.StatusNotFound, er.GeneralErrorJson(er.ErrorEventNotFound, "Event doesn't exist"))
+			return er.ErrEventNotFound
 		}
 
 		//Check if the user exists and its active
-		ex, err = a.rp.FindUserById(cl.ID)
+		ex, err = traced(c, a.rp).FindUserById(cl.ID)
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, er.GeneralErrorJson(http.StatusInternalServerError, err.Error()))
+			return er.From(err)
 		}
 		if !ex {
-			return c.JSON(http.StatusNotFound, er.GeneralErrorJson(er.ErrorUserNotFound, "User doesn't exist"))
+			return er.ErrUserNotFound
 		}
 
 		//Add the user to the event
-		err = a.rp.AddUserToEvent(id, cl.ID)
+		err = traced(c, a.rp).AddUserToEvent(id, cl.ID)
 		if err != nil {
-			if err.Error() == strconv.Itoa(er.ErrorCantAddUSerToEvent) {
-				return c.JSON(http.StatusBadRequest, er.GeneralErrorJson(er.ErrorCantAddUSerToEvent, "Can't add creator as user"))
-			}
-			return c.JSON(http.StatusInternalServerError, er.GeneralErrorJson(http.StatusInternalServerError, err.Error()))
+			// the creator, finished events and blocked users are errors of the catalog
+			return er.From(err)
 		}
+		metrics.EventJoins.Inc()
+
+		//Notify the creator in a new go routine
+		a.notifyAsync(c, func() error { return a.notifyCreator(id, cl.ID) })
 
 		return c.NoContent(http.StatusOK)
 	}
@@ -132,34 +239,34 @@ func (a *EventApi) RemoveUserFromEvent() echo.HandlerFunc {
 		// gets the event id
 		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 		if err != nil {
-			return c.JSON(http.StatusBadRequest, er.GeneralErrorJson(http.StatusBadRequest, err.Error()))
+			return er.ErrBadRequest.WithDetail("Invalid id")
 		}
 
 		// Get the user by the claim ID
 		cl := c.Get("claims").(*stru.TokenClaims)
 
 		//Check if the event exists and its active
-		ex, err := a.rp.FindEventById(id)
+		ex, err := traced(c, a.rp).FindEventById(id)
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, er.GeneralErrorJson(http.StatusInternalServerError, err.Error()))
+			return er.From(err)
 		}
 		if !ex {
-			return c.JSON(http.StatusNotFound, er.GeneralErrorJson(er.ErrorEventNotFound, "Event doesn't exist"))
+			return er.ErrEventNotFound
 		}
 
 		//Check if the user exists and its active
-		ex, err = a.rp.FindUserById(cl.ID)
+		ex, err = traced(c, a.rp).FindUserById(cl.ID)
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, er.GeneralErrorJson(http.StatusInternalServerError, err.Error()))
+			return er.From(err)
 		}
 		if !ex {
-			return c.JSON(http.StatusNotFound, er.GeneralErrorJson(er.ErrorUserNotFound, "User doesn't exist"))
+			return er.ErrUserNotFound
 		}
 
 		//Remove the user from the event
-		err = a.rp.RemoveUserFromEvent(id, cl.ID)
+		err = traced(c, a.rp).RemoveUserFromEvent(id, cl.ID)
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, er.GeneralErrorJson(http.StatusInternalServerError, err.Error()))
+			return er.From(err)
 		}
 
 		return c.NoContent(http.StatusOK)
@@ -173,3 +280,125 @@ func (a *EventApi) FindEvents() echo.HandlerFunc {
 		return c.NoContent(http.StatusOK)
 	}
 }
+
+// GetFeed Handler to GET the upcoming Events created by the Users followed by the logged User
+func (a *EventApi) GetFeed() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		limit := defaultFeedLimit
+		if c.QueryParam("limit") != "" {
+			var err error
+			if limit, err = strconv.Atoi(c.QueryParam("limit")); err != nil || limit <= 0 {
+				return er.ErrBadRequest.WithDetail("Invalid limit")
+			}
+			if limit > maxFeedLimit {
+				limit = maxFeedLimit
+			}
+		}
+
+		cl := c.Get("claims").(*stru.TokenClaims)
+
+		resp, err := traced(c, a.rp).GetFeedEventsByUserId(cl.ID, limit)
+		if err != nil {
+			return er.From(err)
+		}
+
+		return c.JSON(http.StatusOK, resp)
+	}
+}
+
+// getOwnEvent Gets an active event checking that it was created by the logged user.
+// Returns the error of the catalog to answer with
+func (a *EventApi) getOwnEvent(c echo.Context, id int64) (*models.Event, error) {
+
+	ev, err := traced(c, a.rp).GetEventById(id)
+	if err != nil {
+		return nil, er.From(err, er.ErrEventNotFound)
+	}
+	if !ev.Active {
+		return nil, er.ErrEventNotFound.WithDetail("Event with id %d not found", id)
+	}
+
+	cl := c.Get("claims").(*stru.TokenClaims)
+	if ev.CreatedBy.ID != cl.ID {
+		return nil, er.ErrForbidden
+	}
+	if ev.CompletedAt != nil {
+		return nil, er.ErrEventFinished
+	}
+
+	return ev, nil
+}
+
+// notifyAsync Runs the notification function in a new go routine, logging its errors
+func (a *EventApi) notifyAsync(c echo.Context, fn func() error) {
+	go func(lg echo.Logger) {
+		if err := fn(); err != nil {
+			lg.Errorf(err.Error())
+		}
+	}(c.Logger())
+}
+
+// notifyCreator Notifies the creator of an event that a user joined it
+func (a *EventApi) notifyCreator(idEvent int64, idUser int64) error {
+
+	ev, err := a.rp.GetEventById(idEvent)
+	if err != nil {
+		return err
+	}
+	ur, err := a.rp.GetUserById(idUser)
+	if err != nil {
+		return err
+	}
+
+	return a.notifier.Notify([]*models.Notification{{
+		UserID: ev.CreatedBy.ID,
+		Type:   notification.TypeEventUserJoined,
+		Key:    notification.TypeEventUserJoined,
+		Args:   map[string]string{"user": ur.Name, "location": ev.Location, "date": ev.StartDate.Format(time.RFC1123)},
+		Data:   map[string]string{"event_id": strconv.FormatInt(ev.ID, 10), "user_id": strconv.FormatInt(ur.ID, 10)},
+	}})
+}
+
+// notifyAttendees Notifies all the users of an event
+func (a *EventApi) notifyAttendees(ev *models.Event, tp string) error {
+
+	if len(ev.Users) == 0 {
+		return nil
+	}
+
+	ns := make([]*models.Notification, 0, len(ev.Users))
+	for _, u := range ev.Users {
+		ns = append(ns, &models.Notification{
+			UserID: u.ID,
+			Type:   tp,
+			Key:    tp,
+			Args:   map[string]string{"location": ev.Location, "date": ev.StartDate.Format(time.RFC1123)},
+			Data:   map[string]string{"event_id": strconv.FormatInt(ev.ID, 10)},
+		})
+	}
+
+	return a.notifier.Notify(ns)
+}
+
+// notifyFollowers Fans out a notification about a new event to the followers of its creator
+func (a *EventApi) notifyFollowers(ev *models.Event) error {
+
+	ids, err := a.rp.GetFollowerIdsByUserId(ev.CreatedBy.ID)
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+
+	ns := make([]*models.Notification, 0, len(ids))
+	for _, id := range ids {
+		ns = append(ns, &models.Notification{
+			UserID: id,
+			Type:   notification.TypeFollowedUserEvent,
+			Key:    notification.TypeFollowedUserEvent,
+			Args:   map[string]string{"user": ev.CreatedBy.Name, "location": ev.Location, "date": ev.StartDate.Format(time.RFC1123)},
+			Data:   map[string]string{"event_id": strconv.FormatInt(ev.ID, 10)},
+		})
+	}
+
+	return a.notifier.Notify(ns)
+}
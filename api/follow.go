@@ -0,0 +1,129 @@
+package api
+
+import (
+	"github.com/labstack/echo"
+	"github.com/pintobikez/popmeet/api/models"
+	er "github.com/pintobikez/popmeet/errors"
+	repo "github.com/pintobikez/popmeet/repository"
+	stru "github.com/pintobikez/popmeet/secure/structures"
+	"net/http"
+	"strconv"
+)
+
+type FollowApi struct {
+	rp repo.Repository
+}
+
+func (a *FollowApi) New(rpo repo.Repository) {
+	a.rp = rpo
+}
+
+func (a *FollowApi) SetRepository(rpo repo.Repository) {
+	a.rp = rpo
+}
+
+// FollowUser Handler to PUT a follow on a User
+func (a *FollowApi) FollowUser() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			return er.ErrBadRequest.WithDetail("Invalid id")
+		}
+
+		cl := c.Get("claims").(*stru.TokenClaims)
+		if cl.ID == id {
+			return er.ErrSelfAction.WithDetail("Can't follow yourself")
+		}
+
+		//Check if the user exists and its active
+		ex, err := traced(c, a.rp).FindUserById(id)
+		if err != nil {
+			return er.From(err)
+		}
+		if !ex {
+			return er.ErrUserNotFound
+		}
+
+		bl, err := traced(c, a.rp).IsUserBlocked(cl.ID, id)
+		if err != nil {
+			return er.From(err)
+		}
+		if bl {
+			return er.ErrUserBlocked
+		}
+
+		if err = traced(c, a.rp).FollowUser(cl.ID, id); err != nil {
+			return er.From(err)
+		}
+
+		return c.NoContent(http.StatusOK)
+	}
+}
+
+// UnfollowUser Handler to DELETE a follow on a User
+func (a *FollowApi) UnfollowUser() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			return er.ErrBadRequest.WithDetail("Invalid id")
+		}
+
+		cl := c.Get("claims").(*stru.TokenClaims)
+
+		if err = traced(c, a.rp).UnfollowUser(cl.ID, id); err != nil {
+			return er.From(err)
+		}
+
+		return c.NoContent(http.StatusOK)
+	}
+}
+
+// GetFollowers Handler to GET the followers of a User
+func (a *FollowApi) GetFollowers() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			return er.ErrBadRequest.WithDetail("Invalid id")
+		}
+
+		resp, err := traced(c, a.rp).GetFollowersByUserId(id)
+		if err != nil {
+			return er.From(err)
+		}
+
+		return a.filtered(c, resp)
+	}
+}
+
+// GetFollowing Handler to GET the Users followed by a User
+func (a *FollowApi) GetFollowing() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			return er.ErrBadRequest.WithDetail("Invalid id")
+		}
+
+		resp, err := traced(c, a.rp).GetFollowingByUserId(id)
+		if err != nil {
+			return er.From(err)
+		}
+
+		return a.filtered(c, resp)
+	}
+}
+
+// filtered Removes the users related by a block with the logged user and returns the list
+func (a *FollowApi) filtered(c echo.Context, users []*models.User) error {
+
+	cl := c.Get("claims").(*stru.TokenClaims)
+	blocked, err := traced(c, a.rp).GetBlockRelatedUserIds(cl.ID)
+	if err != nil {
+		return er.From(err)
+	}
+
+	return c.JSON(http.StatusOK, filterBlockedUsers(users, blocked))
+}
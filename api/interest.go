@@ -26,13 +26,13 @@ func (a *InterestApi) GetInterest() echo.HandlerFunc {
 
 		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 		if err != nil {
-			return c.JSON(http.StatusBadRequest, er.GeneralErrorJson(http.StatusBadRequest, err.Error()))
+			return er.ErrBadRequest.WithDetail("Invalid id")
 		}
 
-		resp, err := a.rp.GetInterestById(id)
+		resp, err := traced(c, a.rp).GetInterestById(id)
 
 		if err != nil {
-			return c.JSON(http.StatusNotFound, er.GeneralErrorJson(er.ErrorInterestNotFound, err.Error()))
+			return er.From(err, er.ErrInterestNotFound)
 		}
 
 		return c.JSON(http.StatusOK, resp)
@@ -43,9 +43,9 @@ func (a *InterestApi) GetInterest() echo.HandlerFunc {
 func (a *InterestApi) GetAllInterest() echo.HandlerFunc {
 	return func(c echo.Context) error {
 
-		resp, err := a.rp.GetAllInterests()
+		resp, err := traced(c, a.rp).GetAllInterests()
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, er.GeneralErrorJson(er.ErrorInterestsNotFound, err.Error()))
+			return er.From(err)
 		}
 
 		return c.JSON(http.StatusOK, resp)
@@ -0,0 +1,195 @@
+package api
+
+import (
+	"github.com/labstack/echo"
+	"github.com/pintobikez/popmeet/api/models"
+	er "github.com/pintobikez/popmeet/errors"
+	"github.com/pintobikez/popmeet/i18n"
+	repo "github.com/pintobikez/popmeet/repository"
+	"gopkg.in/go-playground/validator.v9"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type LanguageApi struct {
+	rp       repo.Repository
+	validate *validator.Validate
+	cat      *i18n.Catalog
+}
+
+func (a *LanguageApi) New(rpo repo.Repository, cat *i18n.Catalog) {
+	a.rp = rpo
+	a.validate = validator.New()
+	a.cat = cat
+	if err := i18n.RegisterValidation(a.validate); err != nil {
+		panic(err)
+	}
+}
+
+func (a *LanguageApi) SetRepository(rpo repo.Repository) {
+	a.rp = rpo
+}
+
+// GetLanguages Handler to GET the Languages the users can choose
+func (a *LanguageApi) GetLanguages() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		ls, err := traced(c, a.rp).GetAllLanguage()
+		if err != nil {
+			return er.From(err)
+		}
+
+		resp := make([]*models.Language, 0, len(ls))
+		for _, l := range ls {
+			if l.Active {
+				resp = append(resp, l)
+			}
+		}
+
+		return c.JSON(http.StatusOK, resp)
+	}
+}
+
+// GetLanguage Handler to GET a Language, the disabled ones included for the profiles still using them
+func (a *LanguageApi) GetLanguage() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			return er.ErrBadRequest.WithDetail("Invalid id")
+		}
+
+		resp, err := traced(c, a.rp).GetLanguageById(id)
+		if err != nil {
+			return er.From(err, er.ErrLanguageNotFound)
+		}
+
+		return c.JSON(http.StatusOK, resp)
+	}
+}
+
+// GetAllLanguages Handler to GET all the Languages, the disabled ones included
+func (a *LanguageApi) GetAllLanguages() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		resp, err := traced(c, a.rp).GetAllLanguage()
+		if err != nil {
+			return er.From(err)
+		}
+
+		return c.JSON(http.StatusOK, resp)
+	}
+}
+
+// PutLanguage Handler to PUT a new Language, translated with PutTranslation
+func (a *LanguageApi) PutLanguage() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		u := new(models.NewLanguage)
+		if err := c.Bind(u); err != nil {
+			return er.From(err)
+		}
+
+		if err := a.validate.Struct(u); err != nil {
+			return er.Validation(err)
+		}
+
+		if !i18n.MatchISO639(u.NameIso2, u.NameIso3) {
+			return er.ErrBadRequest.WithDetail("%s and %s are not codes of the same language", u.NameIso2, u.NameIso3)
+		}
+
+		l := &models.Language{Name: u.Name, NameIso2: strings.ToUpper(u.NameIso2), NameIso3: strings.ToUpper(u.NameIso3), Active: true}
+		if err := traced(c, a.rp).InsertLanguage(l); err != nil {
+			return er.From(err)
+		}
+
+		audit(c, a.rp, models.AuditLanguageCreate, 0, "language", l.ID)
+
+		return c.JSON(http.StatusOK, l)
+	}
+}
+
+// EnableLanguage Handler to POST the enabling of a Language
+func (a *LanguageApi) EnableLanguage() echo.HandlerFunc {
+	return a.setActive(true)
+}
+
+// DisableLanguage Handler to POST the disabling of a Language, the profiles using it keep it
+func (a *LanguageApi) DisableLanguage() echo.HandlerFunc {
+	return a.setActive(false)
+}
+
+func (a *LanguageApi) setActive(active bool) echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			return er.ErrBadRequest.WithDetail("Invalid id")
+		}
+
+		l, err := traced(c, a.rp).GetLanguageById(id)
+		if err != nil {
+			return er.From(err, er.ErrLanguageNotFound)
+		}
+
+		if !active && strings.EqualFold(l.NameIso2, i18n.DefaultLanguage) {
+			return er.ErrBadRequest.WithDetail("The default language can't be disabled")
+		}
+
+		if err = traced(c, a.rp).SetLanguageActive(id, active); err != nil {
+			return er.From(err)
+		}
+		l.Active = active
+
+		action := models.AuditLanguageDisable
+		if active {
+			action = models.AuditLanguageEnable
+		}
+		audit(c, a.rp, action, 0, "language", id)
+
+		return c.JSON(http.StatusOK, l)
+	}
+}
+
+// GetTranslations Handler to GET the Translations of a language, the embedded ones and the ones set by the admins
+func (a *LanguageApi) GetTranslations() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		lang := strings.ToLower(c.QueryParam("language"))
+		if lang == "" {
+			lang = i18n.DefaultLanguage
+		}
+
+		return c.JSON(http.StatusOK, a.cat.Translations(lang))
+	}
+}
+
+// PutTranslation Handler to PUT the Translation of a key in a language, used at once without a restart
+func (a *LanguageApi) PutTranslation() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		u := new(models.Translation)
+		if err := c.Bind(u); err != nil {
+			return er.From(err)
+		}
+
+		if err := a.validate.Struct(u); err != nil {
+			return er.Validation(err)
+		}
+
+		if !a.cat.Known(u.Key) {
+			return er.ErrTranslationKey.WithDetail("Key %s is not a text of the api", u.Key)
+		}
+
+		u.Language = strings.ToLower(u.Language)
+		if err := traced(c, a.rp).SaveTranslation(u); err != nil {
+			return er.From(err, er.ErrLanguageNotFound)
+		}
+
+		a.cat.Set(u.Language, u.Key, u.Text)
+		audit(c, a.rp, models.AuditTranslationSet, 0, "translation", 0)
+
+		return c.JSON(http.StatusOK, u)
+	}
+}
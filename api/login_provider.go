@@ -0,0 +1,160 @@
+package api
+
+import (
+	"github.com/labstack/echo"
+	"github.com/pintobikez/popmeet/api/models"
+	er "github.com/pintobikez/popmeet/errors"
+	repo "github.com/pintobikez/popmeet/repository"
+	"gopkg.in/go-playground/validator.v9"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type LoginProviderApi struct {
+	rp       repo.Repository
+	validate *validator.Validate
+}
+
+func (a *LoginProviderApi) New(rpo repo.Repository) {
+	a.rp = rpo
+	a.validate = validator.New()
+}
+
+func (a *LoginProviderApi) SetRepository(rpo repo.Repository) {
+	a.rp = rpo
+}
+
+// GetLoginProviders Handler to GET all the Login Providers, without their secrets
+func (a *LoginProviderApi) GetLoginProviders() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		resp, err := traced(c, a.rp).GetAllLoginProvider()
+		if err != nil {
+			return er.From(err)
+		}
+
+		return c.JSON(http.StatusOK, resp)
+	}
+}
+
+// PutLoginProvider Handler to PUT a new Login Provider with its credentials
+func (a *LoginProviderApi) PutLoginProvider() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		u := new(models.NewLoginProvider)
+		if err := c.Bind(u); err != nil {
+			return er.From(err)
+		}
+
+		if err := a.validate.Struct(u); err != nil {
+			return er.Validation(err)
+		}
+
+		now := time.Now()
+		p := &models.LoginProvider{
+			Name:             u.Name,
+			WebClientid:      u.WebClientid,
+			WebSecret:        u.WebSecret,
+			AndroidClientid:  u.AndroidClientid,
+			AndroidSecret:    u.AndroidSecret,
+			IphoneClientid:   u.IphoneClientid,
+			IphoneSecret:     u.IphoneSecret,
+			SecretsRotatedAt: &now,
+			UpdatedAt:        now,
+		}
+		if err := traced(c, a.rp).InsertLoginProvider(p); err != nil {
+			return er.From(err)
+		}
+
+		audit(c, a.rp, models.AuditLoginProviderCreate, 0, "login_provider", p.ID)
+
+		return c.JSON(http.StatusOK, p)
+	}
+}
+
+// PostLoginProvider Handler to POST the name and client ids of a Login Provider, the secrets are changed by RotateSecrets
+func (a *LoginProviderApi) PostLoginProvider() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		p, err := a.provider(c)
+		if err != nil {
+			return err
+		}
+
+		u := new(models.LoginProviderUpdate)
+		if err = c.Bind(u); err != nil {
+			return er.From(err)
+		}
+
+		if err = a.validate.Struct(u); err != nil {
+			return er.Validation(err)
+		}
+
+		p.Name, p.WebClientid, p.AndroidClientid, p.IphoneClientid = u.Name, u.WebClientid, u.AndroidClientid, u.IphoneClientid
+		p.UpdatedAt = time.Now()
+		if err = traced(c, a.rp).UpdateLoginProvider(p); err != nil {
+			return er.From(err)
+		}
+
+		audit(c, a.rp, models.AuditLoginProviderUpdate, 0, "login_provider", p.ID)
+
+		return c.JSON(http.StatusOK, p)
+	}
+}
+
+// RotateSecrets Handler to POST new secrets of a Login Provider, the ones not sent are kept
+func (a *LoginProviderApi) RotateSecrets() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		p, err := a.provider(c)
+		if err != nil {
+			return err
+		}
+
+		u := new(models.LoginProviderSecrets)
+		if err = c.Bind(u); err != nil {
+			return er.From(err)
+		}
+
+		if err = a.validate.Struct(u); err != nil {
+			return er.Validation(err)
+		}
+
+		if u.WebSecret == "" && u.AndroidSecret == "" && u.IphoneSecret == "" {
+			return er.ErrBadRequest.WithDetail("At least one secret must be sent")
+		}
+
+		for _, s := range []struct{ from, to *string }{{&u.WebSecret, &p.WebSecret}, {&u.AndroidSecret, &p.AndroidSecret}, {&u.IphoneSecret, &p.IphoneSecret}} {
+			if *s.from != "" {
+				*s.to = *s.from
+			}
+		}
+
+		now := time.Now()
+		p.SecretsRotatedAt, p.UpdatedAt = &now, now
+		if err = traced(c, a.rp).UpdateLoginProvider(p); err != nil {
+			return er.From(err)
+		}
+
+		audit(c, a.rp, models.AuditLoginProviderRotate, 0, "login_provider", p.ID)
+
+		return c.JSON(http.StatusOK, p)
+	}
+}
+
+// provider Gets the Login Provider of the id parameter
+func (a *LoginProviderApi) provider(c echo.Context) (*models.LoginProvider, error) {
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return nil, er.ErrBadRequest.WithDetail("Invalid id")
+	}
+
+	p, err := traced(c, a.rp).GetLoginProviderById(id)
+	if err != nil {
+		return nil, er.From(err)
+	}
+
+	return p, nil
+}
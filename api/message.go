@@ -0,0 +1,213 @@
+package api
+
+import (
+	"github.com/labstack/echo"
+	"github.com/pintobikez/popmeet/api/models"
+	er "github.com/pintobikez/popmeet/errors"
+	repo "github.com/pintobikez/popmeet/repository"
+	stru "github.com/pintobikez/popmeet/secure/structures"
+	"gopkg.in/go-playground/validator.v9"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultMessageLimit = 50
+	maxMessageLimit     = 100
+)
+
+type MessageApi struct {
+	rp       repo.Repository
+	validate *validator.Validate
+}
+
+func (a *MessageApi) New(rpo repo.Repository) {
+	a.rp = rpo
+	a.validate = validator.New()
+}
+
+func (a *MessageApi) SetRepository(rpo repo.Repository) {
+	a.rp = rpo
+}
+
+// SendMessage Handler to PUT a Message to another User
+func (a *MessageApi) SendMessage() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		// gets the recipient id
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			return er.ErrBadRequest.WithDetail("Invalid id")
+		}
+
+		u := new(models.NewMessage)
+		if err := c.Bind(u); err != nil {
+			return er.From(err)
+		}
+
+		if err := a.validate.Struct(u); err != nil {
+			return er.Validation(err)
+		}
+
+		cl := c.Get("claims").(*stru.TokenClaims)
+		if cl.ID == id {
+			return er.ErrSelfAction.WithDetail("Can't send a message to yourself")
+		}
+
+		//Check if the recipient exists and its active
+		ex, err := traced(c, a.rp).FindUserById(id)
+		if err != nil {
+			return er.From(err)
+		}
+		if !ex {
+			return er.ErrUserNotFound
+		}
+
+		if err = a.canMessage(c, cl.ID, id); err != nil {
+			return er.From(err)
+		}
+
+		m := &models.Message{Sender: cl.ID, Recipient: id, Body: u.Body}
+		if err = traced(c, a.rp).InsertMessage(m); err != nil {
+			return er.From(err)
+		}
+
+		return c.JSON(http.StatusOK, m)
+	}
+}
+
+// GetMessages Handler to GET the Messages exchanged with another User
+func (a *MessageApi) GetMessages() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			return er.ErrBadRequest.WithDetail("Invalid id")
+		}
+
+		var before int64
+		if c.QueryParam("before") != "" {
+			if before, err = strconv.ParseInt(c.QueryParam("before"), 10, 64); err != nil {
+				return er.ErrBadRequest.WithDetail("Invalid before")
+			}
+		}
+
+		limit := defaultMessageLimit
+		if c.QueryParam("limit") != "" {
+			if limit, err = strconv.Atoi(c.QueryParam("limit")); err != nil || limit <= 0 {
+				return er.ErrBadRequest.WithDetail("Invalid limit")
+			}
+			if limit > maxMessageLimit {
+				limit = maxMessageLimit
+			}
+		}
+
+		cl := c.Get("claims").(*stru.TokenClaims)
+
+		if err = a.checkBlocked(c, cl.ID, id); err != nil {
+			return er.From(err)
+		}
+
+		resp, err := traced(c, a.rp).GetMessagesBetweenUsers(cl.ID, id, before, limit)
+		if err != nil {
+			return er.From(err)
+		}
+
+		return c.JSON(http.StatusOK, resp)
+	}
+}
+
+// GetConversations Handler to GET all the Conversations of the logged User
+func (a *MessageApi) GetConversations() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		cl := c.Get("claims").(*stru.TokenClaims)
+
+		resp, err := traced(c, a.rp).GetConversationsByUserId(cl.ID)
+		if err != nil {
+			return er.From(err)
+		}
+
+		return c.JSON(http.StatusOK, resp)
+	}
+}
+
+// MarkAsRead Handler to POST the read receipt of the Messages sent by another User
+func (a *MessageApi) MarkAsRead() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			return er.ErrBadRequest.WithDetail("Invalid id")
+		}
+
+		cl := c.Get("claims").(*stru.TokenClaims)
+
+		if err = a.checkBlocked(c, cl.ID, id); err != nil {
+			return er.From(err)
+		}
+
+		if err = traced(c, a.rp).MarkMessagesAsRead(cl.ID, id); err != nil {
+			return er.From(err)
+		}
+
+		return c.NoContent(http.StatusOK)
+	}
+}
+
+// GetUnreadCount Handler to GET the number of unread Messages of the logged User
+func (a *MessageApi) GetUnreadCount() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		cl := c.Get("claims").(*stru.TokenClaims)
+
+		total, err := traced(c, a.rp).CountUnreadMessages(cl.ID)
+		if err != nil {
+			return er.From(err)
+		}
+
+		return c.JSON(http.StatusOK, &models.UnreadCount{Total: total})
+	}
+}
+
+// canMessage Checks if a user is allowed to send messages to another one.
+// Returns the error of the catalog when it is not allowed
+func (a *MessageApi) canMessage(c echo.Context, idUser int64, idOther int64) error {
+
+	if err := a.checkBlocked(c, idUser, idOther); err != nil {
+		return err
+	}
+
+	sh, err := traced(c, a.rp).HaveSharedEvent(idUser, idOther)
+	if err != nil {
+		return err
+	}
+	if sh {
+		return nil
+	}
+
+	mf, err := traced(c, a.rp).AreMutualFollowers(idUser, idOther)
+	if err != nil {
+		return err
+	}
+	if !mf {
+		return er.ErrMessageNotAllowed.WithDetail("Users must have attended the same event or follow each other to exchange messages")
+	}
+
+	return nil
+}
+
+// checkBlocked Checks that neither user blocked the other one, the conversation between them is closed
+// while they are. Returns the error of the catalog when one of them is blocked
+func (a *MessageApi) checkBlocked(c echo.Context, idUser int64, idOther int64) error {
+
+	bl, err := traced(c, a.rp).IsUserBlocked(idUser, idOther)
+	if err != nil {
+		return err
+	}
+	if bl {
+		return er.ErrUserBlocked
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"context"
+	"github.com/labstack/echo"
+	"github.com/pintobikez/popmeet/api/models"
+	er "github.com/pintobikez/popmeet/errors"
+	repo "github.com/pintobikez/popmeet/repository"
+	stru "github.com/pintobikez/popmeet/secure/structures"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+/*
+Fake repository with the relation between the logged user and the peer, it records the calls made
+without the context of the request
+*/
+type fakeMessageRepository struct {
+	repo.Repository
+	blocked  bool
+	shared   bool
+	mutual   bool
+	traced   bool
+	untraced *[]string
+}
+
+func (f *fakeMessageRepository) WithContext(ctx context.Context) repo.Repository {
+	t := *f
+	t.traced = true
+	return &t
+}
+
+func (f *fakeMessageRepository) record(name string) {
+	if !f.traced {
+		*f.untraced = append(*f.untraced, name)
+	}
+}
+
+func (f *fakeMessageRepository) FindUserById(id int64) (bool, error) {
+	f.record("FindUserById")
+	return true, nil
+}
+
+func (f *fakeMessageRepository) IsUserBlocked(idUser int64, idOther int64) (bool, error) {
+	f.record("IsUserBlocked")
+	return f.blocked, nil
+}
+
+func (f *fakeMessageRepository) HaveSharedEvent(idUser int64, idOther int64) (bool, error) {
+	f.record("HaveSharedEvent")
+	return f.shared, nil
+}
+
+func (f *fakeMessageRepository) AreMutualFollowers(idUser int64, idOther int64) (bool, error) {
+	f.record("AreMutualFollowers")
+	return f.mutual, nil
+}
+
+func (f *fakeMessageRepository) InsertMessage(m *models.Message) error {
+	f.record("InsertMessage")
+	m.ID = 1
+	return nil
+}
+
+func (f *fakeMessageRepository) GetMessagesBetweenUsers(idUser int64, idPeer int64, before int64, limit int) ([]*models.Message, error) {
+	f.record("GetMessagesBetweenUsers")
+	return []*models.Message{}, nil
+}
+
+func (f *fakeMessageRepository) MarkMessagesAsRead(idUser int64, idPeer int64) error {
+	f.record("MarkMessagesAsRead")
+	return nil
+}
+
+/*
+Provider struct for the handlers of MessageApi
+*/
+type providerMessageApi struct {
+	handler string
+	blocked bool
+	shared  bool
+	mutual  bool
+	status  int
+	code    int
+}
+
+var testProviderMessageApi = []providerMessageApi{
+	{"send", true, true, true, http.StatusForbidden, er.ErrUserBlocked.Code},          // the peer blocked the logged user
+	{"send", false, false, true, http.StatusOK, 0},                                    // both follow each other
+	{"send", false, true, false, http.StatusOK, 0},                                    // attended the same event
+	{"send", false, false, false, http.StatusForbidden, er.ErrMessageNotAllowed.Code}, // no relation
+	{"read", true, true, true, http.StatusForbidden, er.ErrUserBlocked.Code},          // blocked conversation
+	{"read", false, false, false, http.StatusOK, 0},                                   // the history is kept without a relation
+	{"mark", true, true, true, http.StatusForbidden, er.ErrUserBlocked.Code},          // blocked conversation
+	{"mark", false, false, false, http.StatusOK, 0},                                   // the history is kept without a relation
+}
+
+/* Test for the block and follow rules of SendMessage, GetMessages and MarkAsRead methods */
+func TestMessageApiRelations(t *testing.T) {
+
+	e := echo.New()
+
+	for _, pair := range testProviderMessageApi {
+
+		var untraced []string
+		a := new(MessageApi)
+		a.New(&fakeMessageRepository{blocked: pair.blocked, shared: pair.shared, mutual: pair.mutual, untraced: &untraced})
+
+		var h echo.HandlerFunc
+		var req *http.Request
+		switch pair.handler {
+		case "send":
+			h = a.SendMessage()
+			req = httptest.NewRequest(echo.PUT, "/conversation/2/message", strings.NewReader(`{"body":"hello"}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		case "read":
+			h = a.GetMessages()
+			req = httptest.NewRequest(echo.GET, "/conversation/2/message", nil)
+		case "mark":
+			h = a.MarkAsRead()
+			req = httptest.NewRequest(echo.POST, "/conversation/2/read", nil)
+		}
+
+		c := e.NewContext(req, httptest.NewRecorder())
+		c.SetParamNames("id")
+		c.SetParamValues("2")
+		c.Set("claims", &stru.TokenClaims{ID: 1})
+
+		status, code := http.StatusOK, 0
+		if err := h(c); err != nil {
+			ae := er.From(err)
+			status, code = ae.Status, ae.Code
+		}
+
+		// Assertions
+		assert.Equal(t, pair.status, status, pair.handler)
+		assert.Equal(t, pair.code, code, pair.handler)
+		assert.Empty(t, untraced, "repository calls without the request context")
+	}
+}
@@ -3,7 +3,7 @@ package models
 import "time"
 
 type EventSearch struct {
-	Location    string      `json:"location" validate:"required,excludesall=!@#?,min=1,max=255"`
+	Location    string      `json:"location" validate:"required,excludesall=!@#?,nocontrol,min=1,max=255"`
 	Longitude   float64     `json:"longitude" validate:"required,numeric"`
 	Latitude    float64     `json:"latitude" validate:"required,numeric"`
 	SearchRange int64       `json:"range" validate:"required,numeric"`
@@ -22,7 +22,7 @@ type LoginUser struct {
 
 type NewUser struct {
 	Email    string `json:"email" validate:"required,email"`
-	Name     string `json:"name" validate:"required,excludesall=!@#?,min=1,max=255"`
+	Name     string `json:"name" validate:"required,excludesall=!@#?,nocontrol,min=1,max=255"`
 	Provider int64  `json:"login_provider" validate:"required,numeric"`
 	Password string `json:"password,omitempty" validate:"omitempty,required"`
 }
@@ -30,11 +30,11 @@ type NewUser struct {
 type NewEvent struct {
 	StartDate time.Time `json:"start_date" validate:"required"`
 	EndDate   time.Time `json:"end_date" validate:"required,gtfield=StartDate"`
-	Location  string    `json:"location" validate:"required,excludesall=!@#?,min=1,max=255"`
+	Location  string    `json:"location" validate:"required,excludesall=!@#?,nocontrol,min=1,max=255"`
 	Longitude float64   `json:"longitude" validate:"required,numeric"`
 	Latitude  float64   `json:"latitude" validate:"required,numeric"`
 	Active    bool      `json:"active" validate:"required"`
-	CreatedBy int64     `json:"created_by validate:"required,numeric"`
+	CreatedBy int64     `json:"-"`
 }
 
 type Interest struct {
@@ -47,19 +47,42 @@ type Language struct {
 	Name     string `json:"name,omitempty" validate:"omitempty,required,alpha,min=1,max=40"`
 	NameIso2 string `json:"name_iso2,omitempty" validate:"omitempty,required,alpha,len=2"`
 	NameIso3 string `json:"name_iso3,omitempty" validate:"omitempty,required,alpha,len=3"`
+	Active   bool   `json:"active"`
+}
+
+// NewLanguage is a language added by the admins, the codes are ISO 639-1 and ISO 639-2 of the same language
+type NewLanguage struct {
+	Name     string `json:"name" validate:"required,min=1,max=40"`
+	NameIso2 string `json:"name_iso2" validate:"required,len=2,iso639_1"`
+	NameIso3 string `json:"name_iso3" validate:"required,len=3,iso639_2"`
+}
+
+// SpokenLanguage is a language spoken by a user
+type SpokenLanguage struct {
+	Language    *Language `json:"language" validate:"required"`
+	Proficiency string    `json:"proficiency" validate:"required,oneof=basic conversational fluent native"`
+}
+
+// Translation is a text of the api in a language, the language is its ISO 639-1 code
+type Translation struct {
+	Language  string     `json:"language" validate:"required,alpha,len=2"`
+	Key       string     `json:"key" validate:"required,min=1,max=150"`
+	Text      string     `json:"text" validate:"required,min=1,max=1000"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
 }
 
 type Event struct {
-	ID        int64     `json:"id" validate:"required,numeric"`
-	CreatedAt time.Time `json:"created_at"`
-	StartDate time.Time `json:"start_date" validate:"required"`
-	EndDate   time.Time `json:"end_date" validate:"required,gtfield=StartDate"`
-	Location  string    `json:"location" validate:"required,excludesall=!@#?,min=1,max=255"`
-	Longitude float64   `json:"longitude" validate:"required,numeric"`
-	Latitude  float64   `json:"latitude" validate:"required,numeric"`
-	Active    bool      `json:"active" validate:"required"`
-	CreatedBy *User     `json:"created_by"`
-	Users     []*User   `json:"users"`
+	ID          int64      `json:"id" validate:"required,numeric"`
+	CreatedAt   time.Time  `json:"created_at"`
+	StartDate   time.Time  `json:"start_date" validate:"required"`
+	EndDate     time.Time  `json:"end_date" validate:"required,gtfield=StartDate"`
+	Location    string     `json:"location" validate:"required,excludesall=!@#?,nocontrol,min=1,max=255"`
+	Longitude   float64    `json:"longitude" validate:"required,numeric"`
+	Latitude    float64    `json:"latitude" validate:"required,numeric"`
+	Active      bool       `json:"active" validate:"required"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	CreatedBy   *User      `json:"created_by"`
+	Users       []*User    `json:"users"`
 }
 
 type EventUsers struct {
@@ -68,14 +91,20 @@ type EventUsers struct {
 }
 
 type User struct {
-	ID        int64         `json:"id" validate:"required,numeric"`
-	Email     string        `json:"email,omitempty" validate:"omitempty,required,email"`
-	Name      string        `json:"name" validate:"required,excludesall=!@#?,min=1,max=255"`
-	CreatedAt time.Time     `json:"created_at,omitempty"`
-	UpdatedAt time.Time     `json:"updated_at,omitempty"`
-	Active    bool          `json:"active,omitempty" validate:"omitempty,required"`
-	Profile   *UserProfile  `json:"profile,omitempty" validate:"omitempty,required,dive"`
-	Security  *UserSecurity `json:"security,omitempty" validate:"omitempty,required,dive"`
+	ID            int64     `json:"id" validate:"required,numeric"`
+	Email         string    `json:"email,omitempty" validate:"omitempty,required,email"`
+	Name          string    `json:"name" validate:"required,excludesall=!@#?,nocontrol,min=1,max=255"`
+	CreatedAt     time.Time `json:"created_at,omitempty"`
+	UpdatedAt     time.Time `json:"updated_at,omitempty"`
+	Active        bool      `json:"active,omitempty" validate:"omitempty,required"`
+	Role          string    `json:"role,omitempty" validate:"omitempty,oneof=user admin"`
+	EmailVerified bool      `json:"email_verified"`
+	// PendingEmail is the new email of the user, set until it is confirmed
+	PendingEmail string `json:"pending_email,omitempty"`
+	// DeletionAt is when the account is erased, set while it waits for the erasure
+	DeletionAt *time.Time    `json:"deletion_at,omitempty"`
+	Profile    *UserProfile  `json:"profile,omitempty" validate:"omitempty,required,dive"`
+	Security   *UserSecurity `json:"security,omitempty" validate:"omitempty,required,dive"`
 }
 
 type UserProfile struct {
@@ -85,25 +114,345 @@ type UserProfile struct {
 	AgeRange  string      `json:"age_range" validate:"required,oneof=18-25 26-32 33-39 40-46 47-53 54-60 61-70 +70"`
 	UpdatedAt time.Time   `json:"updated_at,omitempty"`
 	Interests []*Interest `json:"interests,omitempty" validate:"omitempty,required,dive"`
+	// Languages spoken by the user, Language is the one of the api texts
+	Languages []*SpokenLanguage `json:"languages,omitempty" validate:"omitempty,max=10,dive"`
 }
 
+// LoginProvider is an OAuth client of the api, its secrets are stored encrypted and never serialized
 type LoginProvider struct {
-	ID              int64     `json:"id" validate:"required,numeric"`
-	Name            string    `json:"name,omitempty"`
-	WebClientid     string    `json:"web_clientid,omitempty"`
-	WebSecret       string    `json:"web_secret,omitempty"`
-	AndroidClientid string    `json:"android_clientid,omitempty"`
-	AndroidSecret   string    `json:"android_secret,omitempty"`
-	IphoneClientid  string    `json:"iphone_clientid,omitempty"`
-	IphoneSecret    string    `json:"iphone_secret,omitempty"`
-	UpdatedAt       time.Time `json:"updated_at,omitempty"`
+	ID               int64      `json:"id" validate:"required,numeric"`
+	Name             string     `json:"name,omitempty"`
+	WebClientid      string     `json:"web_clientid,omitempty"`
+	WebSecret        string     `json:"-"`
+	AndroidClientid  string     `json:"android_clientid,omitempty"`
+	AndroidSecret    string     `json:"-"`
+	IphoneClientid   string     `json:"iphone_clientid,omitempty"`
+	IphoneSecret     string     `json:"-"`
+	SecretsRotatedAt *time.Time `json:"secrets_rotated_at,omitempty"`
+	UpdatedAt        time.Time  `json:"updated_at,omitempty"`
+}
+
+type NewLoginProvider struct {
+	Name            string `json:"name" validate:"required,min=1,max=255"`
+	WebClientid     string `json:"web_clientid" validate:"max=255"`
+	WebSecret       string `json:"web_secret" validate:"max=255"`
+	AndroidClientid string `json:"android_clientid" validate:"max=255"`
+	AndroidSecret   string `json:"android_secret" validate:"max=255"`
+	IphoneClientid  string `json:"iphone_clientid" validate:"max=255"`
+	IphoneSecret    string `json:"iphone_secret" validate:"max=255"`
+}
+
+type LoginProviderUpdate struct {
+	Name            string `json:"name" validate:"required,min=1,max=255"`
+	WebClientid     string `json:"web_clientid" validate:"max=255"`
+	AndroidClientid string `json:"android_clientid" validate:"max=255"`
+	IphoneClientid  string `json:"iphone_clientid" validate:"max=255"`
+}
+
+// LoginProviderSecrets are the new secrets of a provider, the empty ones are kept
+type LoginProviderSecrets struct {
+	WebSecret     string `json:"web_secret" validate:"max=255"`
+	AndroidSecret string `json:"android_secret" validate:"max=255"`
+	IphoneSecret  string `json:"iphone_secret" validate:"max=255"`
 }
 
 type UserSecurity struct {
-	ID          int64          `json:"id" validate:"required,numeric"`
-	Provider    *LoginProvider `json:"login_provider" validate:"required,dive"`
-	Hash        string         `json:"-"`
-	LastMachine string         `json:"last_machine,omitempty"`
-	LastLogin   time.Time      `json:"last_login,omitempty"`
-	UpdatedAt   time.Time      `json:"updated_at,omitempty"`
+	ID             int64          `json:"id" validate:"required,numeric"`
+	Provider       *LoginProvider `json:"login_provider" validate:"required,dive"`
+	Hash           string         `json:"-"`
+	SessionVersion int64          `json:"-"`
+	TotpSecret     string         `json:"-"`
+	TwoFactor      bool           `json:"two_factor_enabled"`
+	FailedAttempts int64          `json:"-"`
+	LastFailedAt   *time.Time     `json:"-"`
+	LockedUntil    *time.Time     `json:"-"`
+	LastMachine    string         `json:"last_machine,omitempty"`
+	LastLogin      time.Time      `json:"last_login,omitempty"`
+	UpdatedAt      time.Time      `json:"updated_at,omitempty"`
+}
+
+type NewMessage struct {
+	Body string `json:"body" validate:"required,min=1,max=2000"`
+}
+
+type Message struct {
+	ID        int64      `json:"id" validate:"required,numeric"`
+	Sender    int64      `json:"sender_id"`
+	Recipient int64      `json:"recipient_id"`
+	Body      string     `json:"body"`
+	CreatedAt time.Time  `json:"created_at"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+}
+
+type Conversation struct {
+	User        *User    `json:"user"`
+	LastMessage *Message `json:"last_message"`
+	Unread      int64    `json:"unread"`
+}
+
+type UnreadCount struct {
+	Total int64 `json:"total"`
+}
+
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+
+	ReportStatusOpen             = "open"
+	ReportStatusDismissed        = "dismissed"
+	ReportStatusWarned           = "warned"
+	ReportStatusUserDeactivated  = "user_deactivated"
+	ReportStatusEventDeactivated = "event_deactivated"
+
+	ModerationDismiss         = "dismiss"
+	ModerationWarn            = "warn"
+	ModerationDeactivateUser  = "deactivate_user"
+	ModerationDeactivateEvent = "deactivate_event"
+)
+
+// ModerationReportStatus maps each moderation action to the report status it leads to
+var ModerationReportStatus = map[string]string{
+	ModerationDismiss:         ReportStatusDismissed,
+	ModerationWarn:            ReportStatusWarned,
+	ModerationDeactivateUser:  ReportStatusUserDeactivated,
+	ModerationDeactivateEvent: ReportStatusEventDeactivated,
+}
+
+type NewReport struct {
+	UserID  int64  `json:"user_id,omitempty" validate:"omitempty,numeric"`
+	EventID int64  `json:"event_id,omitempty" validate:"omitempty,numeric"`
+	Reason  string `json:"reason" validate:"required,min=1,max=1000"`
+}
+
+type Report struct {
+	ID        int64     `json:"id" validate:"required,numeric"`
+	Reporter  *User     `json:"reporter,omitempty"`
+	User      *User     `json:"user,omitempty"`
+	Event     *Event    `json:"event,omitempty"`
+	Reason    string    `json:"reason"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+}
+
+type NewModerationAction struct {
+	Action string `json:"action" validate:"required,oneof=dismiss warn deactivate_user deactivate_event"`
+	Reason string `json:"reason" validate:"required,min=1,max=1000"`
+}
+
+type ModerationAction struct {
+	ID        int64     `json:"id" validate:"required,numeric"`
+	ReportID  int64     `json:"report_id"`
+	Moderator *User     `json:"moderator"`
+	Action    string    `json:"action"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ReportContext struct {
+	Report         *Report             `json:"report"`
+	Actions        []*ModerationAction `json:"actions"`
+	RelatedReports []*Report           `json:"related_reports"`
+}
+
+type Notification struct {
+	ID        int64             `json:"id"`
+	UserID    int64             `json:"user_id"`
+	Type      string            `json:"type"`
+	Title     string            `json:"title"`
+	Body      string            `json:"body"`
+	Data      map[string]string `json:"data,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	ReadAt    *time.Time        `json:"read_at,omitempty"`
+	// Key and Args of the translation of the title and body, written in the language of the user
+	Key  string            `json:"-"`
+	Args map[string]string `json:"-"`
+}
+
+type NotificationPreference struct {
+	Type    string `json:"type" validate:"required,min=1,max=50"`
+	Channel string `json:"channel" validate:"required,oneof=email push"`
+	Enabled bool   `json:"enabled"`
+}
+
+const (
+	JobStatusPending = "pending"
+	JobStatusRunning = "running"
+	JobStatusDone    = "done"
+	JobStatusFailed  = "failed"
+)
+
+type Job struct {
+	ID        int64     `json:"id"`
+	Type      string    `json:"type"`
+	RefID     int64     `json:"ref_id"`
+	RunAt     time.Time `json:"run_at"`
+	Status    string    `json:"status"`
+	Attempts  int       `json:"attempts"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+const (
+	TokenVerifyEmail   = "verify_email"
+	TokenResetPassword = "reset_password"
+	TokenChangeEmail   = "change_email"
+)
+
+type UserToken struct {
+	ID        int64
+	UserID    int64
+	Purpose   string
+	Hash      string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	// Target is the email a change_email token was sent to, the only one it confirms
+	Target string
+}
+
+type RedeemToken struct {
+	Token string `json:"token" validate:"required,min=1,max=255"`
+}
+
+type ChangeEmail struct {
+	Email    string `json:"email" validate:"required,email,max=100"`
+	Password string `json:"password"`
+}
+
+type ForgotPassword struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPassword struct {
+	Token    string `json:"token" validate:"required,min=1,max=255"`
+	Password string `json:"password" validate:"required"`
+}
+
+type TwoFactorEnrollment struct {
+	Secret string `json:"secret"`
+	Uri    string `json:"otpauth_uri"`
+}
+
+type TwoFactorCode struct {
+	Code string `json:"code" validate:"required,min=6,max=20"`
+}
+
+type TwoFactorLogin struct {
+	Challenge string `json:"challenge_token" validate:"required"`
+	Code      string `json:"code" validate:"required,min=6,max=20"`
+}
+
+type LoginChallenge struct {
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	Challenge         string `json:"challenge_token"`
+}
+
+type RecoveryCodes struct {
+	Codes []string `json:"recovery_codes"`
+}
+
+type ChangePassword struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required"`
+}
+
+const (
+	AuditLoginSuccess        = "login.success"
+	AuditLoginFailure        = "login.failure"
+	AuditPasswordChange      = "password.change"
+	AuditPasswordReset       = "password.reset"
+	AuditEmailChange         = "email.change"
+	AuditEmailChangeRequest  = "email.change_request"
+	AuditEmailVerify         = "email.verify"
+	AuditEmailDuplicate      = "email.duplicate"
+	AuditProviderChange      = "provider.change"
+	AuditRoleChange          = "role.change"
+	AuditTwoFactorEnable     = "2fa.enable"
+	AuditTwoFactorDisable    = "2fa.disable"
+	AuditEventCreate         = "event.create"
+	AuditEventUpdate         = "event.update"
+	AuditEventCancel         = "event.cancel"
+	AuditReportResolve       = "report.resolve"
+	AuditUserUnlock          = "user.unlock"
+	AuditLanguageCreate      = "language.create"
+	AuditLanguageEnable      = "language.enable"
+	AuditLanguageDisable     = "language.disable"
+	AuditTranslationSet      = "translation.set"
+	AuditLoginProviderCreate = "login_provider.create"
+	AuditLoginProviderUpdate = "login_provider.update"
+	AuditLoginProviderRotate = "login_provider.rotate"
+	AuditAccountExport       = "account.export"
+	AuditAccountDelete       = "account.delete"
+	AuditAccountRestore      = "account.restore"
+	AuditAccountErase        = "account.erase"
+)
+
+// AuditEntry is a security sensitive action, the audit log is never changed but to remove the ip and
+// user agent of the erased accounts.
+// UserID is the account the action is about and ActorID who did it, the same user or an admin
+type AuditEntry struct {
+	ID        int64     `json:"id"`
+	Action    string    `json:"action"`
+	UserID    int64     `json:"user_id,omitempty"`
+	ActorID   int64     `json:"actor_id,omitempty"`
+	Target    string    `json:"target,omitempty"`
+	TargetID  int64     `json:"target_id,omitempty"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	RequestID string    `json:"request_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AuditFilter filters the audit log, the zero values don't filter. Before is the id to page from, newest first
+type AuditFilter struct {
+	UserID  int64
+	ActorID int64
+	Action  string
+	From    *time.Time
+	To      *time.Time
+	Before  int64
+	Limit   int
+}
+
+type UserRole struct {
+	Role string `json:"role" validate:"required,oneof=user admin"`
+}
+
+const (
+	HostedEventsTransfer = "transfer"
+	HostedEventsCancel   = "cancel"
+)
+
+// DeleteAccount asks the erasure of the account of the logged user, the upcoming events it hosts are
+// transferred to one of their attendees or cancelled. The password is required to the users that have one
+type DeleteAccount struct {
+	Password     string `json:"password"`
+	HostedEvents string `json:"hosted_events" validate:"required,oneof=transfer cancel"`
+}
+
+// AccountErasure is an account whose grace period is over
+type AccountErasure struct {
+	UserID       int64
+	HostedEvents string
+}
+
+// HandedOverEvent is an upcoming event of an erased account, transferred to NewHost or cancelled when it is 0
+type HandedOverEvent struct {
+	Event     *Event
+	NewHost   int64
+	Attendees []int64
+}
+
+// UserExport is all the data about a user
+type UserExport struct {
+	ExportedAt              time.Time                 `json:"exported_at"`
+	User                    *User                     `json:"user"`
+	HostedEvents            []*Event                  `json:"hosted_events"`
+	JoinedEvents            []*Event                  `json:"joined_events"`
+	Messages                []*Message                `json:"messages"`
+	Following               []*User                   `json:"following"`
+	Followers               []*User                   `json:"followers"`
+	Blocked                 []*User                   `json:"blocked"`
+	Notifications           []*Notification           `json:"notifications"`
+	NotificationPreferences []*NotificationPreference `json:"notification_preferences"`
+	Activity                []*AuditEntry             `json:"activity"`
 }
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"github.com/labstack/echo"
+	"github.com/pintobikez/popmeet/api/models"
+	er "github.com/pintobikez/popmeet/errors"
+	repo "github.com/pintobikez/popmeet/repository"
+	stru "github.com/pintobikez/popmeet/secure/structures"
+	"gopkg.in/go-playground/validator.v9"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultNotificationLimit = 50
+	maxNotificationLimit     = 100
+)
+
+type NotificationApi struct {
+	rp       repo.Repository
+	validate *validator.Validate
+}
+
+func (a *NotificationApi) New(rpo repo.Repository) {
+	a.rp = rpo
+	a.validate = validator.New()
+}
+
+func (a *NotificationApi) SetRepository(rpo repo.Repository) {
+	a.rp = rpo
+}
+
+// GetNotifications Handler to GET the inbox of the logged User
+func (a *NotificationApi) GetNotifications() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		limit := defaultNotificationLimit
+		if c.QueryParam("limit") != "" {
+			var err error
+			if limit, err = strconv.Atoi(c.QueryParam("limit")); err != nil || limit <= 0 {
+				return er.ErrBadRequest.WithDetail("Invalid limit")
+			}
+			if limit > maxNotificationLimit {
+				limit = maxNotificationLimit
+			}
+		}
+
+		cl := c.Get("claims").(*stru.TokenClaims)
+
+		resp, err := traced(c, a.rp).GetNotificationsByUserId(cl.ID, c.QueryParam("unread") == "true", limit)
+		if err != nil {
+			return er.From(err)
+		}
+
+		return c.JSON(http.StatusOK, resp)
+	}
+}
+
+// MarkAsRead Handler to POST the read of a Notification of the logged User
+func (a *NotificationApi) MarkAsRead() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil || id <= 0 {
+			return er.ErrBadRequest.WithDetail("Invalid notification id")
+		}
+
+		cl := c.Get("claims").(*stru.TokenClaims)
+
+		if err = traced(c, a.rp).MarkNotificationsAsRead(cl.ID, id); err != nil {
+			return er.From(err)
+		}
+
+		return c.NoContent(http.StatusOK)
+	}
+}
+
+// MarkAllAsRead Handler to POST the read of all the Notifications of the logged User
+func (a *NotificationApi) MarkAllAsRead() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		cl := c.Get("claims").(*stru.TokenClaims)
+
+		if err := traced(c, a.rp).MarkNotificationsAsRead(cl.ID, 0); err != nil {
+			return er.From(err)
+		}
+
+		return c.NoContent(http.StatusOK)
+	}
+}
+
+// GetPreferences Handler to GET the notification channel preferences of the logged User
+func (a *NotificationApi) GetPreferences() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		cl := c.Get("claims").(*stru.TokenClaims)
+
+		resp, err := traced(c, a.rp).GetNotificationPreferencesByUserId(cl.ID)
+		if err != nil {
+			return er.From(err)
+		}
+
+		return c.JSON(http.StatusOK, resp)
+	}
+}
+
+// PostPreferences Handler to POST notification channel preferences of the logged User
+func (a *NotificationApi) PostPreferences() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		ps := []*models.NotificationPreference{}
+		if err := c.Bind(&ps); err != nil {
+			return er.From(err)
+		}
+
+		for _, p := range ps {
+			if err := a.validate.Struct(p); err != nil {
+				return er.Validation(err)
+			}
+		}
+
+		cl := c.Get("claims").(*stru.TokenClaims)
+
+		if err := traced(c, a.rp).UpdateNotificationPreferences(cl.ID, ps); err != nil {
+			return er.From(err)
+		}
+
+		resp, err := traced(c, a.rp).GetNotificationPreferencesByUserId(cl.ID)
+		if err != nil {
+			return er.From(err)
+		}
+
+		return c.JSON(http.StatusOK, resp)
+	}
+}
@@ -0,0 +1,191 @@
+package api
+
+import (
+	"github.com/labstack/echo"
+	"github.com/pintobikez/popmeet/api/models"
+	er "github.com/pintobikez/popmeet/errors"
+	repo "github.com/pintobikez/popmeet/repository"
+	stru "github.com/pintobikez/popmeet/secure/structures"
+	"gopkg.in/go-playground/validator.v9"
+	"net/http"
+	"strconv"
+)
+
+type ReportApi struct {
+	rp       repo.Repository
+	validate *validator.Validate
+}
+
+func (a *ReportApi) New(rpo repo.Repository) {
+	a.rp = rpo
+	a.validate = validator.New()
+}
+
+func (a *ReportApi) SetRepository(rpo repo.Repository) {
+	a.rp = rpo
+}
+
+// PutReport Handler to PUT a Report about a User or an Event into the moderation queue
+func (a *ReportApi) PutReport() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		u := new(models.NewReport)
+		if err := c.Bind(u); err != nil {
+			return er.From(err)
+		}
+
+		if err := a.validate.Struct(u); err != nil {
+			return er.Validation(err)
+		}
+
+		// a report targets a user or an event, never both
+		if (u.UserID > 0) == (u.EventID > 0) {
+			return er.ErrReportTarget.WithDetail("A report must target either a user or an event")
+		}
+
+		cl := c.Get("claims").(*stru.TokenClaims)
+		rp := &models.Report{Reporter: &models.User{ID: cl.ID}, Reason: u.Reason}
+
+		if u.UserID > 0 {
+			if u.UserID == cl.ID {
+				return er.ErrReportTarget.WithDetail("Can't report yourself")
+			}
+			ur, err := traced(c, a.rp).GetUserById(u.UserID)
+			if err != nil {
+				return er.From(err, er.ErrUserNotFound)
+			}
+			rp.User = ur
+		}
+
+		if u.EventID > 0 {
+			ev, err := traced(c, a.rp).GetEventById(u.EventID)
+			if err != nil {
+				return er.From(err, er.ErrEventNotFound)
+			}
+			rp.Event = ev
+		}
+
+		if err := traced(c, a.rp).InsertReport(rp); err != nil {
+			return er.From(err)
+		}
+
+		return c.JSON(http.StatusOK, &models.Report{ID: rp.ID, Reason: rp.Reason, Status: rp.Status, CreatedAt: rp.CreatedAt})
+	}
+}
+
+// GetReports Handler to GET the Reports in the moderation queue, by default the open ones
+func (a *ReportApi) GetReports() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		status := c.QueryParam("status")
+		if status == "" {
+			status = models.ReportStatusOpen
+		}
+
+		resp, err := traced(c, a.rp).GetReportsByStatus(status)
+		if err != nil {
+			return er.From(err)
+		}
+
+		return c.JSON(http.StatusOK, resp)
+	}
+}
+
+// GetReport Handler to GET a Report in context: the reported user or event,
+// the moderation actions already taken and the other reports about the same target
+func (a *ReportApi) GetReport() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			return er.ErrBadRequest.WithDetail("Invalid id")
+		}
+
+		rp, err := traced(c, a.rp).GetReportById(id)
+		if err != nil {
+			return er.From(err, er.ErrReportNotFound)
+		}
+
+		resp := &models.ReportContext{Report: rp}
+
+		if resp.Actions, err = traced(c, a.rp).GetModerationActionsByReportId(rp.ID); err != nil {
+			return er.From(err)
+		}
+
+		var idUser, idEvent int64
+		if rp.User != nil {
+			idUser = rp.User.ID
+		}
+		if rp.Event != nil {
+			idEvent = rp.Event.ID
+		}
+		related, err := traced(c, a.rp).GetReportsByTarget(idUser, idEvent)
+		if err != nil {
+			return er.From(err)
+		}
+		resp.RelatedReports = []*models.Report{}
+		for _, r := range related {
+			if r.ID != rp.ID {
+				resp.RelatedReports = append(resp.RelatedReports, r)
+			}
+		}
+
+		return c.JSON(http.StatusOK, resp)
+	}
+}
+
+// ResolveReport Handler to POST a moderation action that resolves a Report
+func (a *ReportApi) ResolveReport() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			return er.ErrBadRequest.WithDetail("Invalid id")
+		}
+
+		u := new(models.NewModerationAction)
+		if err := c.Bind(u); err != nil {
+			return er.From(err)
+		}
+
+		if err := a.validate.Struct(u); err != nil {
+			return er.Validation(err)
+		}
+
+		rp, err := traced(c, a.rp).GetReportById(id)
+		if err != nil {
+			return er.From(err, er.ErrReportNotFound)
+		}
+		if rp.Status != models.ReportStatusOpen {
+			return er.ErrReportResolved
+		}
+		if u.Action == models.ModerationDeactivateUser && rp.User == nil {
+			return er.ErrReportTarget.WithDetail("Report has no reported user")
+		}
+		if u.Action == models.ModerationDeactivateEvent && rp.Event == nil {
+			return er.ErrReportTarget.WithDetail("Report has no reported event")
+		}
+
+		cl := c.Get("claims").(*stru.TokenClaims)
+		ac := &models.ModerationAction{ReportID: rp.ID, Moderator: &models.User{ID: cl.ID}, Action: u.Action, Reason: u.Reason}
+
+		if err = traced(c, a.rp).ResolveReport(ac); err != nil {
+			return er.From(err, er.ErrReportNotFound, er.ErrReportResolved)
+		}
+
+		// the account of a deactivated user has the action in its audit log
+		var idUser int64
+		if u.Action == models.ModerationDeactivateUser {
+			idUser = rp.User.ID
+		}
+		audit(c, a.rp, models.AuditReportResolve, idUser, "report", rp.ID)
+
+		// Get the report with its new status
+		rp, err = traced(c, a.rp).GetReportById(id)
+		if err != nil {
+			return er.From(err, er.ErrReportNotFound)
+		}
+
+		return c.JSON(http.StatusOK, rp)
+	}
+}
@@ -0,0 +1,16 @@
+package api
+
+import (
+	"github.com/labstack/echo"
+	repo "github.com/pintobikez/popmeet/repository"
+	"github.com/pintobikez/popmeet/tracing"
+)
+
+// traced Gets the repository tracing its calls under the span of the request, when it supports it.
+// Must not be used in go routines started by the handlers, the echo context is reused after the response
+func traced(c echo.Context, rp repo.Repository) repo.Repository {
+	if t, ok := rp.(tracing.ContextRepository); ok {
+		return t.WithContext(c.Request().Context())
+	}
+	return rp
+}
@@ -1,16 +1,24 @@
 package api
 
 import (
+	"context"
+	"errors"
 	"github.com/labstack/echo"
 	"github.com/pintobikez/popmeet/api/models"
 	er "github.com/pintobikez/popmeet/errors"
+	"github.com/pintobikez/popmeet/i18n"
+	"github.com/pintobikez/popmeet/mailer"
+	"github.com/pintobikez/popmeet/metrics"
 	repo "github.com/pintobikez/popmeet/repository"
 	"github.com/pintobikez/popmeet/secure"
 	tok "github.com/pintobikez/popmeet/secure/structures"
+	"github.com/pintobikez/popmeet/tracing"
 	"golang.org/x/crypto/bcrypt"
 	"gopkg.in/go-playground/validator.v9"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 )
 
 const ApiLoginProvider int64 = 1
@@ -19,12 +27,26 @@ type UserApi struct {
 	rp       repo.Repository
 	validate *validator.Validate
 	tokenMan *secure.TokenManager
+	mailer   mailer.Mailer
+	cat      *i18n.Catalog
+	policy   *secure.PasswordPolicy
+	guard    *secure.LoginGuard
+	// dummyHash is checked when the user doesn't exist, so the response time is the same
+	dummyHash string
 }
 
-func (a *UserApi) New(rpo repo.Repository, t *secure.TokenManager) {
+func (a *UserApi) New(rpo repo.Repository, t *secure.TokenManager, m mailer.Mailer, cat *i18n.Catalog, p *secure.PasswordPolicy, g *secure.LoginGuard) {
 	a.rp = rpo
 	a.validate = validator.New()
 	a.tokenMan = t
+	a.mailer = m
+	a.cat = cat
+	a.policy = p
+	a.guard = g
+	a.dummyHash, _ = a.hashPassword(context.Background(), "popmeet-dummy-password")
+	if err := registerTextValidation(a.validate); err != nil {
+		panic(err)
+	}
 }
 
 func (a *UserApi) SetRepository(rpo repo.Repository) {
@@ -37,23 +59,23 @@ func (a *UserApi) GetUser() echo.HandlerFunc {
 
 		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 		if err != nil {
-			return c.JSON(http.StatusBadRequest, er.GeneralErrorJson(http.StatusBadRequest, err.Error()))
+			return er.ErrBadRequest.WithDetail("Invalid id")
 		}
 
 		// Get the user
-		resp, err := a.rp.GetUserById(id)
+		resp, err := traced(c, a.rp).GetUserById(id)
 		if err != nil {
-			return c.JSON(http.StatusNotFound, er.GeneralErrorJson(er.ErrorUserNotFound, err.Error()))
+			return er.From(err, er.ErrUserNotFound)
 		}
 		// Get the user profile
-		resp.Profile, err = a.rp.GetUserProfileByUserId(resp.ID)
+		resp.Profile, err = traced(c, a.rp).GetUserProfileByUserId(resp.ID)
 		if err != nil {
-			return c.JSON(http.StatusNotFound, er.GeneralErrorJson(er.ErrorUserProfileNotFound, err.Error()))
+			return er.From(err, er.ErrUserProfileNotFound)
 		}
 		// Get the user security
-		resp.Security, err = a.rp.GetSecurityInfoByUserId(resp.ID)
+		resp.Security, err = traced(c, a.rp).GetSecurityInfoByUserId(resp.ID)
 		if err != nil {
-			return c.JSON(http.StatusNotFound, er.GeneralErrorJson(er.ErrorUserProfileNotFound, err.Error()))
+			return er.From(err, er.ErrUserProfileNotFound)
 		}
 
 		return c.JSON(http.StatusOK, resp)
@@ -67,52 +89,67 @@ func (a *UserApi) PutUser() echo.HandlerFunc {
 
 		u := new(models.NewUser)
 		if err := c.Bind(u); err != nil {
-			return c.JSON(http.StatusBadRequest, er.GeneralErrorJson(http.StatusBadRequest, err.Error()))
+			return er.From(err)
 		}
 
 		if err := a.validate.Struct(u); err != nil {
-			return c.JSON(http.StatusUnprocessableEntity, er.ValidationErrorJson(http.StatusUnprocessableEntity, err))
+			return er.Validation(err)
 		}
 
 		if u.Password == "" && u.Provider == ApiLoginProvider {
-			return c.JSON(http.StatusBadRequest, er.GeneralErrorJson(http.StatusBadRequest, "Password must be filled"))
+			return er.ErrBadRequest.WithDetail("Password must be filled")
+		}
+
+		if u.Password != "" {
+			if err := a.policy.Validate(u.Password, u.Email); err != nil {
+				return er.ErrPasswordPolicy.WithDetail("%s", err.Error())
+			}
 		}
 
-		if em, err := a.rp.FindUserByEmail(u.Email); err != nil || em {
-			return c.JSON(http.StatusConflict, er.GeneralErrorJson(http.StatusConflict, "Email already exists"))
+		if em, err := traced(c, a.rp).FindUserByEmail(u.Email); err != nil || em {
+			return er.ErrEmailExists
 		}
 
-		ur := &models.User{Name: u.Name, Email: u.Email, Active: true, Security: &models.UserSecurity{LastMachine: c.RealIP()}}
+		ur := &models.User{Name: u.Name, Email: u.Email, Active: true, Security: &models.UserSecurity{LastMachine: clientIP(c)}}
 
 		// Hash the password
 		if u.Password != "" {
-			ur.Security.Hash, err = a.hashPassword(u.Password)
+			ur.Security.Hash, err = a.hashPassword(c.Request().Context(), u.Password)
 			if err != nil {
-				return c.JSON(http.StatusInternalServerError, er.GeneralErrorJson(http.StatusInternalServerError, err.Error()))
+				return er.From(err)
 			}
 		}
 
 		// Find the login provider
-		if ur.Security.Provider, err = a.rp.GetLoginProviderById(u.Provider); err != nil {
-			return c.JSON(http.StatusBadRequest, er.GeneralErrorJson(http.StatusBadRequest, err.Error()))
+		if ur.Security.Provider, err = traced(c, a.rp).GetLoginProviderById(u.Provider); err != nil {
+			return er.From(err)
 		}
 
-		err = a.rp.InsertUser(ur)
+		// the email is unique, a concurrent registration with it is a conflict
+		err = traced(c, a.rp).InsertUser(ur)
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, er.GeneralErrorJson(http.StatusInternalServerError, err.Error()))
+			return er.From(err, er.ErrEmailExists)
 		}
+		metrics.Registrations.Inc()
 
 		// Get all user information
-		ur, err = a.rp.GetUserById(ur.ID)
+		ur, err = traced(c, a.rp).GetUserById(ur.ID)
 		if err != nil {
-			return c.JSON(http.StatusNotFound, er.GeneralErrorJson(er.ErrorUserNotFound, err.Error()))
+			return er.From(err, er.ErrUserNotFound)
 		}
 		// Get the user security
-		ur.Security, err = a.rp.GetSecurityInfoByUserId(ur.ID)
+		ur.Security, err = traced(c, a.rp).GetSecurityInfoByUserId(ur.ID)
 		if err != nil {
-			return c.JSON(http.StatusNotFound, er.GeneralErrorJson(er.ErrorUserProfileNotFound, err.Error()))
+			return er.From(err, er.ErrUserProfileNotFound)
 		}
 
+		//Send the email verification in a new go routine
+		go func(lg echo.Logger, ur *models.User) {
+			if err := a.sendVerification(ur); err != nil {
+				lg.Errorf(err.Error())
+			}
+		}(c.Logger(), ur)
+
 		return c.JSON(http.StatusOK, ur)
 	}
 }
@@ -124,111 +161,389 @@ func (a *UserApi) PostUser() echo.HandlerFunc {
 
 		u := new(models.User)
 		if err = c.Bind(u); err != nil {
-			return c.JSON(http.StatusBadRequest, er.GeneralErrorJson(http.StatusBadRequest, err.Error()))
+			return er.From(err)
 		}
 
 		// Get the user by the claim ID
 		cl := c.Get("claims").(*tok.TokenClaims)
 		if cl.ID != u.ID {
-			return c.JSON(http.StatusUnauthorized, er.GeneralErrorJson(http.StatusUnauthorized, "Not authorized to perform this action"))
+			return er.ErrForbidden
 		}
 
 		if err = a.validate.Struct(u); err != nil {
-			return c.JSON(http.StatusUnprocessableEntity, er.ValidationErrorJson(http.StatusUnprocessableEntity, err))
+			return er.Validation(err)
+		}
+
+		if u.Profile != nil {
+			if err = a.checkLanguages(c, cl.ID, u.Profile); err != nil {
+				return err
+			}
+			// the profile of the logged user is updated, or created when it has none. The id of the body is ignored
+			u.Profile.ID = 0
+			cp, err := traced(c, a.rp).GetUserProfileByUserId(cl.ID)
+			if err != nil && !errors.Is(err, er.ErrNotFound) {
+				return er.From(err)
+			}
+			if err == nil {
+				u.Profile.ID = cp.ID
+			}
 		}
 
-		// if the user is not changing the email well set it up from the claims
-		if u.Email == "" {
-			u.Email = cl.Email
+		// the email is changed with a confirmation sent to the new address
+		ur, err := traced(c, a.rp).GetUserById(u.ID)
+		if err != nil {
+			return er.From(err, er.ErrUserNotFound)
+		}
+		if u.Email != "" && !strings.EqualFold(u.Email, ur.Email) {
+			return er.ErrBadRequest.WithDetail("The email is changed with POST /user/email")
+		}
+		u.Email = ur.Email
+
+		// the login provider before the update, to audit its change. Only the provider of the logged user is
+		// changed, the security id of the body is ignored and the password is changed with ChangePassword
+		var provider int64
+		if u.Security != nil {
+			sec, err := traced(c, a.rp).GetSecurityInfoByUserId(cl.ID)
+			if err != nil {
+				return er.From(err, er.ErrUserProfileNotFound)
+			}
+			provider = sec.Provider.ID
+			u.Security.ID = sec.ID
 		}
 
 		// Perform the update
-		if err = a.rp.UpdateUser(u); err != nil {
-			return c.JSON(http.StatusBadRequest, er.GeneralErrorJson(http.StatusBadRequest, err.Error()))
+		if err = traced(c, a.rp).UpdateUser(u); err != nil {
+			return er.From(err)
+		}
+
+		if u.Security != nil && u.Security.Provider != nil && u.Security.Provider.ID != provider {
+			audit(c, a.rp, models.AuditProviderChange, u.ID, "login_provider", u.Security.Provider.ID)
 		}
 
 		// Get the user
-		resp, err := a.rp.GetUserById(u.ID)
+		resp, err := traced(c, a.rp).GetUserById(u.ID)
 		if err != nil {
-			return c.JSON(http.StatusNotFound, er.GeneralErrorJson(er.ErrorUserNotFound, err.Error()))
+			return er.From(err, er.ErrUserNotFound)
 		}
 		// Get the user profile
-		resp.Profile, err = a.rp.GetUserProfileByUserId(resp.ID)
+		resp.Profile, err = traced(c, a.rp).GetUserProfileByUserId(resp.ID)
 		if err != nil {
-			return c.JSON(http.StatusNotFound, er.GeneralErrorJson(er.ErrorUserProfileNotFound, err.Error()))
+			return er.From(err, er.ErrUserProfileNotFound)
 		}
 		// Get the user security
-		resp.Security, err = a.rp.GetSecurityInfoByUserId(resp.ID)
+		resp.Security, err = traced(c, a.rp).GetSecurityInfoByUserId(resp.ID)
 		if err != nil {
-			return c.JSON(http.StatusNotFound, er.GeneralErrorJson(er.ErrorUserProfileNotFound, err.Error()))
+			return er.From(err, er.ErrUserProfileNotFound)
 		}
 
 		return c.JSON(http.StatusOK, resp)
 	}
 }
 
+// checkLanguages Checks the languages of a profile exist and each spoken one is given once.
+// The disabled languages can't be chosen, the ones already in the profile are kept
+func (a *UserApi) checkLanguages(c echo.Context, idUser int64, p *models.UserProfile) error {
+
+	ids := []int64{p.Language.ID}
+	seen := make(map[int64]bool)
+	for _, sl := range p.Languages {
+		if seen[sl.Language.ID] {
+			return er.ErrBadRequest.WithDetail("Language %d is repeated", sl.Language.ID)
+		}
+		seen[sl.Language.ID] = true
+		ids = append(ids, sl.Language.ID)
+	}
+
+	current := make(map[int64]bool)
+	if cp, err := traced(c, a.rp).GetUserProfileByUserId(idUser); err == nil {
+		current[cp.Language.ID] = true
+		for _, sl := range cp.Languages {
+			current[sl.Language.ID] = true
+		}
+	}
+
+	for _, id := range ids {
+		l, err := traced(c, a.rp).GetLanguageById(id)
+		if err != nil {
+			return er.From(err, er.ErrLanguageNotFound)
+		}
+		if !l.Active && !current[id] {
+			return er.ErrLanguageDisabled.WithDetail("Language %s is disabled", l.Name)
+		}
+	}
+
+	return nil
+}
+
 // Handler to Login User
 func (a *UserApi) LoginUser() echo.HandlerFunc {
 	return func(c echo.Context) error {
 
 		u := new(models.LoginUser)
 		if err := c.Bind(u); err != nil {
-			return c.JSON(http.StatusBadRequest, er.GeneralErrorJson(http.StatusBadRequest, err.Error()))
+			return er.From(err)
 		}
 		if err := a.validate.Struct(u); err != nil {
-			return c.JSON(http.StatusUnprocessableEntity, er.ValidationErrorJson(http.StatusUnprocessableEntity, err))
+			return er.Validation(err)
+		}
+
+		ip := clientIP(c)
+		if !a.guard.IpAllowed(ip, time.Now()) {
+			return er.ErrTooManyRequests.WithDetail("Too many login attempts, try again later")
 		}
 
 		// Get the user
-		resp, err := a.rp.GetUserByEmail(u.Email)
-		if err != nil {
-			return c.JSON(http.StatusUnauthorized, er.GeneralErrorJson(http.StatusUnauthorized, "Invalid credentials"))
+		resp, err := traced(c, a.rp).GetUserByEmail(u.Email)
+		if err == nil && resp.Active {
+			// Get the user security
+			resp.Security, err = traced(c, a.rp).GetSecurityInfoByUserId(resp.ID)
+		}
+		if err != nil || !resp.Active {
+			a.checkPasswordHash(c.Request().Context(), u.Password, a.dummyHash)
+			a.guard.IpFailed(ip, time.Now())
+			metrics.Logins.WithLabelValues(metrics.LoginFailure).Inc()
+			// the unknown emails are audited without user
+			var idUser int64
+			if resp != nil {
+				idUser = resp.ID
+			}
+			audit(c, a.rp, models.AuditLoginFailure, idUser, "", 0)
+			return er.ErrInvalidCredentials
 		}
 		// Get the user profile
-		resp.Profile, _ = a.rp.GetUserProfileByUserId(resp.ID)
+		resp.Profile, _ = traced(c, a.rp).GetUserProfileByUserId(resp.ID)
 
-		// Get the user security
-		resp.Security, err = a.rp.GetSecurityInfoByUserId(resp.ID)
-		if err != nil {
-			return c.JSON(http.StatusNotFound, er.GeneralErrorJson(er.ErrorUserProfileNotFound, err.Error()))
-		}
 		//Set the last machine
-		resp.Security.LastMachine = c.RealIP()
+		resp.Security.LastMachine = ip
+
+		// Accounts waiting after failed attempts answer as a wrong password
+		if !a.accountAllowed(resp.Security) {
+			a.checkPasswordHash(c.Request().Context(), u.Password, a.dummyHash)
+			a.guard.IpFailed(ip, time.Now())
+			metrics.Logins.WithLabelValues(metrics.LoginFailure).Inc()
+			audit(c, a.rp, models.AuditLoginFailure, resp.ID, "", 0)
+			return er.ErrInvalidCredentials
+		}
 
 		// Validate user password
-		if !a.checkPasswordHash(u.Password, resp.Security.Hash) {
-			return c.JSON(http.StatusUnauthorized, er.GeneralErrorJson(http.StatusUnauthorized, "Invalid credentials"))
+		if !a.checkPasswordHash(c.Request().Context(), u.Password, resp.Security.Hash) {
+			a.loginFailed(c, resp)
+			return er.ErrInvalidCredentials
+		}
+
+		// With 2FA the login is finished in LoginTwoFactor
+		if resp.Security.TwoFactor {
+			tc := &tok.TokenClaims{Email: resp.Email, ID: resp.ID, Role: resp.Role, SessionVersion: resp.Security.SessionVersion}
+			challenge, err := a.tokenMan.CreateChallengeToken(c.Request().Context(), tc)
+			if err != nil {
+				return er.ErrCreatingToken.Wrap(err)
+			}
+
+			metrics.Logins.WithLabelValues(metrics.LoginChallenged).Inc()
+			return c.JSON(http.StatusOK, &models.LoginChallenge{TwoFactorRequired: true, Challenge: challenge})
 		}
 
-		// Create the JWT Token
-		tc := &tok.TokenClaims{Email: resp.Email, ID: resp.ID}
-		token, err := a.tokenMan.CreateToken(tc, "")
+		return a.startSession(c, resp, false)
+	}
+}
+
+// startSession Sets the JWT token of the logged user in the Header and answers with the user
+func (a *UserApi) startSession(c echo.Context, resp *models.User, secondFactor bool) error {
+
+	// a login in the grace period keeps the account
+	if resp.DeletionAt != nil {
+		if err := traced(c, a.rp).CancelUserDeletion(resp.ID); err != nil {
+			return er.From(err)
+		}
+		resp.DeletionAt = nil
+		audit(c, a.rp, models.AuditAccountRestore, resp.ID, "", 0)
+	}
+
+	// Create the JWT Token
+	tc := &tok.TokenClaims{Email: resp.Email, ID: resp.ID, Role: resp.Role, SessionVersion: resp.Security.SessionVersion}
+	token, err := a.tokenMan.CreateSessionToken(c.Request().Context(), tc, secondFactor)
+	if err != nil {
+		return er.ErrCreatingToken.Wrap(err)
+	}
+
+	//Set the token in the Header
+	c.Response().Header().Set(echo.HeaderAuthorization, token)
+	metrics.Logins.WithLabelValues(metrics.LoginSuccess).Inc()
+	// the last login is overwritten, the audit log keeps them all
+	audit(c, a.rp, models.AuditLoginSuccess, resp.ID, "", 0)
+
+	//Update the LastMachine and LastLogin in a new go routine
+	go func(lg echo.Logger) {
+		if err := a.rp.UpdateLoginData(resp.Security); err != nil {
+			lg.Errorf(err.Error())
+		}
+	}(c.Logger())
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// UnlockUser Handler to POST the unlock of a User locked after failed logins
+func (a *UserApi) UnlockUser() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, er.GeneralErrorJson(er.ErrorCreatingToken, err.Error()))
+			return er.ErrBadRequest.WithDetail("Invalid id")
 		}
 
-		//Set the token in the Header
-		c.Response().Header().Set(echo.HeaderAuthorization, token)
+		sec, err := traced(c, a.rp).GetSecurityInfoByUserId(id)
+		if err != nil {
+			return er.From(err, er.ErrUserProfileNotFound)
+		}
+
+		if err = traced(c, a.rp).ResetLoginFailures(sec.ID); err != nil {
+			return er.From(err)
+		}
 
-		//Update the LastMachine and LastLogin in a new go routine
-		go func() {
-			if err := a.rp.UpdateLoginData(resp.Security); err != nil {
-				c.Logger().Errorf(err.Error())
+		audit(c, a.rp, models.AuditUserUnlock, id, "user", id)
+
+		return c.NoContent(http.StatusOK)
+	}
+}
+
+// SetRole Handler to POST the role of a User, ending its sessions. The admins can't change their own role
+func (a *UserApi) SetRole() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			return er.ErrBadRequest.WithDetail("Invalid id")
+		}
+
+		u := new(models.UserRole)
+		if err = c.Bind(u); err != nil {
+			return er.From(err)
+		}
+		if err = a.validate.Struct(u); err != nil {
+			return er.Validation(err)
+		}
+
+		cl := c.Get("claims").(*tok.TokenClaims)
+		if cl.ID == id {
+			return er.ErrForbidden.WithDetail("The own role can't be changed")
+		}
+
+		ur, err := traced(c, a.rp).GetUserById(id)
+		if err != nil {
+			return er.From(err, er.ErrUserNotFound)
+		}
+
+		if ur.Role != u.Role {
+			if err = traced(c, a.rp).SetUserRole(id, u.Role); err != nil {
+				return er.From(err)
 			}
-		}()
+			ur.Role = u.Role
 
-		return c.JSON(http.StatusOK, resp)
+			audit(c, a.rp, models.AuditRoleChange, id, "user", id)
+		}
+
+		return c.JSON(http.StatusOK, ur)
+	}
+}
+
+// accountAllowed Checks the backoff and lockout of an account
+func (a *UserApi) accountAllowed(sec *models.UserSecurity) bool {
+	return a.guard.AccountAllowed(sec.FailedAttempts, sec.LastFailedAt, sec.LockedUntil, time.Now())
+}
+
+// loginFailed Records a failed login of a user and its ip, locking the account after too many
+func (a *UserApi) loginFailed(c echo.Context, ur *models.User) {
+	sec := ur.Security
+	now := time.Now()
+	a.guard.IpFailed(clientIP(c), now)
+	metrics.Logins.WithLabelValues(metrics.LoginFailure).Inc()
+	audit(c, a.rp, models.AuditLoginFailure, ur.ID, "", 0)
+
+	failures, err := traced(c, a.rp).RecordLoginFailure(sec.ID, clientIP(c), now)
+	if err != nil {
+		c.Logger().Errorf(err.Error())
+		return
+	}
+
+	if until := a.guard.LockUntil(failures, now); until != nil {
+		if err = traced(c, a.rp).LockUserSecurity(sec.ID, *until); err != nil {
+			c.Logger().Errorf(err.Error())
+		}
+	}
+}
+
+// ChangePassword Handler to POST a new password for the logged User.
+// Ends every other session of the user, the new token is set in the Header
+func (a *UserApi) ChangePassword() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		u := new(models.ChangePassword)
+		if err := c.Bind(u); err != nil {
+			return er.From(err)
+		}
+		if err := a.validate.Struct(u); err != nil {
+			return er.Validation(err)
+		}
+
+		cl := c.Get("claims").(*tok.TokenClaims)
+
+		ur, err := traced(c, a.rp).GetUserById(cl.ID)
+		if err != nil {
+			return er.From(err, er.ErrUserNotFound)
+		}
+
+		sec, err := traced(c, a.rp).GetSecurityInfoByUserId(cl.ID)
+		if err != nil {
+			return er.From(err, er.ErrUserProfileNotFound)
+		}
+
+		if sec.Hash == "" || !a.checkPasswordHash(c.Request().Context(), u.CurrentPassword, sec.Hash) {
+			return er.ErrWrongPassword
+		}
+
+		if err = a.policy.Validate(u.NewPassword, ur.Email); err != nil {
+			return er.ErrPasswordPolicy.WithDetail("%s", err.Error())
+		}
+
+		hash, err := a.hashPassword(c.Request().Context(), u.NewPassword)
+		if err != nil {
+			return er.From(err)
+		}
+
+		version, err := traced(c, a.rp).ChangePassword(sec.ID, hash)
+		if err != nil {
+			return er.From(err)
+		}
+
+		audit(c, a.rp, models.AuditPasswordChange, cl.ID, "", 0)
+
+		// Create a new JWT Token so the current session stays valid
+		tc := &tok.TokenClaims{Email: ur.Email, ID: ur.ID, Role: ur.Role, SessionVersion: version, Amr: cl.Amr}
+		token, err := a.tokenMan.CreateTokenContext(c.Request().Context(), tc, "")
+		if err != nil {
+			return er.ErrCreatingToken.Wrap(err)
+		}
+
+		c.Response().Header().Set(echo.HeaderAuthorization, token)
+
+		return c.NoContent(http.StatusOK)
 	}
 }
 
 // hashPassword Generates the hash of a given user password
-func (a *UserApi) hashPassword(password string) (string, error) {
+func (a *UserApi) hashPassword(ctx context.Context, password string) (string, error) {
+	_, span := tracing.Start(ctx, "bcrypt.GenerateFromPassword")
+	defer span.End()
+
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	return string(bytes), err
 }
 
 // checkPasswordHash Validates that the user password is correct
-func (a *UserApi) checkPasswordHash(password, hash string) bool {
+func (a *UserApi) checkPasswordHash(ctx context.Context, password, hash string) bool {
+	_, span := tracing.Start(ctx, "bcrypt.CompareHashAndPassword")
+	defer span.End()
+
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 	return err == nil
 }
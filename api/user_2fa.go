@@ -0,0 +1,196 @@
+package api
+
+import (
+	"github.com/labstack/echo"
+	"github.com/pintobikez/popmeet/api/models"
+	er "github.com/pintobikez/popmeet/errors"
+	"github.com/pintobikez/popmeet/secure"
+	tok "github.com/pintobikez/popmeet/secure/structures"
+	"net/http"
+	"time"
+)
+
+const defaultIssuer = "Popmeet"
+
+// EnrollTwoFactor Handler to POST a new TOTP secret for the logged User.
+// The otpauth uri can be shown as a QR code to the authenticator apps
+func (a *UserApi) EnrollTwoFactor() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		cl := c.Get("claims").(*tok.TokenClaims)
+
+		sec, err := traced(c, a.rp).GetSecurityInfoByUserId(cl.ID)
+		if err != nil {
+			return er.From(err, er.ErrUserProfileNotFound)
+		}
+		if sec.TwoFactor {
+			return er.ErrTwoFactorEnabled
+		}
+
+		secret, err := secure.GenerateTotpSecret()
+		if err != nil {
+			return er.From(err)
+		}
+
+		if err = traced(c, a.rp).SetTotpSecret(cl.ID, secret); err != nil {
+			return er.From(err)
+		}
+
+		issuer := a.tokenMan.Config.Issuer
+		if issuer == "" {
+			issuer = defaultIssuer
+		}
+
+		return c.JSON(http.StatusOK, &models.TwoFactorEnrollment{Secret: secret, Uri: secure.TotpURI(issuer, cl.Email, secret)})
+	}
+}
+
+// ConfirmTwoFactor Handler to POST the first TOTP code of the logged User, enabling the 2FA.
+// Returns the recovery codes, they are only shown once
+func (a *UserApi) ConfirmTwoFactor() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		u := new(models.TwoFactorCode)
+		if err := c.Bind(u); err != nil {
+			return er.From(err)
+		}
+		if err := a.validate.Struct(u); err != nil {
+			return er.Validation(err)
+		}
+
+		cl := c.Get("claims").(*tok.TokenClaims)
+
+		sec, err := traced(c, a.rp).GetSecurityInfoByUserId(cl.ID)
+		if err != nil {
+			return er.From(err, er.ErrUserProfileNotFound)
+		}
+		if sec.TwoFactor {
+			return er.ErrTwoFactorEnabled
+		}
+		if sec.TotpSecret == "" {
+			return er.ErrTwoFactorDisabled.WithDetail("Two factor authentication not enrolled")
+		}
+
+		step, ok := secure.ValidateTotp(sec.TotpSecret, u.Code, time.Now())
+		if !ok {
+			return er.ErrInvalidCode
+		}
+
+		codes, hashes, err := secure.GenerateRecoveryCodes()
+		if err != nil {
+			return er.From(err)
+		}
+
+		if err = traced(c, a.rp).EnableTotp(cl.ID, hashes); err != nil {
+			return er.From(err)
+		}
+		if _, err = traced(c, a.rp).UseTotpStep(cl.ID, step); err != nil {
+			return er.From(err)
+		}
+
+		audit(c, a.rp, models.AuditTwoFactorEnable, cl.ID, "", 0)
+
+		return c.JSON(http.StatusOK, &models.RecoveryCodes{Codes: codes})
+	}
+}
+
+// DisableTwoFactor Handler to POST a TOTP or recovery code of the logged User, disabling the 2FA
+func (a *UserApi) DisableTwoFactor() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		u := new(models.TwoFactorCode)
+		if err := c.Bind(u); err != nil {
+			return er.From(err)
+		}
+		if err := a.validate.Struct(u); err != nil {
+			return er.Validation(err)
+		}
+
+		cl := c.Get("claims").(*tok.TokenClaims)
+
+		sec, err := traced(c, a.rp).GetSecurityInfoByUserId(cl.ID)
+		if err != nil {
+			return er.From(err, er.ErrUserProfileNotFound)
+		}
+		if !sec.TwoFactor {
+			return er.ErrTwoFactorDisabled
+		}
+
+		ok, err := a.checkSecondFactor(cl.ID, sec.TotpSecret, u.Code)
+		if err != nil {
+			return er.From(err)
+		}
+		if !ok {
+			return er.ErrInvalidCode
+		}
+
+		if err = traced(c, a.rp).DisableTotp(cl.ID); err != nil {
+			return er.From(err)
+		}
+
+		audit(c, a.rp, models.AuditTwoFactorDisable, cl.ID, "", 0)
+
+		return c.NoContent(http.StatusOK)
+	}
+}
+
+// LoginTwoFactor Handler to POST the challenge token and the TOTP or recovery code, finishing the login
+func (a *UserApi) LoginTwoFactor() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		u := new(models.TwoFactorLogin)
+		if err := c.Bind(u); err != nil {
+			return er.From(err)
+		}
+		if err := a.validate.Struct(u); err != nil {
+			return er.Validation(err)
+		}
+
+		cl, err := a.tokenMan.ValidateChallengeToken(c.Request().Context(), u.Challenge)
+		if err != nil {
+			return er.ErrInvalidCredentials
+		}
+
+		// Get the user
+		resp, err := traced(c, a.rp).GetUserById(cl.ID)
+		if err != nil || !resp.Active {
+			return er.ErrInvalidCredentials
+		}
+		// Get the user profile
+		resp.Profile, _ = traced(c, a.rp).GetUserProfileByUserId(resp.ID)
+
+		// Get the user security
+		resp.Security, err = traced(c, a.rp).GetSecurityInfoByUserId(resp.ID)
+		if err != nil || !resp.Security.TwoFactor || resp.Security.SessionVersion != cl.SessionVersion {
+			return er.ErrInvalidCredentials
+		}
+		//Set the last machine
+		resp.Security.LastMachine = clientIP(c)
+
+		// wrong codes count as failed logins too
+		if !a.guard.IpAllowed(clientIP(c), time.Now()) || !a.accountAllowed(resp.Security) {
+			return er.ErrInvalidCredentials
+		}
+
+		ok, err := a.checkSecondFactor(resp.ID, resp.Security.TotpSecret, u.Code)
+		if err != nil {
+			return er.From(err)
+		}
+		if !ok {
+			a.loginFailed(c, resp)
+			return er.ErrInvalidCredentials
+		}
+
+		return a.startSession(c, resp, true)
+	}
+}
+
+// checkSecondFactor Validates a TOTP code, or a recovery code, of the user. Each code can only be used once
+func (a *UserApi) checkSecondFactor(id int64, secret string, code string) (bool, error) {
+
+	if step, ok := secure.ValidateTotp(secret, code, time.Now()); ok {
+		return a.rp.UseTotpStep(id, step)
+	}
+
+	return a.rp.UseRecoveryCode(id, secure.HashRecoveryCode(code))
+}
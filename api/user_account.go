@@ -0,0 +1,360 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/labstack/echo"
+	"github.com/pintobikez/popmeet/api/models"
+	er "github.com/pintobikez/popmeet/errors"
+	"github.com/pintobikez/popmeet/secure"
+	tok "github.com/pintobikez/popmeet/secure/structures"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	defaultVerifyTTL = 24 * 60
+	defaultResetTTL  = 60
+)
+
+// RequestVerification Handler to POST a new email verification for the logged User
+func (a *UserApi) RequestVerification() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		cl := c.Get("claims").(*tok.TokenClaims)
+
+		ur, err := traced(c, a.rp).GetUserById(cl.ID)
+		if err != nil {
+			return er.From(err, er.ErrUserNotFound)
+		}
+		if ur.EmailVerified {
+			return er.ErrEmailVerified
+		}
+
+		if err = a.sendVerification(ur); err != nil {
+			return er.From(err)
+		}
+
+		return c.NoContent(http.StatusOK)
+	}
+}
+
+// VerifyEmail Handler to POST the token that verifies the email of a User
+func (a *UserApi) VerifyEmail() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		u := new(models.RedeemToken)
+		if err := c.Bind(u); err != nil {
+			return er.From(err)
+		}
+		if err := a.validate.Struct(u); err != nil {
+			return er.Validation(err)
+		}
+
+		t, err := a.redeemToken(models.TokenVerifyEmail, u.Token)
+		if err != nil {
+			return er.From(err)
+		}
+
+		if err = traced(c, a.rp).SetEmailVerified(t.UserID); err != nil {
+			return er.From(err)
+		}
+
+		audit(c, a.rp, models.AuditEmailVerify, t.UserID, "", 0)
+
+		return c.NoContent(http.StatusOK)
+	}
+}
+
+// ChangeEmail Handler to POST a new email for the logged User. The email changes once the link
+// sent to the new address is followed, the old address is told about the change
+func (a *UserApi) ChangeEmail() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		u := new(models.ChangeEmail)
+		if err := c.Bind(u); err != nil {
+			return er.From(err)
+		}
+		if err := a.validate.Struct(u); err != nil {
+			return er.Validation(err)
+		}
+
+		cl := c.Get("claims").(*tok.TokenClaims)
+
+		ur, err := traced(c, a.rp).GetUserById(cl.ID)
+		if err != nil {
+			return er.From(err, er.ErrUserNotFound)
+		}
+
+		sec, err := traced(c, a.rp).GetSecurityInfoByUserId(cl.ID)
+		if err != nil {
+			return er.From(err, er.ErrUserProfileNotFound)
+		}
+
+		// the users of the other login providers have no password
+		if sec.Hash != "" && !a.checkPasswordHash(c.Request().Context(), u.Password, sec.Hash) {
+			return er.ErrWrongPassword
+		}
+
+		if strings.EqualFold(u.Email, ur.Email) {
+			return er.ErrBadRequest.WithDetail("The new email is the current one")
+		}
+		if _, err = traced(c, a.rp).GetUserByEmail(u.Email); err == nil {
+			return er.ErrEmailExists
+		}
+		if !errors.Is(err, er.ErrNotFound) {
+			return er.From(err)
+		}
+
+		// the pending email and the token sent to it are stored together, the token only confirms that email
+		token, t, err := a.newToken(ur.ID, models.TokenChangeEmail, a.tokenMan.Config.VerifyTTL, defaultVerifyTTL)
+		if err != nil {
+			return er.From(err)
+		}
+		t.Target = u.Email
+		if err = traced(c, a.rp).SetPendingEmail(t); err != nil {
+			return er.From(err)
+		}
+
+		if err = a.sendEmailChange(ur, u.Email, token); err != nil {
+			return er.From(err)
+		}
+
+		audit(c, a.rp, models.AuditEmailChangeRequest, ur.ID, "", 0)
+
+		return c.NoContent(http.StatusOK)
+	}
+}
+
+// ConfirmEmail Handler to POST the token that confirms the new email of a User
+func (a *UserApi) ConfirmEmail() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		u := new(models.RedeemToken)
+		if err := c.Bind(u); err != nil {
+			return er.From(err)
+		}
+		if err := a.validate.Struct(u); err != nil {
+			return er.Validation(err)
+		}
+
+		t, err := a.redeemToken(models.TokenChangeEmail, u.Token)
+		if err != nil {
+			return er.From(err)
+		}
+
+		// the email may have been taken or changed by a newer request since the token was sent
+		if err = traced(c, a.rp).ConfirmPendingEmail(t.UserID, t.Target); errors.Is(err, er.ErrNotFound) {
+			return er.ErrInvalidToken
+		}
+		if err != nil {
+			return er.From(err, er.ErrEmailExists)
+		}
+
+		audit(c, a.rp, models.AuditEmailChange, t.UserID, "", 0)
+
+		return c.NoContent(http.StatusOK)
+	}
+}
+
+// ForgotPassword Handler to POST a password reset request.
+// Always answers the same way so it can't be used to find out which emails exist
+func (a *UserApi) ForgotPassword() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		u := new(models.ForgotPassword)
+		if err := c.Bind(u); err != nil {
+			return er.From(err)
+		}
+		if err := a.validate.Struct(u); err != nil {
+			return er.Validation(err)
+		}
+
+		//Send the reset email in a new go routine
+		go func(lg echo.Logger, email string) {
+			if err := a.sendPasswordReset(email); err != nil {
+				lg.Errorf(err.Error())
+			}
+		}(c.Logger(), u.Email)
+
+		return c.NoContent(http.StatusOK)
+	}
+}
+
+// ResetPassword Handler to POST the token and new password of a password reset
+func (a *UserApi) ResetPassword() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		u := new(models.ResetPassword)
+		if err := c.Bind(u); err != nil {
+			return er.From(err)
+		}
+		if err := a.validate.Struct(u); err != nil {
+			return er.Validation(err)
+		}
+
+		// check the new password before using the token, so it can be retried
+		t, err := traced(c, a.rp).GetUserTokenByHash(models.TokenResetPassword, secure.HashToken(u.Token))
+		if errors.Is(err, er.ErrNotFound) {
+			return er.ErrInvalidToken
+		}
+		if err != nil {
+			return er.From(err)
+		}
+
+		ur, err := traced(c, a.rp).GetUserById(t.UserID)
+		if err != nil {
+			return er.From(err, er.ErrUserNotFound)
+		}
+
+		if err = a.policy.Validate(u.Password, ur.Email); err != nil {
+			return er.ErrPasswordPolicy.WithDetail("%s", err.Error())
+		}
+
+		if t, err = a.redeemToken(models.TokenResetPassword, u.Token); err != nil {
+			return er.From(err)
+		}
+
+		sec, err := traced(c, a.rp).GetSecurityInfoByUserId(t.UserID)
+		if err != nil {
+			return er.From(err, er.ErrUserProfileNotFound)
+		}
+
+		hash, err := a.hashPassword(c.Request().Context(), u.Password)
+		if err != nil {
+			return er.From(err)
+		}
+
+		// a reset ends all the sessions of the user
+		if _, err = traced(c, a.rp).ChangePassword(sec.ID, hash); err != nil {
+			return er.From(err)
+		}
+
+		audit(c, a.rp, models.AuditPasswordReset, t.UserID, "", 0)
+
+		// the reset link was received by email, so the address is valid
+		if err = traced(c, a.rp).SetEmailVerified(t.UserID); err != nil {
+			return er.From(err)
+		}
+
+		return c.NoContent(http.StatusOK)
+	}
+}
+
+// sendVerification Creates an email verification token and emails it to the user
+func (a *UserApi) sendVerification(ur *models.User) error {
+
+	token, err := a.createToken(ur.ID, models.TokenVerifyEmail, a.tokenMan.Config.VerifyTTL, defaultVerifyTTL)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/verify-email?token=%s", a.tokenMan.Config.AppUrl, token)
+
+	return a.sendMail(ur, "email.verify", map[string]string{"name": ur.Name, "link": link})
+}
+
+// sendEmailChange Emails the email change token to the new address, telling the old one about the change
+func (a *UserApi) sendEmailChange(ur *models.User, email string, token string) error {
+
+	link := fmt.Sprintf("%s/confirm-email?token=%s", a.tokenMan.Config.AppUrl, token)
+
+	if err := a.sendMailTo(ur, email, "email.change", map[string]string{"name": ur.Name, "link": link}); err != nil {
+		return err
+	}
+
+	return a.sendMail(ur, "email.change_notice", map[string]string{"name": ur.Name, "email": email})
+}
+
+// sendPasswordReset Creates a password reset token and emails it to the user with the given email, if any
+func (a *UserApi) sendPasswordReset(email string) error {
+
+	ur, err := a.rp.GetUserByEmail(email)
+	if err != nil || !ur.Active {
+		return nil
+	}
+
+	sec, err := a.rp.GetSecurityInfoByUserId(ur.ID)
+	if err != nil {
+		return err
+	}
+	// only the users of the api provider have a password
+	if sec.Provider == nil || sec.Provider.ID != ApiLoginProvider {
+		return nil
+	}
+
+	token, err := a.createToken(ur.ID, models.TokenResetPassword, a.tokenMan.Config.ResetTTL, defaultResetTTL)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", a.tokenMan.Config.AppUrl, token)
+
+	return a.sendMail(ur, "email.reset", map[string]string{"name": ur.Name, "link": link})
+}
+
+// sendMail Emails the user the subject and body of the key, in the language of the user
+func (a *UserApi) sendMail(ur *models.User, key string, args map[string]string) error {
+	return a.sendMailTo(ur, ur.Email, key, args)
+}
+
+// sendMailTo Emails the given address the subject and body of the key, in the language of the user
+func (a *UserApi) sendMailTo(ur *models.User, to string, key string, args map[string]string) error {
+
+	lang := a.cat.Language(a.rp, ur.ID)
+
+	return a.mailer.Send(to, a.cat.Translate(lang, key+".subject", args), a.cat.Translate(lang, key+".body", args))
+}
+
+// createToken Stores a new single use token for the user and returns it
+func (a *UserApi) createToken(id int64, purpose string, ttl int, defaultTTL int) (string, error) {
+
+	token, t, err := a.newToken(id, purpose, ttl, defaultTTL)
+	if err != nil {
+		return "", err
+	}
+
+	if err = a.rp.InsertUserToken(t); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// newToken Generates a single use token for the user, returns it with the record to store
+func (a *UserApi) newToken(id int64, purpose string, ttl int, defaultTTL int) (string, *models.UserToken, error) {
+
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+
+	token, hash, err := secure.GenerateToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	return token, &models.UserToken{UserID: id, Purpose: purpose, Hash: hash, ExpiresAt: time.Now().Add(time.Duration(ttl) * time.Minute)}, nil
+}
+
+// redeemToken Checks a single use token and marks it as used
+func (a *UserApi) redeemToken(purpose string, token string) (*models.UserToken, error) {
+
+	t, err := a.rp.GetUserTokenByHash(purpose, secure.HashToken(token))
+	if err != nil && !errors.Is(err, er.ErrNotFound) {
+		return nil, err
+	}
+	if err != nil || t.UsedAt != nil || time.Now().After(t.ExpiresAt) {
+		return nil, er.ErrInvalidToken
+	}
+
+	ok, err := a.rp.UseUserToken(t.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, er.ErrInvalidToken
+	}
+
+	return t, nil
+}
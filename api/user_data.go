@@ -0,0 +1,206 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/labstack/echo"
+	"github.com/pintobikez/popmeet/api/models"
+	er "github.com/pintobikez/popmeet/errors"
+	tok "github.com/pintobikez/popmeet/secure/structures"
+	"net/http"
+	"time"
+)
+
+const (
+	exportZip            = "zip"
+	exportJSON           = "json"
+	exportLimit          = 10000
+	defaultDeletionGrace = 30
+)
+
+// ExportUser Handler to GET all the data about the logged User, a ZIP of JSON files or a single JSON with format=json
+func (a *UserApi) ExportUser() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		format := c.QueryParam("format")
+		if format == "" {
+			format = exportZip
+		}
+		if format != exportZip && format != exportJSON {
+			return er.ErrBadRequest.WithDetail("Invalid format, expected %s or %s", exportZip, exportJSON)
+		}
+
+		cl := c.Get("claims").(*tok.TokenClaims)
+
+		ex, err := a.export(c, cl.ID)
+		if err != nil {
+			return err
+		}
+
+		audit(c, a.rp, models.AuditAccountExport, cl.ID, "", 0)
+
+		name := fmt.Sprintf("popmeet-export-%d-%s.%s", cl.ID, ex.ExportedAt.Format("20060102"), format)
+		c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", name))
+
+		if format == exportJSON {
+			return c.JSON(http.StatusOK, ex)
+		}
+
+		b, err := exportArchive(ex)
+		if err != nil {
+			return er.From(err)
+		}
+
+		return c.Blob(http.StatusOK, "application/zip", b)
+	}
+}
+
+// DeleteUser Handler to DELETE the account of the logged User. The account is erased after the grace period,
+// a login in the meantime keeps it
+func (a *UserApi) DeleteUser() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		u := new(models.DeleteAccount)
+		if err := c.Bind(u); err != nil {
+			return er.From(err)
+		}
+		if err := a.validate.Struct(u); err != nil {
+			return er.Validation(err)
+		}
+
+		cl := c.Get("claims").(*tok.TokenClaims)
+
+		ur, err := traced(c, a.rp).GetUserById(cl.ID)
+		if err != nil {
+			return er.From(err, er.ErrUserNotFound)
+		}
+
+		sec, err := traced(c, a.rp).GetSecurityInfoByUserId(cl.ID)
+		if err != nil {
+			return er.From(err, er.ErrUserProfileNotFound)
+		}
+
+		// the users of the other login providers have no password
+		if sec.Hash != "" && !a.checkPasswordHash(c.Request().Context(), u.Password, sec.Hash) {
+			return er.ErrWrongPassword
+		}
+
+		grace := a.tokenMan.Config.DeletionGrace
+		if grace <= 0 {
+			grace = defaultDeletionGrace
+		}
+		at := time.Now().Add(time.Duration(grace) * 24 * time.Hour)
+
+		if err = traced(c, a.rp).ScheduleUserDeletion(cl.ID, at, u.HostedEvents); err != nil {
+			return er.From(err)
+		}
+		ur.DeletionAt = &at
+
+		audit(c, a.rp, models.AuditAccountDelete, cl.ID, "", 0)
+
+		//Send the deletion notice in a new go routine
+		go func(lg echo.Logger, ur *models.User) {
+			if err := a.sendMail(ur, "email.deletion", map[string]string{"name": ur.Name, "date": at.Format("2006-01-02")}); err != nil {
+				lg.Errorf(err.Error())
+			}
+		}(c.Logger(), ur)
+
+		return c.JSON(http.StatusOK, ur)
+	}
+}
+
+// export Gets all the data about a user
+func (a *UserApi) export(c echo.Context, id int64) (*models.UserExport, error) {
+	var err error
+
+	ex := &models.UserExport{ExportedAt: time.Now()}
+
+	if ex.User, err = traced(c, a.rp).GetUserById(id); err != nil {
+		return nil, er.From(err, er.ErrUserNotFound)
+	}
+	if ex.User.Profile, err = traced(c, a.rp).GetUserProfileByUserId(id); err != nil && !errors.Is(err, er.ErrNotFound) {
+		return nil, er.From(err)
+	}
+	if err != nil {
+		ex.User.Profile = nil
+	}
+	if ex.User.Security, err = traced(c, a.rp).GetSecurityInfoByUserId(id); err != nil {
+		return nil, er.From(err, er.ErrUserProfileNotFound)
+	}
+
+	if ex.HostedEvents, err = traced(c, a.rp).GetHostedEventsByUserId(id); err != nil {
+		return nil, er.From(err)
+	}
+	if ex.JoinedEvents, err = traced(c, a.rp).GetJoinedEventsByUserId(id); err != nil {
+		return nil, er.From(err)
+	}
+	if ex.Messages, err = traced(c, a.rp).GetMessagesByUserId(id); err != nil {
+		return nil, er.From(err)
+	}
+	if ex.Following, err = traced(c, a.rp).GetFollowingByUserId(id); err != nil {
+		return nil, er.From(err)
+	}
+	if ex.Followers, err = traced(c, a.rp).GetFollowersByUserId(id); err != nil {
+		return nil, er.From(err)
+	}
+	if ex.Blocked, err = traced(c, a.rp).GetBlockedUsersByUserId(id); err != nil {
+		return nil, er.From(err)
+	}
+	if ex.Notifications, err = traced(c, a.rp).GetNotificationsByUserId(id, false, exportLimit); err != nil {
+		return nil, er.From(err)
+	}
+	if ex.NotificationPreferences, err = traced(c, a.rp).GetNotificationPreferencesByUserId(id); err != nil {
+		return nil, er.From(err)
+	}
+	if ex.Activity, err = traced(c, a.rp).GetAuditEntries(&models.AuditFilter{UserID: id, Limit: exportLimit}); err != nil {
+		return nil, er.From(err)
+	}
+
+	return ex, nil
+}
+
+// exportArchive Writes each part of the export in its own JSON file of a ZIP
+func exportArchive(ex *models.UserExport) ([]byte, error) {
+
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"user.json", ex.User},
+		{"hosted_events.json", ex.HostedEvents},
+		{"joined_events.json", ex.JoinedEvents},
+		{"messages.json", ex.Messages},
+		{"following.json", ex.Following},
+		{"followers.json", ex.Followers},
+		{"blocked.json", ex.Blocked},
+		{"notifications.json", ex.Notifications},
+		{"notification_preferences.json", ex.NotificationPreferences},
+		{"activity.json", ex.Activity},
+	}
+
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+
+	for _, f := range files {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: f.name, Method: zip.Deflate, Modified: ex.ExportedAt})
+		if err != nil {
+			return nil, err
+		}
+		b, err := json.MarshalIndent(f.data, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		if _, err = w.Write(b); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,15 @@
+package api
+
+import (
+	"gopkg.in/go-playground/validator.v9"
+	"strings"
+	"unicode"
+)
+
+// registerTextValidation Registers the nocontrol validation tag. The names and locations end up in the
+// notifications and email subjects, a line break in them would add email headers
+func registerTextValidation(v *validator.Validate) error {
+	return v.RegisterValidation("nocontrol", func(fl validator.FieldLevel) bool {
+		return strings.IndexFunc(fl.Field().String(), unicode.IsControl) < 0
+	})
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"github.com/labstack/echo"
 	mw "github.com/labstack/echo/middleware"
 	"github.com/labstack/gommon/color"
@@ -9,16 +11,29 @@ import (
 	"github.com/pintobikez/popmeet/api"
 	uti "github.com/pintobikez/popmeet/config"
 	cnfs "github.com/pintobikez/popmeet/config/structures"
+	"github.com/pintobikez/popmeet/dbutil"
 	er "github.com/pintobikez/popmeet/errors"
+	"github.com/pintobikez/popmeet/health"
+	"github.com/pintobikez/popmeet/i18n"
+	"github.com/pintobikez/popmeet/jobs"
+	"github.com/pintobikez/popmeet/logging"
+	"github.com/pintobikez/popmeet/mailer"
+	"github.com/pintobikez/popmeet/metrics"
 	mwl "github.com/pintobikez/popmeet/middleware"
+	"github.com/pintobikez/popmeet/notification"
+	"github.com/pintobikez/popmeet/ratelimit"
 	rep "github.com/pintobikez/popmeet/repository"
 	mysql "github.com/pintobikez/popmeet/repository/mysql"
 	"github.com/pintobikez/popmeet/secure"
+	"github.com/pintobikez/popmeet/tracing"
 	"gopkg.in/urfave/cli.v1"
 	"io"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"time"
 )
 
@@ -31,11 +46,14 @@ var (
 	apiInterest *api.InterestApi
 	apiUser     *api.UserApi
 	apiEvent    *api.EventApi
-)
-
-const (
-	StatusAvailable   = "Available"
-	StatusUnavailable = "Unavailable"
+	apiMessage  *api.MessageApi
+	apiBlock    *api.BlockApi
+	apiReport   *api.ReportApi
+	apiFollow   *api.FollowApi
+	apiNotif    *api.NotificationApi
+	apiLanguage *api.LanguageApi
+	apiProvider *api.LoginProviderApi
+	apiAudit    *api.AuditApi
 )
 
 func init() {
@@ -62,6 +80,14 @@ func init() {
 	apiInterest = new(api.InterestApi)
 	apiUser = new(api.UserApi)
 	apiEvent = new(api.EventApi)
+	apiMessage = new(api.MessageApi)
+	apiBlock = new(api.BlockApi)
+	apiReport = new(api.ReportApi)
+	apiFollow = new(api.FollowApi)
+	apiNotif = new(api.NotificationApi)
+	apiLanguage = new(api.LanguageApi)
+	apiProvider = new(api.LoginProviderApi)
+	apiAudit = new(api.AuditApi)
 }
 
 // Start Http Server
@@ -69,62 +95,179 @@ func Handler(c *cli.Context) error {
 
 	// Echo instance
 	e := echo.New()
-	e.HTTPErrorHandler = serverErrorHandler
-	e.Logger.SetLevel(log.INFO)
-	e.Logger.SetOutput(LoadFileWriter(c.String("log-folder") + "/app.log"))
+	level, err := parseLogLevel(c.String("log-level"))
+	if err != nil {
+		return err
+	}
+	e.Logger.SetLevel(level)
+
+	rotate := logging.RotateConfig{
+		MaxSize:    int64(c.Int("log-max-size")) * 1024 * 1024,
+		Interval:   time.Duration(c.Int("log-rotate-hours")) * time.Hour,
+		MaxBackups: c.Int("log-max-backups"),
+		MaxAge:     time.Duration(c.Int("log-max-age-days")) * 24 * time.Hour,
+	}
+	e.Logger.SetOutput(&logging.RedactingWriter{W: LoadFileWriter(c.String("log-folder"), "app.log", rotate)})
+
+	//loads security config
+	secCnf := new(cnfs.SecurityConfig)
+	err = uti.LoadConfigFile(c.String("security-file"), secCnf)
+	if err != nil {
+		e.Logger.Fatal(err)
+	}
+	tknm := &secure.TokenManager{Config: secCnf}
+
+	// the forwarded headers are only honoured from the trusted proxies
+	trusted, err := parseNetworks(secCnf.TrustedProxies)
+	if err != nil {
+		e.Logger.Fatal(err)
+	}
 
 	e.Use(mw.Recover())
 	e.Use(mw.Secure())
 	e.Use(mw.RequestID())
+	e.Use(mwl.ClientIP(trusted))
+	e.Use(mwl.Tracing())
+	e.Use(mwl.AccessLog(&logging.RedactingWriter{W: LoadFileWriter(c.String("log-folder"), "access.log", rotate)}))
+	e.Use(mwl.Metrics())
 	e.Pre(mw.RemoveTrailingSlash())
 
 	//loads db connection
 	dbConfig := new(cnfs.DatabaseConfig)
-	err := uti.LoadConfigFile(c.String("database-file"), dbConfig)
+	err = uti.LoadConfigFile(c.String("database-file"), dbConfig)
 	if err != nil {
 		e.Logger.Fatal(err)
 	}
 
-	repo, err := mysql.New(dbConfig)
+	client, err := mysql.New(dbConfig)
 	if err != nil {
 		e.Logger.Fatal(err)
 	}
 
 	// Database connect
-	err = repo.Connect()
+	err = client.Connect()
 	if err != nil {
 		e.Logger.Fatal(err)
 	}
-	defer repo.Disconnect()
+	defer client.Disconnect()
 
-	//loads security config
-	secCnf := new(cnfs.SecurityConfig)
-	err = uti.LoadConfigFile(c.String("security-file"), secCnf)
+	// Database schema migrations, the version is only recorded once its migration succeeded
+	if c.BoolT("migrate") {
+		migrations, err := mysql.LoadMigrations(dbutil.Migrations)
+		if err != nil {
+			e.Logger.Fatal(err)
+		}
+		applied, err := client.Migrate(context.Background(), migrations)
+		if err != nil {
+			e.Logger.Fatal(err)
+		}
+		if len(applied) > 0 {
+			e.Logger.Infof("Applied the schema migrations %v", applied)
+		}
+	}
+
+	//loads the tracing exporter
+	tracingCnf := new(cnfs.TracingConfig)
+	if c.String("tracing-file") != "" {
+		if err = uti.LoadConfigFile(c.String("tracing-file"), tracingCnf); err != nil {
+			e.Logger.Fatal(err)
+		}
+	}
+	stopTracing, err := tracing.Setup(tracingCnf)
+	if err != nil {
+		e.Logger.Fatal(err)
+	}
+
+	// every repository call is timed and traced
+	repo := tracing.NewRepository(metrics.NewRepository(client))
+	if err = metrics.RegisterDBStats(client.Stats); err != nil {
+		e.Logger.Fatal(err)
+	}
+
+	// loads the translations, the embedded ones replaced by the ones managed by the admins
+	cat, err := i18n.New()
+	if err != nil {
+		e.Logger.Fatal(err)
+	}
+	if err = loadTranslations(cat, repo); err != nil {
+		e.Logger.Fatal(err)
+	}
+	e.HTTPErrorHandler = serverErrorHandler(cat, repo)
+
+	//loads the metrics access
+	allow, metricsToken, err := loadMetricsAccess(c.String("metrics-file"))
+	if err != nil {
+		e.Logger.Fatal(err)
+	}
+
+	// the login provider secrets are stored encrypted, the ones in plain text or of an older key are sealed again
+	envelope, err := secure.NewEnvelope(secCnf.SecretKeys)
+	if err != nil {
+		e.Logger.Fatal(err)
+	}
+	client.SetSealer(envelope)
+	sealed, err := client.SealLoginProviderSecrets()
+	if err != nil {
+		e.Logger.Fatal(err)
+	}
+	if sealed > 0 {
+		e.Logger.Infof("Sealed the secrets of %d login providers with key %s", sealed, secCnf.SecretKeys[0].ID)
+	}
+	policy, err := secure.NewPasswordPolicy(secCnf.PasswordPolicy)
 	if err != nil {
 		e.Logger.Fatal(err)
 	}
-	tknm := &secure.TokenManager{secCnf}
 
-	// Routes => healh
-	e.GET("/health", healthStatus(repo), mw.CORSWithConfig(corsGET))
+	//loads the rate limits
+	limiter, err := loadRateLimiter(c.String("ratelimit-file"))
+	if err != nil {
+		e.Logger.Fatal(err)
+	}
+	e.Use(mwl.RateLimit(limiter, tknm))
 
-	// Routes => interests api
+	//loads the mailer and notification transports
+	mail, notifier, err := loadNotifier(c.String("notification-file"), repo, cat, e.Logger)
+	if err != nil {
+		e.Logger.Fatal(err)
+	}
+
+	// Health checks of the dependencies
+	checker := health.New(time.Duration(c.Int("health-timeout")) * time.Second)
+	checker.Add("database", health.Database(client))
+	checker.Add("schema", health.Schema(client, mysql.SchemaVersion))
+	checker.Add("security", health.Security(tknm))
+
+	// Apis
 	apiInterest.New(repo)
-	e.GET("/interest", apiInterest.GetAllInterest(), mwl.Authorization(tknm), mw.CORSWithConfig(corsGET))
-	e.GET("/interest/:id", apiInterest.GetInterest(), mwl.Authorization(tknm), mw.CORSWithConfig(corsGET))
-
-	// Routes => users api
-	apiUser.New(repo, tknm)
-	e.PUT("/register", apiUser.PutUser(), mw.CORSWithConfig(corsPUT))
-	e.POST("/user", apiUser.PostUser(), mwl.Authorization(tknm), mw.CORSWithConfig(corsPOST))
-	e.POST("/login", apiUser.LoginUser(), mw.CORSWithConfig(corsPOST))
-
-	// Routes => events api
-	apiEvent.New(repo)
-	e.PUT("/event", apiEvent.PutEvent(), mwl.Authorization(tknm), mw.CORSWithConfig(corsPUT))
-	e.GET("/event/:id", apiEvent.GetEvent(), mwl.Authorization(tknm), mw.CORSWithConfig(corsGET))
-	e.PUT("/event/:id/user", apiEvent.AddUserToEvent(), mwl.Authorization(tknm), mw.CORSWithConfig(corsPUT))
-	e.DELETE("/event/:id/user", apiEvent.RemoveUserFromEvent(), mwl.Authorization(tknm), mw.CORSWithConfig(corsDEL))
+	apiUser.New(repo, tknm, mail, cat, policy, secure.NewLoginGuard(secCnf.LoginGuard))
+	apiBlock.New(repo)
+	apiFollow.New(repo)
+	apiReport.New(repo)
+	apiEvent.New(repo, notifier)
+	apiNotif.New(repo)
+	apiMessage.New(repo)
+	apiLanguage.New(repo, cat)
+	apiProvider.New(repo)
+	apiAudit.New(repo)
+
+	// Routes
+	sunset, err := parseSunset(c.String("legacy-sunset"))
+	if err != nil {
+		e.Logger.Fatal(err)
+	}
+	srv := &server{repo: repo, tknm: tknm, checker: checker, metricsAccess: mwl.MetricsAccess(allow, trusted, metricsToken), sunset: sunset}
+	srv.routes(e)
+
+	// Background jobs
+	runner := jobs.NewRunner(repo, jobs.RealClock{}, time.Duration(c.Int("jobs-interval"))*time.Second, e.Logger)
+	jobs.RegisterEventReminders(runner, repo, notifier)
+	// the translations changed by the admins in other instances
+	runner.Every(func(now time.Time) error { return loadTranslations(cat, repo) })
+	jobs.RegisterEventLifecycle(runner, repo, time.Duration(c.Int("archive-after-days"))*24*time.Hour)
+	jobs.RegisterAccountErasure(runner, repo, notifier)
+	if err = runner.Start(); err != nil {
+		e.Logger.Fatal(err)
+	}
 
 	// Start server
 	colorer := color.New()
@@ -137,12 +280,12 @@ func Handler(c *cli.Context) error {
 
 	go func() {
 		if err := start(e, c); err != nil {
-			colorer.Printf(color.Red("⇛ shutting down the server\n"))
+			colorer.Print(color.Red("⇛ shutting down the server\n"))
 		}
 	}()
 
 	// Graceful Shutdown
-	quit := make(chan os.Signal)
+	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt)
 	<-quit
 
@@ -153,6 +296,14 @@ func Handler(c *cli.Context) error {
 		e.Logger.Fatal(err)
 	}
 
+	if err := runner.Stop(ctx); err != nil {
+		e.Logger.Fatal(err)
+	}
+
+	if err := stopTracing(ctx); err != nil {
+		e.Logger.Fatal(err)
+	}
+
 	return nil
 }
 
@@ -171,45 +322,60 @@ func start(e *echo.Echo, c *cli.Context) error {
 }
 
 // ServerErrorHandler sets the format of the error to be return by the server
-func serverErrorHandler(err error, c echo.Context) {
+func serverErrorHandler(cat *i18n.Catalog, ls i18n.LanguageStore) echo.HTTPErrorHandler {
+	return func(err error, c echo.Context) {
 
-	code := http.StatusServiceUnavailable
-	msg := http.StatusText(code)
+		ae := er.From(err)
 
-	if he, ok := err.(*echo.HTTPError); ok {
-		code = he.Code
-		msg = he.Message.(string)
-	}
+		id := c.Response().Header().Get(echo.HeaderXRequestID)
+		traceID := tracing.TraceID(c.Request().Context())
 
-	if c.Echo().Debug {
-		msg = err.Error()
-	}
+		content := map[string]interface{}{
+			"id":       id,
+			"trace_id": traceID,
+			"message":  err.Error(),
+			"code":     ae.Code,
+			"status":   ae.Status,
+		}
 
-	content := map[string]interface{}{
-		"id":      c.Response().Header().Get(echo.HeaderXRequestID),
-		"message": msg,
-		"status":  code,
-	}
+		if ae.Status >= http.StatusInternalServerError {
+			c.Logger().Errorj(content)
+		} else {
+			c.Logger().Infoj(content)
+		}
 
-	c.Logger().Errorj(content)
+		if c.Response().Committed {
+			return
+		}
 
-	if !c.Response().Committed {
 		if c.Request().Method == echo.HEAD {
-			c.NoContent(code)
-		} else {
-			c.JSON(code, er.GeneralErrorJson(code, msg))
+			c.NoContent(ae.Status)
+			return
+		}
+
+		p := cat.Problem(ae, cat.Locale(c, ls))
+		p.Instance, p.RequestID, p.TraceID = c.Request().URL.Path, id, traceID
+		if c.Echo().Debug {
+			p.Detail = err.Error()
+		}
+
+		b, jerr := json.Marshal(p)
+		if jerr != nil {
+			c.Logger().Error(jerr)
+			c.NoContent(ae.Status)
+			return
 		}
+		c.Blob(ae.Status, er.MIMEApplicationProblemJSON, b)
 	}
 }
 
-// File Retrieve the io.Writer from a file if exists, otherwise returns a os.Stdout
-func LoadFileWriter(filePath string) io.Writer {
-	file, err := os.OpenFile(
-		filePath,
-		os.O_RDWR|os.O_CREATE|os.O_APPEND,
-		0666,
-	)
+// File Retrieve a rotating io.Writer for a file in the log folder, otherwise returns a os.Stdout
+func LoadFileWriter(folder string, name string, rc logging.RotateConfig) io.Writer {
+	if folder == "" {
+		return os.Stdout
+	}
 
+	file, err := logging.NewRotatingWriter(filepath.Join(folder, name), rc)
 	if err != nil {
 		return os.Stdout
 	}
@@ -217,17 +383,122 @@ func LoadFileWriter(filePath string) io.Writer {
 	return file
 }
 
-// Handler for Health Status
-func healthStatus(rp rep.Repository) echo.HandlerFunc {
-	return func(c echo.Context) error {
+// parseLogLevel Gets the logger level of a name
+func parseLogLevel(name string) (log.Lvl, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return log.DEBUG, nil
+	case "", "info":
+		return log.INFO, nil
+	case "warn":
+		return log.WARN, nil
+	case "error":
+		return log.ERROR, nil
+	case "off":
+		return log.OFF, nil
+	}
+	return log.INFO, fmt.Errorf("Invalid log level %s", name)
+}
+
+// loadTranslations Loads the translations managed by the admins into the catalog
+func loadTranslations(cat *i18n.Catalog, rp rep.Repository) error {
+	ts, err := rp.GetAllTranslations()
+	if err != nil {
+		return err
+	}
+	cat.Load(ts)
+	return nil
+}
+
+// parseSunset Parses the date, YYYY-MM-DD, when the legacy unversioned routes are removed. Empty when not planned
+func parseSunset(date string) (time.Time, error) {
+	if date == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return t, fmt.Errorf("Invalid legacy sunset date %s, expected YYYY-MM-DD", date)
+	}
+	return t, nil
+}
+
+// loadNotifier Builds the mailer and the notification service with the transports set in the given configuration file.
+// Without configuration file the emails are logged and the notifications are only stored in the users inbox
+func loadNotifier(filePath string, rp rep.Repository, cat *i18n.Catalog, lg echo.Logger) (mailer.Mailer, *notification.Service, error) {
+
+	var transports []notification.Transport
+	var mail mailer.Mailer = &mailer.LogMailer{Logger: lg}
+
+	if filePath != "" {
+		cnf := new(cnfs.NotificationConfig)
+		if err := uti.LoadConfigFile(filePath, cnf); err != nil {
+			return nil, nil, err
+		}
+		if cnf.Smtp != nil {
+			mail = &mailer.SmtpMailer{Config: cnf.Smtp}
+			transports = append(transports, &notification.EmailTransport{Mailer: mail})
+		}
+		if cnf.Push != nil {
+			transports = append(transports, notification.NewWebhookTransport(cnf.Push))
+		}
+	}
+
+	return mail, notification.New(rp, cat, transports...), nil
+}
+
+// loadRateLimiter Builds the rate limiter with the policies of the given configuration file, kept in memory.
+// Without configuration file only the authentication routes are limited
+func loadRateLimiter(filePath string) (*ratelimit.Limiter, error) {
+
+	cnf := ratelimit.DefaultConfig()
+	if filePath != "" {
+		cnf = new(cnfs.RateLimitConfig)
+		if err := uti.LoadConfigFile(filePath, cnf); err != nil {
+			return nil, err
+		}
+	}
+
+	return ratelimit.New(cnf, ratelimit.NewMemoryStore())
+}
 
-		resp := new(er.HealthStatus)
+// loadMetricsAccess Reads the networks and the token allowed to read the metrics from the given configuration file.
+// Without configuration file only the local machine can read them
+func loadMetricsAccess(filePath string) ([]*net.IPNet, string, error) {
 
-		if err := rp.Health(); err != nil {
-			resp.Repo.Status = StatusUnavailable
-			resp.Repo.Detail = err.Error()
+	cnf := &cnfs.MetricsConfig{Allow: []string{"127.0.0.1", "::1"}}
+	if filePath != "" {
+		cnf = new(cnfs.MetricsConfig)
+		if err := uti.LoadConfigFile(filePath, cnf); err != nil {
+			return nil, "", err
 		}
+	}
 
-		return c.JSON(http.StatusOK, resp)
+	allow, err := parseNetworks(cnf.Allow)
+	if err != nil {
+		return nil, "", err
 	}
+
+	return allow, cnf.Token, nil
+}
+
+// parseNetworks Parses the ips or networks in CIDR notation
+func parseNetworks(list []string) ([]*net.IPNet, error) {
+
+	var resp []*net.IPNet
+	for _, a := range list {
+		if !strings.Contains(a, "/") {
+			if strings.Contains(a, ":") {
+				a += "/128"
+			} else {
+				a += "/32"
+			}
+		}
+		_, n, err := net.ParseCIDR(a)
+		if err != nil {
+			return nil, err
+		}
+		resp = append(resp, n)
+	}
+
+	return resp, nil
 }
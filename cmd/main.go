@@ -29,18 +29,101 @@ func main() {
 			Usage:  `Log folder path for access and application logging. Default "stdout"`,
 			EnvVar: "LOG_FOLDER",
 		},
+		cli.StringFlag{
+			Name:   "log-level, ll",
+			Value:  "info",
+			Usage:  "Application log level: debug, info, warn, error or off",
+			EnvVar: "LOG_LEVEL",
+		},
+		cli.IntFlag{
+			Name:   "log-max-size",
+			Value:  100,
+			Usage:  "Size in MB after which the log files are rotated. 0 disables it",
+			EnvVar: "LOG_MAX_SIZE",
+		},
+		cli.IntFlag{
+			Name:   "log-rotate-hours",
+			Value:  24,
+			Usage:  "Hours after which the log files are rotated. 0 disables it",
+			EnvVar: "LOG_ROTATE_HOURS",
+		},
+		cli.IntFlag{
+			Name:   "log-max-backups",
+			Value:  14,
+			Usage:  "Number of rotated log files kept. 0 keeps all",
+			EnvVar: "LOG_MAX_BACKUPS",
+		},
+		cli.IntFlag{
+			Name:   "log-max-age-days",
+			Value:  30,
+			Usage:  "Days the rotated log files are kept. 0 keeps them forever",
+			EnvVar: "LOG_MAX_AGE_DAYS",
+		},
 		cli.StringFlag{
 			Name:   "database-file, d",
 			Value:  "",
 			Usage:  "Database configuration used by the API to connect to database",
 			EnvVar: "DATABASE_FILE",
 		},
+		cli.BoolTFlag{
+			Name:   "migrate",
+			Usage:  "Applies the pending schema migrations of dbutil/migrations on start. Default true",
+			EnvVar: "MIGRATE",
+		},
 		cli.StringFlag{
 			Name:   "security-file, sf",
 			Value:  "",
 			Usage:  "Security configuration",
 			EnvVar: "SECURITY_FILE",
 		},
+		cli.StringFlag{
+			Name:   "notification-file, nf",
+			Value:  "",
+			Usage:  "Notification and email delivery configuration (SMTP and push webhook). Default inbox only and logged emails",
+			EnvVar: "NOTIFICATION_FILE",
+		},
+		cli.StringFlag{
+			Name:   "ratelimit-file, rf",
+			Value:  "",
+			Usage:  "Rate limit policies of the routes. Default only the authentication routes are limited by ip",
+			EnvVar: "RATELIMIT_FILE",
+		},
+		cli.StringFlag{
+			Name:   "metrics-file, mf",
+			Value:  "",
+			Usage:  "Networks and token allowed to read the /metrics endpoint. Default only the local machine",
+			EnvVar: "METRICS_FILE",
+		},
+		cli.StringFlag{
+			Name:   "tracing-file, tf",
+			Value:  "",
+			Usage:  "OpenTelemetry tracing configuration (otlp or stdout exporter). Default no traces are exported",
+			EnvVar: "TRACING_FILE",
+		},
+		cli.StringFlag{
+			Name:   "legacy-sunset",
+			Value:  "2027-04-30",
+			Usage:  "Date, YYYY-MM-DD, sent in the Sunset header of the legacy routes without the /v1 prefix. Empty sends no date",
+			EnvVar: "LEGACY_SUNSET",
+		},
+		cli.IntFlag{
+			Name:   "health-timeout",
+			Value:  2,
+			Usage:  "Seconds each dependency has to answer the readiness probe /health/ready",
+			EnvVar: "HEALTH_TIMEOUT",
+		},
+		cli.IntFlag{
+			Name:   "jobs-interval",
+			Value:  60,
+			Usage:  "Interval in seconds between runs of the background jobs",
+			EnvVar: "JOBS_INTERVAL",
+		},
+		cli.IntFlag{
+			Name:   "archive-after-days",
+			Value:  90,
+			Usage:  "Days after their end date when completed events are moved to the archive. 0 disables archiving",
+			EnvVar: "ARCHIVE_AFTER_DAYS",
+		},
 		cli.StringFlag{
 			Name:   "ssl-cert",
 			Value:  "",
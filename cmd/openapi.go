@@ -0,0 +1,113 @@
+package main
+
+import (
+	"github.com/labstack/echo"
+	"github.com/pintobikez/popmeet/api/models"
+	er "github.com/pintobikez/popmeet/errors"
+	"github.com/pintobikez/popmeet/openapi"
+)
+
+var limitParam = openapi.QueryParam("limit", "integer", "Maximum number of items returned")
+
+// specification Gets the operations of all the routes, served at /openapi.json.
+// The legacy unversioned routes are documented as deprecated
+func specification() []*openapi.Operation {
+	ops := append([]*openapi.Operation{}, serviceOperations...)
+	ops = append(ops, openapi.Mount(v1Operations, "/v1", false)...)
+	return append(ops, openapi.Mount(v1Operations, "", true)...)
+}
+
+// serviceOperations Specification of the unversioned routes of the service
+var serviceOperations = []*openapi.Operation{
+	// health
+	{Method: echo.GET, Path: "/health", Tag: "health", Summary: "Readiness of the service, same as /health/ready", Response: er.HealthStatus{}},
+	{Method: echo.GET, Path: "/health/live", Tag: "health", Summary: "Liveness of the service", Response: er.HealthStatus{}},
+	{Method: echo.GET, Path: "/health/ready", Tag: "health", Summary: "Readiness of the service, 503 when a dependency is unavailable", Response: er.HealthStatus{}},
+	// documentation
+	{Method: echo.GET, Path: "/openapi.json", Tag: "documentation", Summary: "OpenAPI specification of the api"},
+	{Method: echo.GET, Path: "/docs", Tag: "documentation", Summary: "Swagger UI of the specification"},
+	{Method: echo.GET, Path: "/docs/:file", Tag: "documentation", Summary: "Scripts and styles of the Swagger UI"},
+	{Method: echo.GET, Path: "/metrics", Tag: "metrics", Summary: "Prometheus metrics, only for the allowed networks or token"},
+}
+
+// v1Operations Specification of the routes of the version 1 of the api
+var v1Operations = []*openapi.Operation{
+	// interests
+	{Method: echo.GET, Path: "/interest", Tag: "interest", Summary: "List the interests", Auth: true, Response: []*models.Interest{}},
+	{Method: echo.GET, Path: "/interest/:id", Tag: "interest", Summary: "Get an interest", Auth: true, Response: models.Interest{}},
+	// users
+	{Method: echo.PUT, Path: "/register", Tag: "user", Summary: "Register a new user", Request: models.NewUser{}, Response: models.User{}},
+	{Method: echo.POST, Path: "/user", Tag: "user", Summary: "Update the logged user and profile", Auth: true, Request: models.User{}, Response: models.User{}},
+	{Method: echo.POST, Path: "/login", Tag: "user", Summary: "Login, returns a 2FA challenge when it is enabled", Request: models.LoginUser{}, Response: models.User{}, Token: true},
+	{Method: echo.POST, Path: "/user/verify", Tag: "user", Summary: "Verify the email with the emailed token", Request: models.RedeemToken{}},
+	{Method: echo.POST, Path: "/user/verify/request", Tag: "user", Summary: "Send a new verification email", Auth: true},
+	{Method: echo.POST, Path: "/user/email", Tag: "user", Summary: "Change the email, confirmed with a token emailed to the new address", Auth: true, Request: models.ChangeEmail{}},
+	{Method: echo.POST, Path: "/user/email/confirm", Tag: "user", Summary: "Confirm the new email with the emailed token", Request: models.RedeemToken{}},
+	{Method: echo.POST, Path: "/password/forgot", Tag: "user", Summary: "Send a password reset email", Request: models.ForgotPassword{}},
+	{Method: echo.POST, Path: "/password/reset", Tag: "user", Summary: "Reset the password with the emailed token", Request: models.ResetPassword{}},
+	{Method: echo.POST, Path: "/user/password", Tag: "user", Summary: "Change the password, ending the other sessions", Auth: true, Request: models.ChangePassword{}, Token: true},
+	{Method: echo.POST, Path: "/login/2fa", Tag: "user", Summary: "Finish the login with a TOTP or recovery code", Request: models.TwoFactorLogin{}, Response: models.User{}, Token: true},
+	{Method: echo.POST, Path: "/user/2fa/enroll", Tag: "user", Summary: "Create a new TOTP secret", Auth: true, Response: models.TwoFactorEnrollment{}},
+	{Method: echo.POST, Path: "/user/2fa/confirm", Tag: "user", Summary: "Enable the 2FA with the first code, returns the recovery codes", Auth: true, Request: models.TwoFactorCode{}, Response: models.RecoveryCodes{}},
+	{Method: echo.POST, Path: "/user/2fa/disable", Tag: "user", Summary: "Disable the 2FA", Auth: true, Request: models.TwoFactorCode{}},
+	{Method: echo.GET, Path: "/user/activity", Tag: "user", Summary: "Recent activity of the account of the logged user", Auth: true, Query: []*openapi.Param{limitParam, openapi.QueryParam("before", "integer", "Only the entries older than this entry id")}, Response: []*models.AuditEntry{}},
+	{Method: echo.GET, Path: "/user/export", Tag: "user", Summary: "Export all the data about the logged user", Auth: true, Query: []*openapi.Param{openapi.QueryParam("format", "string", "zip, the default, or json")}, Response: models.UserExport{}},
+	{Method: echo.DELETE, Path: "/user", Tag: "user", Summary: "Delete the account of the logged user, erased after the grace period unless the user logs in", Auth: true, Request: models.DeleteAccount{}, Response: models.User{}},
+	// blocks
+	{Method: echo.GET, Path: "/user/block", Tag: "block", Summary: "List the users blocked by the logged user", Auth: true, Response: []*models.User{}},
+	{Method: echo.PUT, Path: "/user/:id/block", Tag: "block", Summary: "Block a user", Auth: true},
+	{Method: echo.DELETE, Path: "/user/:id/block", Tag: "block", Summary: "Unblock a user", Auth: true},
+	// follows
+	{Method: echo.GET, Path: "/user/:id/followers", Tag: "follow", Summary: "List the followers of a user", Auth: true, Response: []*models.User{}},
+	{Method: echo.GET, Path: "/user/:id/following", Tag: "follow", Summary: "List the users followed by a user", Auth: true, Response: []*models.User{}},
+	{Method: echo.PUT, Path: "/user/:id/follow", Tag: "follow", Summary: "Follow a user", Auth: true},
+	{Method: echo.DELETE, Path: "/user/:id/follow", Tag: "follow", Summary: "Unfollow a user", Auth: true},
+	// reports
+	{Method: echo.PUT, Path: "/report", Tag: "report", Summary: "Report a user or an event", Auth: true, Request: models.NewReport{}, Response: models.Report{}},
+	{Method: echo.GET, Path: "/admin/report", Tag: "admin", Summary: "List the reports by status", Auth: true, Admin: true, Query: []*openapi.Param{openapi.QueryParam("status", "string", "Status of the reports, default open")}, Response: []*models.Report{}},
+	{Method: echo.GET, Path: "/admin/report/:id", Tag: "admin", Summary: "Get a report with its actions and related reports", Auth: true, Admin: true, Response: models.ReportContext{}},
+	{Method: echo.POST, Path: "/admin/report/:id/resolve", Tag: "admin", Summary: "Resolve a report with a moderation action", Auth: true, Admin: true, Request: models.NewModerationAction{}, Response: models.Report{}},
+	{Method: echo.POST, Path: "/admin/user/:id/unlock", Tag: "admin", Summary: "Unlock a user locked by failed logins", Auth: true, Admin: true},
+	{Method: echo.POST, Path: "/admin/user/:id/role", Tag: "admin", Summary: "Set the role of a user, ending its sessions", Auth: true, Admin: true, Request: models.UserRole{}, Response: models.User{}},
+	{Method: echo.GET, Path: "/admin/audit", Tag: "admin", Summary: "Query the audit log, newest first", Auth: true, Admin: true, Query: []*openapi.Param{
+		openapi.QueryParam("user", "integer", "Id of the user the actions are about"),
+		openapi.QueryParam("actor", "integer", "Id of the user who did the actions"),
+		openapi.QueryParam("action", "string", "Action, as login.failure"),
+		openapi.QueryParam("from", "string", "RFC 3339 date of the first entries"),
+		openapi.QueryParam("to", "string", "RFC 3339 date the entries are before"),
+		limitParam, openapi.QueryParam("before", "integer", "Only the entries older than this entry id"),
+	}, Response: []*models.AuditEntry{}},
+	// languages
+	{Method: echo.GET, Path: "/language", Tag: "language", Summary: "List the languages the users can choose", Response: []*models.Language{}},
+	{Method: echo.GET, Path: "/language/:id", Tag: "language", Summary: "Get a language", Response: models.Language{}},
+	{Method: echo.GET, Path: "/admin/language", Tag: "admin", Summary: "List all the languages, the disabled ones included", Auth: true, Admin: true, Response: []*models.Language{}},
+	{Method: echo.POST, Path: "/admin/language/:id/enable", Tag: "admin", Summary: "Enable a language", Auth: true, Admin: true, Response: models.Language{}},
+	{Method: echo.POST, Path: "/admin/language/:id/disable", Tag: "admin", Summary: "Disable a language, the profiles using it keep it", Auth: true, Admin: true, Response: models.Language{}},
+	{Method: echo.PUT, Path: "/admin/language", Tag: "admin", Summary: "Add a language with its ISO 639-1 and ISO 639-2 codes", Auth: true, Admin: true, Request: models.NewLanguage{}, Response: models.Language{}},
+	{Method: echo.GET, Path: "/admin/translation", Tag: "admin", Summary: "List the translations of a language", Auth: true, Admin: true, Query: []*openapi.Param{openapi.QueryParam("language", "string", "ISO 639-1 code of the language, default en")}, Response: []*models.Translation{}},
+	{Method: echo.PUT, Path: "/admin/translation", Tag: "admin", Summary: "Set the translation of a text in a language", Auth: true, Admin: true, Request: models.Translation{}, Response: models.Translation{}},
+	{Method: echo.GET, Path: "/admin/login-provider", Tag: "admin", Summary: "List the login providers, without their secrets", Auth: true, Admin: true, Response: []*models.LoginProvider{}},
+	{Method: echo.PUT, Path: "/admin/login-provider", Tag: "admin", Summary: "Add a login provider with its credentials", Auth: true, Admin: true, Request: models.NewLoginProvider{}, Response: models.LoginProvider{}},
+	{Method: echo.POST, Path: "/admin/login-provider/:id", Tag: "admin", Summary: "Update the name and client ids of a login provider", Auth: true, Admin: true, Request: models.LoginProviderUpdate{}, Response: models.LoginProvider{}},
+	{Method: echo.POST, Path: "/admin/login-provider/:id/rotate", Tag: "admin", Summary: "Rotate the secrets of a login provider, the ones not sent are kept", Auth: true, Admin: true, Request: models.LoginProviderSecrets{}, Response: models.LoginProvider{}},
+	// events
+	{Method: echo.PUT, Path: "/event", Tag: "event", Summary: "Create an event", Auth: true, Request: models.NewEvent{}, Response: models.Event{}},
+	{Method: echo.GET, Path: "/event/:id", Tag: "event", Summary: "Get an event", Auth: true, Response: models.Event{}},
+	{Method: echo.POST, Path: "/event/:id", Tag: "event", Summary: "Update an event of the logged user", Auth: true, Request: models.NewEvent{}, Response: models.Event{}},
+	{Method: echo.DELETE, Path: "/event/:id", Tag: "event", Summary: "Cancel an event of the logged user", Auth: true},
+	{Method: echo.PUT, Path: "/event/:id/user", Tag: "event", Summary: "Join an event", Auth: true},
+	{Method: echo.DELETE, Path: "/event/:id/user", Tag: "event", Summary: "Leave an event", Auth: true},
+	{Method: echo.GET, Path: "/feed", Tag: "event", Summary: "Upcoming events of the followed users", Auth: true, Query: []*openapi.Param{limitParam}, Response: []*models.Event{}},
+	// notifications
+	{Method: echo.GET, Path: "/notifications", Tag: "notification", Summary: "List the notifications", Auth: true, Query: []*openapi.Param{limitParam, openapi.QueryParam("unread", "boolean", "Only the unread notifications")}, Response: []*models.Notification{}},
+	{Method: echo.POST, Path: "/notifications/read", Tag: "notification", Summary: "Mark all the notifications as read", Auth: true},
+	{Method: echo.POST, Path: "/notifications/:id/read", Tag: "notification", Summary: "Mark a notification as read", Auth: true},
+	{Method: echo.GET, Path: "/notifications/preferences", Tag: "notification", Summary: "List the notification preferences", Auth: true, Response: []*models.NotificationPreference{}},
+	{Method: echo.POST, Path: "/notifications/preferences", Tag: "notification", Summary: "Update the notification preferences", Auth: true, Request: []*models.NotificationPreference{}, Response: []*models.NotificationPreference{}},
+	// messages
+	{Method: echo.GET, Path: "/conversation", Tag: "message", Summary: "List the conversations", Auth: true, Response: []*models.Conversation{}},
+	{Method: echo.GET, Path: "/conversation/unread", Tag: "message", Summary: "Count the unread messages", Auth: true, Response: models.UnreadCount{}},
+	{Method: echo.GET, Path: "/conversation/:id/message", Tag: "message", Summary: "List the messages with a user", Auth: true, Query: []*openapi.Param{limitParam, openapi.QueryParam("before", "integer", "Only the messages older than this message id")}, Response: []*models.Message{}},
+	{Method: echo.PUT, Path: "/conversation/:id/message", Tag: "message", Summary: "Send a message to a user", Auth: true, Request: models.NewMessage{}, Response: models.Message{}},
+	{Method: echo.POST, Path: "/conversation/:id/read", Tag: "message", Summary: "Mark the messages of a user as read", Auth: true},
+}
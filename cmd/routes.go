@@ -0,0 +1,82 @@
+package main
+
+import (
+	"github.com/labstack/echo"
+	mw "github.com/labstack/echo/middleware"
+	"github.com/pintobikez/popmeet/health"
+	mwl "github.com/pintobikez/popmeet/middleware"
+	"github.com/pintobikez/popmeet/openapi"
+	rep "github.com/pintobikez/popmeet/repository"
+	"github.com/pintobikez/popmeet/secure"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"time"
+)
+
+// server Dependencies shared by the routes
+type server struct {
+	repo          rep.Repository
+	tknm          *secure.TokenManager
+	checker       *health.Checker
+	metricsAccess echo.MiddlewareFunc
+	sunset        time.Time
+}
+
+// router Registers the routes, implemented by echo and by apiVersion
+type router interface {
+	GET(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	POST(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	PUT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	DELETE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+}
+
+// apiVersion Registers the routes of a version of the api under its prefix, with the middlewares of the version.
+// echo.Group is not used, it adds catch all routes for every group
+type apiVersion struct {
+	e          *echo.Echo
+	prefix     string
+	middleware []echo.MiddlewareFunc
+}
+
+func (v *apiVersion) add(method string, path string, h echo.HandlerFunc, m []echo.MiddlewareFunc) *echo.Route {
+	return v.e.Add(method, v.prefix+path, h, append(append([]echo.MiddlewareFunc{}, v.middleware...), m...)...)
+}
+
+func (v *apiVersion) GET(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return v.add(echo.GET, path, h, m)
+}
+
+func (v *apiVersion) POST(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return v.add(echo.POST, path, h, m)
+}
+
+func (v *apiVersion) PUT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return v.add(echo.PUT, path, h, m)
+}
+
+func (v *apiVersion) DELETE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return v.add(echo.DELETE, path, h, m)
+}
+
+// routes Registers the routes of the service, each one needs an entry in the operations of the specification.
+// The api is versioned by prefix, every version has its own handlers sharing the same repository
+func (s *server) routes(e *echo.Echo) {
+
+	// Routes => health
+	e.GET("/health", s.checker.Ready(), mw.CORSWithConfig(corsGET))
+	e.GET("/health/live", s.checker.Live(), mw.CORSWithConfig(corsGET))
+	e.GET("/health/ready", s.checker.Ready(), mw.CORSWithConfig(corsGET))
+
+	// Routes => documentation
+	doc := openapi.Build(appName, version, specification())
+	e.GET("/openapi.json", openapi.Handler(doc), mw.CORSWithConfig(corsGET))
+	e.GET("/docs", openapi.UI(appName, "/openapi.json", "/docs"), mw.CORSWithConfig(corsGET))
+	e.GET("/docs/:file", openapi.Assets(), mw.CORSWithConfig(corsGET))
+
+	// Routes => metrics
+	e.GET("/metrics", echo.WrapHandler(promhttp.Handler()), s.metricsAccess)
+
+	// Routes => api versions
+	s.v1(&apiVersion{e: e, prefix: "/v1"})
+	// the legacy unversioned routes are kept as aliases of v1 until the sunset
+	s.v1(&apiVersion{e: e, middleware: []echo.MiddlewareFunc{mwl.Deprecated(s.sunset, "/v1")}})
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"github.com/labstack/echo"
+	cnfs "github.com/pintobikez/popmeet/config/structures"
+	"github.com/pintobikez/popmeet/health"
+	mwl "github.com/pintobikez/popmeet/middleware"
+	"github.com/pintobikez/popmeet/openapi"
+	"github.com/pintobikez/popmeet/secure"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+/* Test for the specification of the routes, every route needs an entry in the operations */
+func TestRoutesSpecification(t *testing.T) {
+
+	e := echo.New()
+	srv := &server{tknm: &secure.TokenManager{}, checker: health.New(0), metricsAccess: mwl.MetricsAccess(nil, nil, "")}
+	srv.routes(e)
+
+	doc := openapi.Build(appName, version, specification())
+
+	// Assertions
+	assert.NotEmpty(t, e.Routes())
+	assert.Empty(t, doc.Missing(e.Routes()), "routes without an entry in cmd/openapi.go")
+}
+
+/* Test for the legacy unversioned routes, aliases of v1 with the deprecation headers */
+func TestLegacyRoutes(t *testing.T) {
+
+	e := echo.New()
+	sunset := time.Date(2027, 4, 30, 0, 0, 0, 0, time.UTC)
+	tknm := &secure.TokenManager{Config: &cnfs.SecurityConfig{TTL: 60, CipherKey: "test"}}
+	srv := &server{tknm: tknm, checker: health.New(0), metricsAccess: mwl.MetricsAccess(nil, nil, ""), sunset: sunset}
+	srv.routes(e)
+
+	legacy := httptest.NewRecorder()
+	e.ServeHTTP(legacy, httptest.NewRequest(echo.GET, "/interest", nil))
+	current := httptest.NewRecorder()
+	e.ServeHTTP(current, httptest.NewRequest(echo.GET, "/v1/interest", nil))
+
+	// Assertions
+	assert.Equal(t, current.Code, legacy.Code)
+	assert.Equal(t, "true", legacy.Header().Get(mwl.HeaderDeprecation))
+	assert.Equal(t, "Fri, 30 Apr 2027 00:00:00 GMT", legacy.Header().Get(mwl.HeaderSunset))
+	assert.Equal(t, `</v1/interest>; rel="successor-version"`, legacy.Header().Get(mwl.HeaderLink))
+	assert.Empty(t, current.Header().Get(mwl.HeaderDeprecation))
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	mw "github.com/labstack/echo/middleware"
+	mwl "github.com/pintobikez/popmeet/middleware"
+)
+
+// v1 Registers the routes of the version 1 of the api
+func (s *server) v1(r router) {
+
+	// Routes => interests api
+	r.GET("/interest", apiInterest.GetAllInterest(), mwl.Authorization(s.tknm, s.repo), mw.CORSWithConfig(corsGET))
+	r.GET("/interest/:id", apiInterest.GetInterest(), mwl.Authorization(s.tknm, s.repo), mw.CORSWithConfig(corsGET))
+
+	// Routes => users api
+	r.PUT("/register", apiUser.PutUser(), mw.CORSWithConfig(corsPUT))
+	r.POST("/user", apiUser.PostUser(), mwl.Authorization(s.tknm, s.repo), mw.CORSWithConfig(corsPOST))
+	r.POST("/login", apiUser.LoginUser(), mw.CORSWithConfig(corsPOST))
+	r.POST("/user/verify", apiUser.VerifyEmail(), mw.CORSWithConfig(corsPOST))
+	r.POST("/user/verify/request", apiUser.RequestVerification(), mwl.Authorization(s.tknm, s.repo), mw.CORSWithConfig(corsPOST))
+	r.POST("/user/email", apiUser.ChangeEmail(), mwl.Authorization(s.tknm, s.repo), mw.CORSWithConfig(corsPOST))
+	r.POST("/user/email/confirm", apiUser.ConfirmEmail(), mw.CORSWithConfig(corsPOST))
+	r.POST("/password/forgot", apiUser.ForgotPassword(), mw.CORSWithConfig(corsPOST))
+	r.POST("/password/reset", apiUser.ResetPassword(), mw.CORSWithConfig(corsPOST))
+	r.POST("/user/password", apiUser.ChangePassword(), mwl.Authorization(s.tknm, s.repo), mw.CORSWithConfig(corsPOST))
+	r.POST("/login/2fa", apiUser.LoginTwoFactor(), mw.CORSWithConfig(corsPOST))
+	r.POST("/user/2fa/enroll", apiUser.EnrollTwoFactor(), mwl.Authorization(s.tknm, s.repo), mw.CORSWithConfig(corsPOST))
+	r.POST("/user/2fa/confirm", apiUser.ConfirmTwoFactor(), mwl.Authorization(s.tknm, s.repo), mw.CORSWithConfig(corsPOST))
+	r.POST("/user/2fa/disable", apiUser.DisableTwoFactor(), mwl.Authorization(s.tknm, s.repo), mw.CORSWithConfig(corsPOST))
+	r.GET("/user/activity", apiAudit.GetActivity(), mwl.Authorization(s.tknm, s.repo), mw.CORSWithConfig(corsGET))
+	r.GET("/user/export", apiUser.ExportUser(), mwl.Authorization(s.tknm, s.repo), mw.CORSWithConfig(corsGET))
+	r.DELETE("/user", apiUser.DeleteUser(), mwl.Authorization(s.tknm, s.repo), mw.CORSWithConfig(corsDEL))
+
+	// Routes => blocks api
+	r.GET("/user/block", apiBlock.GetBlockedUsers(), mwl.Authorization(s.tknm, s.repo), mw.CORSWithConfig(corsGET))
+	r.PUT("/user/:id/block", apiBlock.BlockUser(), mwl.Authorization(s.tknm, s.repo), mw.CORSWithConfig(corsPUT))
+	r.DELETE("/user/:id/block", apiBlock.UnblockUser(), mwl.Authorization(s.tknm, s.repo), mw.CORSWithConfig(corsDEL))
+
+	// Routes => follows api
+	r.GET("/user/:id/followers", apiFollow.GetFollowers(), mwl.Authorization(s.tknm, s.repo), mw.CORSWithConfig(corsGET))
+	r.GET("/user/:id/following", apiFollow.GetFollowing(), mwl.Authorization(s.tknm, s.repo), mw.CORSWithConfig(corsGET))
+	r.PUT("/user/:id/follow", apiFollow.FollowUser(), mwl.Authorization(s.tknm, s.repo), mw.CORSWithConfig(corsPUT))
+	r.DELETE("/user/:id/follow", apiFollow.UnfollowUser(), mwl.Authorization(s.tknm, s.repo), mw.CORSWithConfig(corsDEL))
+
+	// Routes => reports api
+	r.PUT("/report", apiReport.PutReport(), mwl.Authorization(s.tknm, s.repo), mw.CORSWithConfig(corsPUT))
+	r.GET("/admin/report", apiReport.GetReports(), mwl.Authorization(s.tknm, s.repo), mwl.Admin(), mw.CORSWithConfig(corsGET))
+	r.GET("/admin/report/:id", apiReport.GetReport(), mwl.Authorization(s.tknm, s.repo), mwl.Admin(), mw.CORSWithConfig(corsGET))
+	r.POST("/admin/report/:id/resolve", apiReport.ResolveReport(), mwl.Authorization(s.tknm, s.repo), mwl.Admin(), mw.CORSWithConfig(corsPOST))
+	r.POST("/admin/user/:id/unlock", apiUser.UnlockUser(), mwl.Authorization(s.tknm, s.repo), mwl.Admin(), mw.CORSWithConfig(corsPOST))
+	r.POST("/admin/user/:id/role", apiUser.SetRole(), mwl.Authorization(s.tknm, s.repo), mwl.Admin(), mw.CORSWithConfig(corsPOST))
+	r.GET("/admin/audit", apiAudit.GetAuditLog(), mwl.Authorization(s.tknm, s.repo), mwl.Admin(), mw.CORSWithConfig(corsGET))
+
+	// Routes => languages api
+	r.GET("/language", apiLanguage.GetLanguages(), mw.CORSWithConfig(corsGET))
+	r.GET("/language/:id", apiLanguage.GetLanguage(), mw.CORSWithConfig(corsGET))
+	r.GET("/admin/language", apiLanguage.GetAllLanguages(), mwl.Authorization(s.tknm, s.repo), mwl.Admin(), mw.CORSWithConfig(corsGET))
+	r.POST("/admin/language/:id/enable", apiLanguage.EnableLanguage(), mwl.Authorization(s.tknm, s.repo), mwl.Admin(), mw.CORSWithConfig(corsPOST))
+	r.POST("/admin/language/:id/disable", apiLanguage.DisableLanguage(), mwl.Authorization(s.tknm, s.repo), mwl.Admin(), mw.CORSWithConfig(corsPOST))
+	r.PUT("/admin/language", apiLanguage.PutLanguage(), mwl.Authorization(s.tknm, s.repo), mwl.Admin(), mw.CORSWithConfig(corsPUT))
+	r.GET("/admin/translation", apiLanguage.GetTranslations(), mwl.Authorization(s.tknm, s.repo), mwl.Admin(), mw.CORSWithConfig(corsGET))
+	r.PUT("/admin/translation", apiLanguage.PutTranslation(), mwl.Authorization(s.tknm, s.repo), mwl.Admin(), mw.CORSWithConfig(corsPUT))
+	r.GET("/admin/login-provider", apiProvider.GetLoginProviders(), mwl.Authorization(s.tknm, s.repo), mwl.Admin(), mw.CORSWithConfig(corsGET))
+	r.PUT("/admin/login-provider", apiProvider.PutLoginProvider(), mwl.Authorization(s.tknm, s.repo), mwl.Admin(), mw.CORSWithConfig(corsPUT))
+	r.POST("/admin/login-provider/:id", apiProvider.PostLoginProvider(), mwl.Authorization(s.tknm, s.repo), mwl.Admin(), mw.CORSWithConfig(corsPOST))
+	r.POST("/admin/login-provider/:id/rotate", apiProvider.RotateSecrets(), mwl.Authorization(s.tknm, s.repo), mwl.Admin(), mw.CORSWithConfig(corsPOST))
+
+	// Routes => events api
+	r.PUT("/event", apiEvent.PutEvent(), mwl.Authorization(s.tknm, s.repo), mw.CORSWithConfig(corsPUT))
+	r.GET("/event/:id", apiEvent.GetEvent(), mwl.Authorization(s.tknm, s.repo), mw.CORSWithConfig(corsGET))
+	r.POST("/event/:id", apiEvent.PostEvent(), mwl.Authorization(s.tknm, s.repo), mw.CORSWithConfig(corsPOST))
+	r.DELETE("/event/:id", apiEvent.CancelEvent(), mwl.Authorization(s.tknm, s.repo), mw.CORSWithConfig(corsDEL))
+	r.PUT("/event/:id/user", apiEvent.AddUserToEvent(), mwl.Authorization(s.tknm, s.repo), mw.CORSWithConfig(corsPUT))
+	r.DELETE("/event/:id/user", apiEvent.RemoveUserFromEvent(), mwl.Authorization(s.tknm, s.repo), mw.CORSWithConfig(corsDEL))
+	r.GET("/feed", apiEvent.GetFeed(), mwl.Authorization(s.tknm, s.repo), mw.CORSWithConfig(corsGET))
+
+	// Routes => notifications api
+	r.GET("/notifications", apiNotif.GetNotifications(), mwl.Authorization(s.tknm, s.repo), mw.CORSWithConfig(corsGET))
+	r.POST("/notifications/read", apiNotif.MarkAllAsRead(), mwl.Authorization(s.tknm, s.repo), mw.CORSWithConfig(corsPOST))
+	r.POST("/notifications/:id/read", apiNotif.MarkAsRead(), mwl.Authorization(s.tknm, s.repo), mw.CORSWithConfig(corsPOST))
+	r.GET("/notifications/preferences", apiNotif.GetPreferences(), mwl.Authorization(s.tknm, s.repo), mw.CORSWithConfig(corsGET))
+	r.POST("/notifications/preferences", apiNotif.PostPreferences(), mwl.Authorization(s.tknm, s.repo), mw.CORSWithConfig(corsPOST))
+
+	// Routes => messages api
+	r.GET("/conversation", apiMessage.GetConversations(), mwl.Authorization(s.tknm, s.repo), mw.CORSWithConfig(corsGET))
+	r.GET("/conversation/unread", apiMessage.GetUnreadCount(), mwl.Authorization(s.tknm, s.repo), mw.CORSWithConfig(corsGET))
+	r.GET("/conversation/:id/message", apiMessage.GetMessages(), mwl.Authorization(s.tknm, s.repo), mw.CORSWithConfig(corsGET))
+	r.PUT("/conversation/:id/message", apiMessage.SendMessage(), mwl.Authorization(s.tknm, s.repo), mw.CORSWithConfig(corsPUT))
+	r.POST("/conversation/:id/read", apiMessage.MarkAsRead(), mwl.Authorization(s.tknm, s.repo), mw.CORSWithConfig(corsPOST))
+}
@@ -3,6 +3,43 @@ package structures
 type SecurityConfig struct {
 	CipherKey string `yaml:"cipherkey"`
 	TTL       int    `yaml:"ttl"`
+	AppUrl    string `yaml:"app_url,omitempty"`
+	VerifyTTL int    `yaml:"verify_ttl,omitempty"`
+	ResetTTL  int    `yaml:"reset_ttl,omitempty"`
+	// Issuer is the name shown in the authenticator apps
+	Issuer       string `yaml:"issuer,omitempty"`
+	ChallengeTTL int    `yaml:"challenge_ttl,omitempty"`
+	// DeletionGrace is the number of days a deleted account waits before it is erased
+	DeletionGrace int `yaml:"deletion_grace,omitempty"`
+
+	PasswordPolicy PasswordPolicyConfig `yaml:"password_policy,omitempty"`
+	LoginGuard     LoginGuardConfig     `yaml:"login_guard,omitempty"`
+	// SecretKeys encrypt the secrets stored in the database, the first one seals and all of them open
+	SecretKeys []SecretKeyConfig `yaml:"secret_keys,omitempty"`
+	// TrustedProxies lists the ips or networks in CIDR notation of the proxies whose forwarded headers are honoured
+	TrustedProxies []string `yaml:"trusted_proxies,omitempty"`
+}
+
+type SecretKeyConfig struct {
+	ID string `yaml:"id"`
+	// Key is a base64 AES-256 key
+	Key string `yaml:"key"`
+}
+
+type LoginGuardConfig struct {
+	MaxFailures    int `yaml:"max_failures,omitempty"`
+	LockoutMinutes int `yaml:"lockout_minutes,omitempty"`
+	// BackoffBase and BackoffMax are in seconds
+	BackoffBase   int `yaml:"backoff_base,omitempty"`
+	BackoffMax    int `yaml:"backoff_max,omitempty"`
+	IpMaxFailures int `yaml:"ip_max_failures,omitempty"`
+	// IpWindow is in minutes
+	IpWindow int `yaml:"ip_window,omitempty"`
+}
+
+type PasswordPolicyConfig struct {
+	MinLength    int    `yaml:"min_length,omitempty"`
+	BreachedFile string `yaml:"breached_file,omitempty"`
 }
 
 type DatabaseConfig struct {
@@ -12,3 +49,52 @@ type DatabaseConfig struct {
 	Port   int    `yaml:"port,omitempty"`
 	Schema string `yaml:"schema,omitempty"`
 }
+
+type NotificationConfig struct {
+	Smtp *SmtpConfig    `yaml:"smtp,omitempty"`
+	Push *WebhookConfig `yaml:"push,omitempty"`
+}
+
+type SmtpConfig struct {
+	Host string `yaml:"host"`
+	Port int    `yaml:"port"`
+	User string `yaml:"user,omitempty"`
+	Pw   string `yaml:"pw,omitempty"`
+	From string `yaml:"from"`
+}
+
+type WebhookConfig struct {
+	Url     string `yaml:"url"`
+	Token   string `yaml:"token,omitempty"`
+	Timeout int    `yaml:"timeout,omitempty"`
+}
+
+type RateLimitConfig struct {
+	// Default is the policy of the routes not listed, empty for no limit
+	Default  string                       `yaml:"default,omitempty"`
+	Policies map[string]*RatePolicyConfig `yaml:"policies"`
+	// Routes maps "METHOD /path" to a policy name
+	Routes map[string]string `yaml:"routes,omitempty"`
+}
+
+type RatePolicyConfig struct {
+	PerMinute float64 `yaml:"per_minute"`
+	Burst     int     `yaml:"burst"`
+	// Key is ip or user, user falls back to the ip for anonymous requests
+	Key string `yaml:"key,omitempty"`
+}
+
+type MetricsConfig struct {
+	// Allow lists the ips or networks in CIDR notation that can read the metrics
+	Allow []string `yaml:"allow,omitempty"`
+	Token string   `yaml:"token,omitempty"`
+}
+
+type TracingConfig struct {
+	// Exporter is none, stdout or otlp
+	Exporter    string  `yaml:"exporter"`
+	Endpoint    string  `yaml:"endpoint,omitempty"`
+	Insecure    bool    `yaml:"insecure,omitempty"`
+	ServiceName string  `yaml:"service_name,omitempty"`
+	SampleRatio float64 `yaml:"sample_ratio,omitempty"`
+}
@@ -0,0 +1,9 @@
+package dbutil
+
+import "embed"
+
+// Migrations are the changes to the schema of popmeet.sql, numbered in the order they are applied.
+// Each statement ends a line with a semicolon and can run again, a migration stopped midway is retried
+//
+//go:embed migrations/*.sql
+var Migrations embed.FS
@@ -0,0 +1,171 @@
+-- the changes made to the schema before it had versions: roles, email verification, sessions, two factor
+-- authentication, login lockouts, blocks, messages, reports, follows, notifications, jobs and the event archive
+
+ALTER TABLE `user` ADD COLUMN `role` enum('user','admin') NOT NULL DEFAULT 'user' AFTER `active`;
+ALTER TABLE `user` ADD COLUMN `email_verified_at` datetime NULL DEFAULT NULL AFTER `role`;
+
+ALTER TABLE `event` ADD COLUMN `completed_at` datetime NULL DEFAULT NULL AFTER `fk_created_by`;
+ALTER TABLE `event` ADD KEY `idx_end_datetime` (`end_datetime`) USING BTREE;
+
+ALTER TABLE `user_security` ADD COLUMN `session_version` int(11) unsigned NOT NULL DEFAULT 0 AFTER `hash`;
+ALTER TABLE `user_security` ADD COLUMN `totp_secret` varchar(64) NULL DEFAULT NULL AFTER `session_version`;
+ALTER TABLE `user_security` ADD COLUMN `totp_enabled_at` datetime NULL DEFAULT NULL AFTER `totp_secret`;
+ALTER TABLE `user_security` ADD COLUMN `totp_last_step` bigint(20) unsigned NULL DEFAULT NULL AFTER `totp_enabled_at`;
+ALTER TABLE `user_security` ADD COLUMN `failed_attempts` int(11) unsigned NOT NULL DEFAULT 0 AFTER `totp_last_step`;
+ALTER TABLE `user_security` ADD COLUMN `last_failed_at` datetime NULL DEFAULT NULL AFTER `failed_attempts`;
+ALTER TABLE `user_security` ADD COLUMN `last_failed_machine` varchar(255) NULL DEFAULT NULL AFTER `last_failed_at`;
+ALTER TABLE `user_security` ADD COLUMN `locked_until` datetime NULL DEFAULT NULL AFTER `last_failed_machine`;
+
+CREATE TABLE IF NOT EXISTS `user_block` (
+  `fk_blocker` int(11) unsigned NOT NULL,
+  `fk_blocked` int(11) unsigned NOT NULL,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (`fk_blocker`) REFERENCES user(`id`) ON UPDATE CASCADE ON DELETE RESTRICT,
+  FOREIGN KEY (`fk_blocked`) REFERENCES user(`id`) ON UPDATE CASCADE ON DELETE RESTRICT,
+  UNIQUE KEY unique_keys (fk_blocker,fk_blocked)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `message` (
+  `id` int(11) unsigned NOT NULL AUTO_INCREMENT,
+  `fk_sender` int(11) unsigned NOT NULL,
+  `fk_recipient` int(11) unsigned NOT NULL,
+  `body` text NOT NULL,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `read_at` datetime NULL DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_sender_recipient` (`fk_sender`,`fk_recipient`) USING BTREE,
+  KEY `idx_recipient_read` (`fk_recipient`,`read_at`) USING BTREE,
+  FOREIGN KEY (`fk_sender`) REFERENCES user(`id`) ON UPDATE CASCADE ON DELETE RESTRICT,
+  FOREIGN KEY (`fk_recipient`) REFERENCES user(`id`) ON UPDATE CASCADE ON DELETE RESTRICT
+) ENGINE=InnoDB AUTO_INCREMENT=0 DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `report` (
+  `id` int(11) unsigned NOT NULL AUTO_INCREMENT,
+  `fk_reporter` int(11) unsigned NOT NULL,
+  `fk_user` int(11) unsigned NULL DEFAULT NULL,
+  `fk_event` int(11) unsigned NULL DEFAULT NULL,
+  `reason` varchar(1000) NOT NULL,
+  `status` enum('open','dismissed','warned','user_deactivated','event_deactivated') NOT NULL DEFAULT 'open',
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` datetime DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_status` (`status`) USING BTREE,
+  FOREIGN KEY (`fk_reporter`) REFERENCES user(`id`) ON UPDATE CASCADE ON DELETE RESTRICT,
+  FOREIGN KEY (`fk_user`) REFERENCES user(`id`) ON UPDATE CASCADE ON DELETE RESTRICT,
+  FOREIGN KEY (`fk_event`) REFERENCES event(`id`) ON UPDATE CASCADE ON DELETE RESTRICT
+) ENGINE=InnoDB AUTO_INCREMENT=0 DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `moderation_action` (
+  `id` int(11) unsigned NOT NULL AUTO_INCREMENT,
+  `fk_report` int(11) unsigned NOT NULL,
+  `fk_moderator` int(11) unsigned NOT NULL,
+  `action` enum('dismiss','warn','deactivate_user','deactivate_event') NOT NULL,
+  `reason` varchar(1000) NOT NULL,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  FOREIGN KEY (`fk_report`) REFERENCES report(`id`) ON UPDATE CASCADE ON DELETE RESTRICT,
+  FOREIGN KEY (`fk_moderator`) REFERENCES user(`id`) ON UPDATE CASCADE ON DELETE RESTRICT
+) ENGINE=InnoDB AUTO_INCREMENT=0 DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `user_follow` (
+  `fk_follower` int(11) unsigned NOT NULL,
+  `fk_followed` int(11) unsigned NOT NULL,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  KEY `idx_followed` (`fk_followed`) USING BTREE,
+  FOREIGN KEY (`fk_follower`) REFERENCES user(`id`) ON UPDATE CASCADE ON DELETE RESTRICT,
+  FOREIGN KEY (`fk_followed`) REFERENCES user(`id`) ON UPDATE CASCADE ON DELETE RESTRICT,
+  UNIQUE KEY unique_keys (fk_follower,fk_followed)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `notification` (
+  `id` int(11) unsigned NOT NULL AUTO_INCREMENT,
+  `fk_user` int(11) unsigned NOT NULL,
+  `type` varchar(50) NOT NULL,
+  `title` varchar(255) NOT NULL,
+  `body` varchar(1000) NOT NULL,
+  `data` text NULL,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `read_at` datetime NULL DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_user_read` (`fk_user`,`read_at`) USING BTREE,
+  FOREIGN KEY (`fk_user`) REFERENCES user(`id`) ON UPDATE CASCADE ON DELETE RESTRICT
+) ENGINE=InnoDB AUTO_INCREMENT=0 DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `notification_preference` (
+  `fk_user` int(11) unsigned NOT NULL,
+  `type` varchar(50) NOT NULL,
+  `channel` enum('email','push') NOT NULL,
+  `enabled` tinyint(1) NOT NULL DEFAULT 1,
+  `updated_at` datetime DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (`fk_user`) REFERENCES user(`id`) ON UPDATE CASCADE ON DELETE RESTRICT,
+  UNIQUE KEY unique_keys (fk_user,type,channel)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `job` (
+  `id` int(11) unsigned NOT NULL AUTO_INCREMENT,
+  `type` varchar(50) NOT NULL,
+  `ref_id` int(11) unsigned NOT NULL,
+  `run_at` datetime NOT NULL,
+  `status` enum('pending','running','done','failed') NOT NULL DEFAULT 'pending',
+  `attempts` int(4) unsigned NOT NULL DEFAULT 0,
+  `last_error` varchar(1000) NOT NULL DEFAULT '',
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` datetime DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_status_run_at` (`status`,`run_at`) USING BTREE,
+  UNIQUE KEY unique_keys (type,ref_id)
+) ENGINE=InnoDB AUTO_INCREMENT=0 DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `event_archive` (
+  `id` int(11) unsigned NOT NULL,
+  `created_at` datetime NOT NULL,
+  `start_datetime` datetime NOT NULL,
+  `end_datetime` datetime NOT NULL,
+  `location` varchar(255) NOT NULL,
+  `latitude` varchar(32) NOT NULL,
+  `longitude` varchar(32) NOT NULL,
+  `active` tinyint(1) NOT NULL,
+  `fk_created_by` int(11) unsigned NOT NULL,
+  `completed_at` datetime NULL DEFAULT NULL,
+  `archived_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_created_by` (`fk_created_by`) USING BTREE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `event_users_archive` (
+  `fk_event` int(11) unsigned NOT NULL,
+  `fk_user` int(11) unsigned NOT NULL,
+  UNIQUE KEY unique_keys (fk_event,fk_user),
+  KEY `idx_user` (`fk_user`) USING BTREE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `user_token` (
+  `id` int(11) unsigned NOT NULL AUTO_INCREMENT,
+  `fk_user` int(11) unsigned NOT NULL,
+  `purpose` varchar(30) NOT NULL,
+  `token_hash` char(64) NOT NULL,
+  `expires_at` datetime NOT NULL,
+  `used_at` datetime NULL DEFAULT NULL,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_token_hash` (`token_hash`),
+  KEY `idx_user_purpose` (`fk_user`,`purpose`) USING BTREE,
+  FOREIGN KEY (`fk_user`) REFERENCES user(`id`) ON UPDATE CASCADE ON DELETE RESTRICT
+) ENGINE=InnoDB AUTO_INCREMENT=0 DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `user_recovery_code` (
+  `id` int(11) unsigned NOT NULL AUTO_INCREMENT,
+  `fk_user` int(11) unsigned NOT NULL,
+  `code_hash` char(64) NOT NULL,
+  `used_at` datetime NULL DEFAULT NULL,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_user_code` (`fk_user`,`code_hash`),
+  FOREIGN KEY (`fk_user`) REFERENCES user(`id`) ON UPDATE CASCADE ON DELETE RESTRICT
+) ENGINE=InnoDB AUTO_INCREMENT=0 DEFAULT CHARSET=utf8;
+
+-- the accounts created before the email verification were never asked to verify, their email is taken as verified.
+-- The accounts created after it were sent a verification token, they keep verifying it
+UPDATE `user` u SET u.email_verified_at=u.created_at
+  WHERE u.email_verified_at IS NULL
+  AND NOT EXISTS (SELECT 1 FROM user_token t WHERE t.fk_user=u.id AND t.purpose='verify_email');
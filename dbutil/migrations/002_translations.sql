@@ -0,0 +1,16 @@
+-- the translations managed by the admins, by language code
+
+ALTER TABLE `language` ADD UNIQUE KEY `idx_name_iso2` (`name_iso2`);
+
+INSERT IGNORE INTO `language` (name,name_iso2,name_iso3) VALUES ('Portuguese','PT','POR');
+
+CREATE TABLE IF NOT EXISTS `translation` (
+  `id` int(11) unsigned NOT NULL AUTO_INCREMENT,
+  `fk_language` int(2) unsigned NOT NULL,
+  `key` varchar(150) NOT NULL,
+  `text` varchar(1000) NOT NULL,
+  `updated_at` datetime NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_language_key` (`fk_language`,`key`),
+  FOREIGN KEY (`fk_language`) REFERENCES language(`id`) ON UPDATE CASCADE ON DELETE RESTRICT
+) ENGINE=InnoDB AUTO_INCREMENT=0 DEFAULT CHARSET=utf8;
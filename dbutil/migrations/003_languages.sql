@@ -0,0 +1,12 @@
+-- the languages enabled by the admins and the ones spoken by the users
+
+ALTER TABLE `language` ADD COLUMN `active` tinyint(1) NOT NULL DEFAULT 1 AFTER `name_iso3`;
+
+CREATE TABLE IF NOT EXISTS `user_profile_language` (
+  `fk_user_profile` int(11) unsigned NOT NULL,
+  `fk_language` int(2) unsigned NOT NULL,
+  `proficiency` enum('basic','conversational','fluent','native') NOT NULL,
+  PRIMARY KEY (`fk_user_profile`,`fk_language`),
+  FOREIGN KEY (`fk_user_profile`) REFERENCES user_profile(`id`) ON UPDATE CASCADE ON DELETE CASCADE,
+  FOREIGN KEY (`fk_language`) REFERENCES language(`id`) ON UPDATE CASCADE ON DELETE RESTRICT
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
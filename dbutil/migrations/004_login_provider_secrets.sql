@@ -0,0 +1,4 @@
+-- the login provider secrets are stored encrypted, longer than in plain text. They are sealed on start
+
+ALTER TABLE `login_provider` MODIFY `web_secret` varchar(1024) NOT NULL, MODIFY `android_secret` varchar(1024) NOT NULL, MODIFY `iphone_secret` varchar(1024) NOT NULL;
+ALTER TABLE `login_provider` ADD COLUMN `secrets_rotated_at` datetime NULL DEFAULT NULL AFTER `iphone_secret`;
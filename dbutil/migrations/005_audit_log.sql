@@ -0,0 +1,19 @@
+-- append only, the entries outlive the users and events they are about. The erasure of an account only
+-- clears the ip and user_agent of its entries
+
+CREATE TABLE IF NOT EXISTS `audit_log` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `action` varchar(50) NOT NULL,
+  `fk_user` int(11) unsigned NULL DEFAULT NULL,
+  `fk_actor` int(11) unsigned NULL DEFAULT NULL,
+  `target` varchar(50) NULL DEFAULT NULL,
+  `target_id` int(11) unsigned NULL DEFAULT NULL,
+  `ip` varchar(45) NOT NULL,
+  `user_agent` varchar(255) NOT NULL,
+  `request_id` varchar(64) NOT NULL,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_user` (`fk_user`,`id`) USING BTREE,
+  KEY `idx_actor` (`fk_actor`,`id`) USING BTREE,
+  KEY `idx_action` (`action`,`id`) USING BTREE
+) ENGINE=InnoDB AUTO_INCREMENT=0 DEFAULT CHARSET=utf8;
@@ -0,0 +1,6 @@
+-- the accounts waiting for the erasure and the ones already erased
+
+ALTER TABLE `user` ADD COLUMN `deletion_at` datetime NULL DEFAULT NULL AFTER `email_verified_at`;
+ALTER TABLE `user` ADD COLUMN `deletion_events` enum('transfer','cancel') NULL DEFAULT NULL AFTER `deletion_at`;
+ALTER TABLE `user` ADD COLUMN `erased_at` datetime NULL DEFAULT NULL AFTER `deletion_events`;
+ALTER TABLE `user` ADD KEY `idx_deletion_at` (`deletion_at`) USING BTREE;
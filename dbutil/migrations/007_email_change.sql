@@ -0,0 +1,20 @@
+-- the new email of a user waits for its confirmation, the emails are unique
+
+ALTER TABLE `user` ADD COLUMN `pending_email` varchar(100) NULL DEFAULT NULL AFTER `email_verified_at`;
+
+-- the oldest account of a duplicated email keeps it. The others lose it with their sessions, and are audited
+-- to be followed up by the admins. Nothing is left to change when the migration is applied again
+UPDATE user_security s
+  INNER JOIN `user` u ON u.id=s.fk_user
+  INNER JOIN (SELECT email, MIN(id) AS keep_id FROM `user` GROUP BY email HAVING COUNT(*)>1) d ON u.email=d.email AND u.id<>d.keep_id
+  SET s.session_version=s.session_version+1, s.updated_at=now();
+
+INSERT INTO audit_log (action,fk_user,fk_actor,target,target_id,ip,user_agent,request_id)
+  SELECT 'email.duplicate', u.id, NULL, 'user', d.keep_id, '', '', 'migration-7' FROM `user` u
+  INNER JOIN (SELECT email, MIN(id) AS keep_id FROM `user` GROUP BY email HAVING COUNT(*)>1) d ON u.email=d.email AND u.id<>d.keep_id;
+
+UPDATE `user` u
+  INNER JOIN (SELECT email, MIN(id) AS keep_id FROM `user` GROUP BY email HAVING COUNT(*)>1) d ON u.email=d.email AND u.id<>d.keep_id
+  SET u.email=CONCAT('duplicate-',u.id,'@duplicate.invalid'), u.email_verified_at=NULL, u.pending_email=NULL, u.updated_at=now();
+
+ALTER TABLE `user` DROP KEY `idx_email`, ADD UNIQUE KEY `idx_email` (`email`) USING BTREE;
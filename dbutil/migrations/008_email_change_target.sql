@@ -0,0 +1,4 @@
+-- the email change tokens only confirm the address they were sent to. The tokens sent before have none,
+-- a new change has to be requested
+
+ALTER TABLE `user_token` ADD COLUMN `target` varchar(100) NULL DEFAULT NULL AFTER `purpose`;
@@ -0,0 +1,6 @@
+-- the jobs are claimed by an instance for a lease it renews while they run, only the jobs of an expired lease
+-- are given back. The running jobs claimed before have no lease, they are given back once
+
+ALTER TABLE `job` ADD COLUMN `owner` varchar(64) NULL DEFAULT NULL AFTER `status`;
+ALTER TABLE `job` ADD COLUMN `lease_until` datetime NULL DEFAULT NULL AFTER `owner`;
+ALTER TABLE `job` ADD KEY `idx_status_lease` (`status`,`lease_until`) USING BTREE;
@@ -1,3 +1,6 @@
+-- the schema of the first release. The service applies the changes since then on start, from the numbered
+-- files of dbutil/migrations, and records each version in the schema_migration table
+
 CREATE DATABASE popmeet;
 
 USE popmeet;
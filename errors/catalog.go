@@ -0,0 +1,129 @@
+package errors
+
+import "net/http"
+
+// Codes of the catalog, they are stable and documented to the clients
+const (
+	ErrorInterestNotFound    = 1001
+	ErrorInterestsNotFound   = 1002
+	ErrorUserNotFound        = 1003
+	ErrorUserProfileNotFound = 1004
+	ErrorCreatingToken       = 1005
+	ErrorEventNotFound       = 1006
+	ErrorCantAddUSerToEvent  = 1007
+	ErrorMessageNotAllowed   = 1008
+	ErrorUserBlocked         = 1009
+	ErrorReportTarget        = 1010
+	ErrorReportNotFound      = 1011
+	ErrorReportResolved      = 1012
+	ErrorEventFinished       = 1013
+	ErrorEmailNotVerified    = 1014
+	ErrorInvalidToken        = 1015
+	ErrorPasswordPolicy      = 1016
+	ErrorWrongPassword       = 1017
+	ErrorInvalidCode         = 1018
+	ErrorInternal            = 1019
+	ErrorBadRequest          = 1020
+	ErrorValidation          = 1021
+	ErrorUnauthorized        = 1022
+	ErrorInvalidCredentials  = 1023
+	ErrorForbidden           = 1024
+	ErrorNotFound            = 1025
+	ErrorMethodNotAllowed    = 1026
+	ErrorConflict            = 1027
+	ErrorTooManyRequests     = 1028
+	ErrorUnavailable         = 1029
+	ErrorTwoFactorEnabled    = 1030
+	ErrorTwoFactorDisabled   = 1031
+	ErrorEmailExists         = 1032
+	ErrorEmailVerified       = 1033
+	ErrorSelfAction          = 1034
+	ErrorLanguageNotFound    = 1035
+	ErrorTranslationKey      = 1036
+	ErrorLanguageDisabled    = 1037
+)
+
+var (
+	// generic errors, also returned by the repository
+	ErrInternal         = newError(ErrorInternal, http.StatusInternalServerError, "internal", "Internal server error")
+	ErrBadRequest       = newError(ErrorBadRequest, http.StatusBadRequest, "bad-request", "Bad request")
+	ErrValidationFailed = newError(ErrorValidation, http.StatusUnprocessableEntity, "validation", "Validation errors")
+	ErrUnauthorized     = newError(ErrorUnauthorized, http.StatusUnauthorized, "unauthorized", "Invalid token")
+	ErrForbidden        = newError(ErrorForbidden, http.StatusForbidden, "forbidden", "Not authorized to perform this action")
+	ErrNotFound         = newError(ErrorNotFound, http.StatusNotFound, "not-found", "Not found")
+	ErrMethodNotAllowed = newError(ErrorMethodNotAllowed, http.StatusMethodNotAllowed, "method-not-allowed", "Method not allowed")
+	ErrConflict         = newError(ErrorConflict, http.StatusConflict, "conflict", "Conflict")
+	ErrTooManyRequests  = newError(ErrorTooManyRequests, http.StatusTooManyRequests, "too-many-requests", "Too many requests, try again later")
+	ErrUnavailable      = newError(ErrorUnavailable, http.StatusServiceUnavailable, "unavailable", "Service unavailable")
+
+	// users
+	ErrUserNotFound        = newError(ErrorUserNotFound, http.StatusNotFound, "user-not-found", "User not found")
+	ErrUserProfileNotFound = newError(ErrorUserProfileNotFound, http.StatusNotFound, "user-profile-not-found", "User profile not found")
+	ErrUserBlocked         = newError(ErrorUserBlocked, http.StatusForbidden, "user-blocked", "User is blocked")
+	ErrSelfAction          = newError(ErrorSelfAction, http.StatusBadRequest, "self-action", "Action not allowed on yourself")
+	ErrEmailExists         = newError(ErrorEmailExists, http.StatusConflict, "email-exists", "Email already exists")
+	ErrEmailVerified       = newError(ErrorEmailVerified, http.StatusConflict, "email-verified", "Email already verified")
+	ErrEmailNotVerified    = newError(ErrorEmailNotVerified, http.StatusForbidden, "email-not-verified", "Email must be verified")
+
+	// authentication
+	ErrInvalidCredentials = newError(ErrorInvalidCredentials, http.StatusUnauthorized, "invalid-credentials", "Invalid credentials")
+	ErrCreatingToken      = newError(ErrorCreatingToken, http.StatusInternalServerError, "creating-token", "Error creating the token")
+	ErrInvalidToken       = newError(ErrorInvalidToken, http.StatusBadRequest, "invalid-token", "Invalid or expired token")
+	ErrPasswordPolicy     = newError(ErrorPasswordPolicy, http.StatusUnprocessableEntity, "password-policy", "Password does not follow the policy")
+	ErrWrongPassword      = newError(ErrorWrongPassword, http.StatusForbidden, "wrong-password", "Current password is not correct")
+	ErrInvalidCode        = newError(ErrorInvalidCode, http.StatusBadRequest, "invalid-code", "Invalid code")
+	ErrTwoFactorEnabled   = newError(ErrorTwoFactorEnabled, http.StatusConflict, "two-factor-enabled", "Two factor authentication already enabled")
+	ErrTwoFactorDisabled  = newError(ErrorTwoFactorDisabled, http.StatusBadRequest, "two-factor-disabled", "Two factor authentication not enabled")
+
+	// interests
+	ErrInterestNotFound  = newError(ErrorInterestNotFound, http.StatusNotFound, "interest-not-found", "Interest not found")
+	ErrInterestsNotFound = newError(ErrorInterestsNotFound, http.StatusNotFound, "interests-not-found", "Interests not found")
+
+	// languages
+	ErrLanguageNotFound = newError(ErrorLanguageNotFound, http.StatusNotFound, "language-not-found", "Language not found")
+	ErrTranslationKey   = newError(ErrorTranslationKey, http.StatusBadRequest, "translation-key", "Unknown translation key")
+	ErrLanguageDisabled = newError(ErrorLanguageDisabled, http.StatusBadRequest, "language-disabled", "Language is disabled")
+
+	// events
+	ErrEventNotFound      = newError(ErrorEventNotFound, http.StatusNotFound, "event-not-found", "Event not found")
+	ErrCantAddUserToEvent = newError(ErrorCantAddUSerToEvent, http.StatusBadRequest, "creator-joining-event", "Can't add creator as user")
+	ErrEventFinished      = newError(ErrorEventFinished, http.StatusBadRequest, "event-finished", "Event has already finished")
+
+	// messages
+	ErrMessageNotAllowed = newError(ErrorMessageNotAllowed, http.StatusForbidden, "message-not-allowed", "Not allowed to message this user")
+
+	// reports
+	ErrReportTarget   = newError(ErrorReportTarget, http.StatusBadRequest, "report-target", "Invalid report target")
+	ErrReportNotFound = newError(ErrorReportNotFound, http.StatusNotFound, "report-not-found", "Report not found")
+	ErrReportResolved = newError(ErrorReportResolved, http.StatusConflict, "report-resolved", "Report is already resolved")
+)
+
+// catalog All the errors by code
+var catalog = make(map[int]*AppError)
+
+// generic The errors that can become a specific one
+var generic = map[int]bool{ErrorNotFound: true, ErrorConflict: true, ErrorForbidden: true}
+
+func newError(code int, status int, slug string, title string) *AppError {
+	e := &AppError{Code: code, Status: status, Slug: slug, Title: title}
+	catalog[code] = e
+	return e
+}
+
+// Catalog Gets all the errors by code
+func Catalog() map[int]*AppError {
+	return catalog
+}
+
+// ForStatus Gets the generic error of a http status, the unknown ones by their class
+func ForStatus(status int) *AppError {
+	for _, e := range []*AppError{ErrBadRequest, ErrUnauthorized, ErrForbidden, ErrNotFound, ErrMethodNotAllowed, ErrConflict, ErrValidationFailed, ErrTooManyRequests, ErrUnavailable} {
+		if e.Status == status {
+			return e
+		}
+	}
+	if status >= http.StatusBadRequest && status < http.StatusInternalServerError {
+		return ErrBadRequest
+	}
+	return ErrInternal
+}
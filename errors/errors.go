@@ -1,27 +1,56 @@
 package errors
 
 import (
+	goerrors "errors"
 	"fmt"
+	"github.com/labstack/echo"
 	"gopkg.in/go-playground/validator.v9"
+	"net/http"
 )
 
-type ErrResponse struct {
-	Error ErrContent `json:"error"`
+const (
+	MIMEApplicationProblemJSON = "application/problem+json"
+	problemTypePrefix          = "urn:popmeet:problem:"
+	errorMessage               = "Field validation for %s failed on the '%s' tag"
+)
+
+// AppError is an error of the catalog, sent to the clients as a problem+json (RFC 7807).
+// The cause is only logged, it can have internal details like the SQL errors
+type AppError struct {
+	Code   int
+	Status int
+	Slug   string
+	Title  string
+	Detail string
+	Fields []*ErrValidation
+	cause  error
 }
 
-type ErrContent struct {
+// Problem is the body of the error responses
+type Problem struct {
+	Type      string           `json:"type"`
+	Title     string           `json:"title"`
+	Status    int              `json:"status"`
+	Detail    string           `json:"detail,omitempty"`
+	Instance  string           `json:"instance,omitempty"`
 	Code      int              `json:"code"`
-	Msg       string           `json:"message"`
 	ValErrors []*ErrValidation `json:"validation_errors,omitempty"`
+	RequestID string           `json:"request_id,omitempty"`
+	TraceID   string           `json:"trace_id,omitempty"`
 }
 
 type ErrValidation struct {
 	Field string `json:"field"`
 	Error string `json:"message"`
+	// Name, Tag and Param of the failed rule, to translate the message
+	Name  string `json:"-"`
+	Tag   string `json:"-"`
+	Param string `json:"-"`
 }
 
 type HealthStatus struct {
-	Repo *HealthStatusDetail `json:"repository"`
+	Status string                         `json:"status"`
+	Checks map[string]*HealthStatusDetail `json:"checks,omitempty"`
 }
 
 type HealthStatusDetail struct {
@@ -29,33 +58,102 @@ type HealthStatusDetail struct {
 	Detail string `json:"detail,omitempty"`
 }
 
-const (
-	ErrorInterestNotFound    = 1001
-	ErrorInterestsNotFound   = 1002
-	ErrorUserNotFound        = 1003
-	ErrorUserProfileNotFound = 1004
-	ErrorCreatingToken       = 1005
-	ErrorEventNotFound       = 1006
-	ErrorCantAddUSerToEvent  = 1007
-
-	ValidationError = "Validation Errors"
-	errorMessage    = "Field validation for %s failed on the '%s' tag"
-)
+func (e *AppError) Error() string {
+	msg := e.Title
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	if e.cause != nil {
+		msg += ": " + e.cause.Error()
+	}
+	return msg
+}
+
+func (e *AppError) Unwrap() error {
+	return e.cause
+}
+
+// Is Checks if the target is the same error of the catalog
+func (e *AppError) Is(target error) bool {
+	t, ok := target.(*AppError)
+	return ok && t.Code == e.Code
+}
+
+// WithDetail Copies the error with a detail for the client
+func (e *AppError) WithDetail(format string, a ...interface{}) *AppError {
+	cp := *e
+	cp.Detail = fmt.Sprintf(format, a...)
+	return &cp
+}
+
+// Wrap Copies the error with the cause, only logged
+func (e *AppError) Wrap(cause error) *AppError {
+	cp := *e
+	cp.cause = cause
+	return &cp
+}
 
-// Processes the Validation errors into a map
-func processValidationErrors(err error) []*ErrValidation {
-	var ers []*ErrValidation
-	for _, err := range err.(validator.ValidationErrors) {
-		ers = append(ers, &ErrValidation{Field: err.Namespace(), Error: fmt.Sprintf(errorMessage, err.Field(), err.Tag())})
+// Problem Creates the problem of the error, in english
+func (e *AppError) Problem() *Problem {
+	return &Problem{
+		Type:      problemTypePrefix + e.Slug,
+		Title:     e.Title,
+		Status:    e.Status,
+		Detail:    e.Detail,
+		Code:      e.Code,
+		ValErrors: e.Fields,
 	}
-	return ers
 }
 
-// Processes the Validation errors into a map
-func GeneralErrorJson(code int, err string) *ErrResponse {
-	return &ErrResponse{ErrContent{code, err, nil}}
+// From Gets the error of the catalog of any error, the errors outside of the catalog are internal.
+// The generic errors of the repository become the given specific error with the same status,
+// e.g. ErrNotFound becomes ErrUserNotFound
+func From(err error, specific ...*AppError) *AppError {
+
+	var ae *AppError
+	if goerrors.As(err, &ae) {
+		if generic[ae.Code] {
+			for _, s := range specific {
+				if s.Status == ae.Status {
+					cp := s.Wrap(err)
+					cp.Detail = ae.Detail
+					return cp
+				}
+			}
+		}
+		return ae
+	}
+
+	var he *echo.HTTPError
+	if goerrors.As(err, &he) {
+		ae = ForStatus(he.Code).Wrap(err)
+		if msg, ok := he.Message.(string); ok && he.Code < http.StatusInternalServerError {
+			ae.Detail = msg
+		}
+		return ae
+	}
+
+	return ErrInternal.Wrap(err)
 }
 
-func ValidationErrorJson(code int, err error) *ErrResponse {
-	return &ErrResponse{ErrContent{code, ValidationError, processValidationErrors(err)}}
+// Validation Gets the validation error with the failed fields
+func Validation(err error) *AppError {
+
+	ves, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return ErrBadRequest.Wrap(err)
+	}
+
+	e := ErrValidationFailed.Wrap(err)
+	for _, ve := range ves {
+		e.Fields = append(e.Fields, &ErrValidation{
+			Field: ve.Namespace(),
+			Error: fmt.Sprintf(errorMessage, ve.Field(), ve.Tag()),
+			Name:  ve.Field(),
+			Tag:   ve.Tag(),
+			Param: ve.Param(),
+		})
+	}
+
+	return e
 }
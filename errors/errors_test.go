@@ -0,0 +1,91 @@
+package errors
+
+import (
+	goerrors "errors"
+	"fmt"
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/go-playground/validator.v9"
+	"net/http"
+	"testing"
+)
+
+/*
+Provider struct for From method
+*/
+type providerFrom struct {
+	err      error
+	specific []*AppError
+	code     int
+	status   int
+	detail   string
+}
+
+var testProviderFrom = []providerFrom{
+	{ErrNotFound.WithDetail("User not found"), []*AppError{ErrUserNotFound}, ErrorUserNotFound, http.StatusNotFound, "User not found"},     // generic to specific
+	{fmt.Errorf("Error in select: %w", ErrNotFound), []*AppError{ErrEventNotFound}, ErrorEventNotFound, http.StatusNotFound, ""},           // wrapped generic
+	{ErrConflict, []*AppError{ErrUserNotFound}, ErrorConflict, http.StatusConflict, ""},                                                    // no specific with the status
+	{ErrEventFinished, []*AppError{ErrEventNotFound}, ErrorEventFinished, http.StatusBadRequest, ""},                                       // already specific
+	{echo.NewHTTPError(http.StatusNotFound, "Not Found"), nil, ErrorNotFound, http.StatusNotFound, "Not Found"},                            // echo error
+	{echo.NewHTTPError(http.StatusRequestEntityTooLarge, "Too large"), nil, ErrorBadRequest, http.StatusBadRequest, "Too large"},           // unknown client status
+	{echo.NewHTTPError(http.StatusBadGateway, "Bad gateway"), nil, ErrorInternal, http.StatusInternalServerError, ""},                      // server status without detail
+	{goerrors.New("Error in select: connection refused"), []*AppError{ErrUserNotFound}, ErrorInternal, http.StatusInternalServerError, ""}, // unknown error
+}
+
+/* Test for From method */
+func TestFrom(t *testing.T) {
+
+	for _, pair := range testProviderFrom {
+
+		ae := From(pair.err, pair.specific...)
+
+		// Assertions
+		assert.Equal(t, pair.code, ae.Code)
+		assert.Equal(t, pair.status, ae.Status)
+		assert.Equal(t, pair.detail, ae.Detail)
+		assert.Contains(t, ae.Error(), pair.err.Error())
+	}
+}
+
+/* Test for the errors of the catalog being copied */
+func TestWithDetail(t *testing.T) {
+
+	e := ErrUserNotFound.WithDetail("User %d", 1)
+
+	// Assertions
+	assert.Equal(t, "User 1", e.Detail)
+	assert.Equal(t, "", ErrUserNotFound.Detail)
+	assert.True(t, goerrors.Is(e, ErrUserNotFound))
+	assert.False(t, goerrors.Is(e, ErrNotFound))
+}
+
+/* Test for Validation method */
+func TestValidation(t *testing.T) {
+
+	v := validator.New()
+	err := v.Struct(&struct {
+		Email string `validate:"required,email"`
+	}{Email: "wrong"})
+
+	ae := Validation(err)
+
+	// Assertions
+	assert.Equal(t, ErrorValidation, ae.Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, ae.Status)
+	assert.Len(t, ae.Fields, 1)
+	assert.Equal(t, "email", ae.Fields[0].Tag)
+	assert.Equal(t, ErrorBadRequest, Validation(goerrors.New("unexpected EOF")).Code)
+}
+
+/* Test for Problem method */
+func TestProblem(t *testing.T) {
+
+	p := ErrUserNotFound.WithDetail("User 1").Problem()
+
+	// Assertions
+	assert.Equal(t, problemTypePrefix+"user-not-found", p.Type)
+	assert.Equal(t, ErrUserNotFound.Title, p.Title)
+	assert.Equal(t, "User 1", p.Detail)
+	assert.Equal(t, ErrorUserNotFound, p.Code)
+	assert.Equal(t, http.StatusNotFound, p.Status)
+}
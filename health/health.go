@@ -0,0 +1,144 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"github.com/labstack/echo"
+	er "github.com/pintobikez/popmeet/errors"
+	rep "github.com/pintobikez/popmeet/repository"
+	"github.com/pintobikez/popmeet/secure"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	StatusAvailable   = "Available"
+	StatusUnavailable = "Unavailable"
+	DefaultTimeout    = 2 * time.Second
+)
+
+// Check reports if a dependency can be used, returning the reason when it can not
+type Check func(ctx context.Context) error
+
+// Checker runs the checks of the dependencies needed to serve the requests
+type Checker struct {
+	timeout time.Duration
+	names   []string
+	checks  map[string]Check
+}
+
+// New Creates a Checker where each check has to finish before the timeout
+func New(timeout time.Duration) *Checker {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Checker{timeout: timeout, checks: make(map[string]Check)}
+}
+
+// Add Registers the check of a dependency
+func (h *Checker) Add(name string, ch Check) {
+	if _, ok := h.checks[name]; !ok {
+		h.names = append(h.names, name)
+	}
+	h.checks[name] = ch
+}
+
+// Run Runs all the checks at the same time, returning the report of each dependency and if all of them are available
+func (h *Checker) Run(ctx context.Context) (*er.HealthStatus, bool) {
+
+	resp := &er.HealthStatus{Status: StatusAvailable, Checks: make(map[string]*er.HealthStatusDetail, len(h.names))}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, name := range h.names {
+		wg.Add(1)
+		go func(name string, ch Check) {
+			defer wg.Done()
+
+			cctx, cancel := context.WithTimeout(ctx, h.timeout)
+			defer cancel()
+
+			det := &er.HealthStatusDetail{Status: StatusAvailable}
+			if err := run(cctx, ch); err != nil {
+				det.Status = StatusUnavailable
+				det.Detail = err.Error()
+			}
+
+			mu.Lock()
+			resp.Checks[name] = det
+			mu.Unlock()
+		}(name, h.checks[name])
+	}
+	wg.Wait()
+
+	for _, det := range resp.Checks {
+		if det.Status != StatusAvailable {
+			resp.Status = StatusUnavailable
+			return resp, false
+		}
+	}
+
+	return resp, true
+}
+
+// run Runs a check, giving up when the context is done even if the check ignores it
+func run(ctx context.Context, ch Check) error {
+	done := make(chan error, 1)
+	go func() { done <- ch(ctx) }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("Check timed out: %s", ctx.Err().Error())
+	}
+}
+
+// Live Handler for the liveness probe, the process is able to answer requests
+func (h *Checker) Live() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return c.JSON(http.StatusOK, &er.HealthStatus{Status: StatusAvailable})
+	}
+}
+
+// Ready Handler for the readiness probe, all the dependencies are available. Otherwise returns 503
+func (h *Checker) Ready() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		resp, ok := h.Run(c.Request().Context())
+		if !ok {
+			return c.JSON(http.StatusServiceUnavailable, resp)
+		}
+
+		return c.JSON(http.StatusOK, resp)
+	}
+}
+
+// Database Check for the connection to the database
+func Database(rp rep.Repository) Check {
+	return func(ctx context.Context) error {
+		return rp.Health(ctx)
+	}
+}
+
+// Schema Check for the migrations applied to the database, it has to be at least the expected version
+func Schema(rp rep.Repository, expected int) Check {
+	return func(ctx context.Context) error {
+		version, err := rp.SchemaVersion(ctx)
+		if err != nil {
+			return err
+		}
+		if version < expected {
+			return fmt.Errorf("Schema version %d, expected %d", version, expected)
+		}
+		return nil
+	}
+}
+
+// Security Check for the security configuration used by the tokens
+func Security(tm *secure.TokenManager) Check {
+	return func(ctx context.Context) error {
+		return tm.Health()
+	}
+}
@@ -0,0 +1,99 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"github.com/labstack/echo"
+	rep "github.com/pintobikez/popmeet/repository"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type fakeRepository struct {
+	rep.Repository
+	ping    error
+	version int
+}
+
+func (f *fakeRepository) Health(ctx context.Context) error {
+	return f.ping
+}
+
+func (f *fakeRepository) SchemaVersion(ctx context.Context) (int, error) {
+	return f.version, f.ping
+}
+
+/*
+Provider struct for Ready method
+*/
+type providerReady struct {
+	repo   *fakeRepository
+	code   int
+	status map[string]string
+}
+
+var testProviderReady = []providerReady{
+	{&fakeRepository{version: 2}, http.StatusOK, map[string]string{"database": StatusAvailable, "schema": StatusAvailable}},                                   // OK
+	{&fakeRepository{version: 1}, http.StatusServiceUnavailable, map[string]string{"database": StatusAvailable, "schema": StatusUnavailable}},                 // old schema
+	{&fakeRepository{ping: errors.New("down")}, http.StatusServiceUnavailable, map[string]string{"database": StatusUnavailable, "schema": StatusUnavailable}}, // database down
+}
+
+/* Test for Ready method */
+func TestReady(t *testing.T) {
+
+	for _, pair := range testProviderReady {
+
+		h := New(time.Second)
+		h.Add("database", Database(pair.repo))
+		h.Add("schema", Schema(pair.repo, 2))
+
+		e := echo.New()
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(echo.GET, "/health/ready", nil), rec)
+
+		resp, _ := h.Run(context.Background())
+		err := h.Ready()(c)
+
+		// Assertions
+		assert.Nil(t, err)
+		assert.Equal(t, pair.code, rec.Code)
+		for name, status := range pair.status {
+			assert.Equal(t, status, resp.Checks[name].Status)
+		}
+	}
+}
+
+/* Test for a check not answering before the timeout */
+func TestRunTimeout(t *testing.T) {
+
+	h := New(10 * time.Millisecond)
+	h.Add("slow", func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+
+	resp, ok := h.Run(context.Background())
+
+	// Assertions
+	assert.False(t, ok)
+	assert.Equal(t, StatusUnavailable, resp.Status)
+	assert.Equal(t, StatusUnavailable, resp.Checks["slow"].Status)
+}
+
+/* Test for Live method */
+func TestLive(t *testing.T) {
+
+	h := New(time.Second)
+	h.Add("database", Database(&fakeRepository{ping: errors.New("down")}))
+
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(echo.GET, "/health/live", nil), rec)
+
+	// Assertions
+	assert.Nil(t, h.Live()(c))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
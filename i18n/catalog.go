@@ -0,0 +1,195 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"github.com/pintobikez/popmeet/api/models"
+	er "github.com/pintobikez/popmeet/errors"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultLanguage is the language of the texts not translated
+const DefaultLanguage = "en"
+
+const (
+	errorPrefix       = "error."
+	validationPrefix  = "validation."
+	validationDefault = "validation.default"
+)
+
+// locales are the translations shipped with the service, one file by language named by its ISO 639-1 code
+//
+//go:embed locales/*.json
+var locales embed.FS
+
+// Catalog keeps the texts of the api by language and key.
+// The embedded translations are loaded first, the ones managed by the admins replace them
+type Catalog struct {
+	mu       sync.RWMutex
+	messages map[string]map[string]string
+}
+
+// New Creates a catalog with the embedded translations
+func New() (*Catalog, error) {
+
+	c := &Catalog{messages: make(map[string]map[string]string)}
+
+	files, err := locales.ReadDir("locales")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range files {
+		b, err := locales.ReadFile(path.Join("locales", f.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		var ms map[string]string
+		if err = json.Unmarshal(b, &ms); err != nil {
+			return nil, fmt.Errorf("Error reading translations %s: %s", f.Name(), err.Error())
+		}
+
+		lang := strings.TrimSuffix(f.Name(), path.Ext(f.Name()))
+		for k, v := range ms {
+			c.Set(lang, k, v)
+		}
+	}
+
+	return c, nil
+}
+
+// Set Sets the text of a key in a language
+func (c *Catalog) Set(lang string, key string, text string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	lang = strings.ToLower(lang)
+	if c.messages[lang] == nil {
+		c.messages[lang] = make(map[string]string)
+	}
+	c.messages[lang][key] = text
+}
+
+// Load Sets the translations managed by the admins
+func (c *Catalog) Load(ts []*models.Translation) {
+	for _, t := range ts {
+		c.Set(t.Language, t.Key, t.Text)
+	}
+}
+
+// Has Checks if there are translations for the language
+func (c *Catalog) Has(lang string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	_, ok := c.messages[strings.ToLower(lang)]
+	return ok
+}
+
+// Known Checks if the key is a text of the api, the ones of the default language and the titles of the errors
+func (c *Catalog) Known(key string) bool {
+
+	if strings.HasPrefix(key, errorPrefix) {
+		for _, e := range er.Catalog() {
+			if errorPrefix+e.Slug == key {
+				return true
+			}
+		}
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	_, ok := c.messages[DefaultLanguage][key]
+	return ok
+}
+
+// Translations Gets the translations of a language sorted by key
+func (c *Catalog) Translations(lang string) []*models.Translation {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	lang = strings.ToLower(lang)
+	resp := make([]*models.Translation, 0, len(c.messages[lang]))
+	for k, v := range c.messages[lang] {
+		resp = append(resp, &models.Translation{Language: lang, Key: k, Text: v})
+	}
+	sort.Slice(resp, func(i, j int) bool { return resp[i].Key < resp[j].Key })
+
+	return resp
+}
+
+// lookup Gets the text of the key in the language, otherwise in the default language
+func (c *Catalog) lookup(lang string, key string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if t, ok := c.messages[strings.ToLower(lang)][key]; ok {
+		return t, true
+	}
+	t, ok := c.messages[DefaultLanguage][key]
+	return t, ok
+}
+
+// Translate Gets the text of the key in the language with the {name} placeholders replaced by the args.
+// The key is returned when it has no text
+func (c *Catalog) Translate(lang string, key string, args map[string]string) string {
+
+	t, ok := c.lookup(lang, key)
+	if !ok {
+		return key
+	}
+
+	if len(args) == 0 {
+		return t
+	}
+
+	pairs := make([]string, 0, len(args)*2)
+	for k, v := range args {
+		pairs = append(pairs, "{"+k+"}", v)
+	}
+
+	return strings.NewReplacer(pairs...).Replace(t)
+}
+
+// Match Gets the first language of the Accept-Language header with translations, the default language otherwise
+func (c *Catalog) Match(accept string) string {
+	for _, part := range strings.Split(accept, ",") {
+		tag := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		lang := strings.ToLower(strings.SplitN(tag, "-", 2)[0])
+		if lang != "" && c.Has(lang) {
+			return lang
+		}
+	}
+	return DefaultLanguage
+}
+
+// Problem Creates the problem of the error with the title and the validation messages in the language
+func (c *Catalog) Problem(e *er.AppError, lang string) *er.Problem {
+
+	p := e.Problem()
+	if t, ok := c.lookup(lang, errorPrefix+e.Slug); ok {
+		p.Title = t
+	}
+
+	if len(e.Fields) == 0 {
+		return p
+	}
+
+	p.ValErrors = make([]*er.ErrValidation, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		key := validationPrefix + f.Tag
+		if _, ok := c.lookup(lang, key); !ok {
+			key = validationDefault
+		}
+		msg := c.Translate(lang, key, map[string]string{"field": f.Name, "tag": f.Tag, "param": f.Param})
+		p.ValErrors = append(p.ValErrors, &er.ErrValidation{Field: f.Field, Error: msg, Name: f.Name, Tag: f.Tag, Param: f.Param})
+	}
+
+	return p
+}
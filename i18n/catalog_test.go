@@ -0,0 +1,163 @@
+package i18n
+
+import (
+	"errors"
+	"github.com/labstack/echo"
+	"github.com/pintobikez/popmeet/api/models"
+	er "github.com/pintobikez/popmeet/errors"
+	stru "github.com/pintobikez/popmeet/secure/structures"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/go-playground/validator.v9"
+	"net/http/httptest"
+	"testing"
+)
+
+/*
+Fake store of the profile languages
+*/
+type fakeLanguageStore struct {
+	lang string
+	err  error
+}
+
+func (f *fakeLanguageStore) GetUserLanguage(userId int64) (string, error) {
+	return f.lang, f.err
+}
+
+/*
+Provider struct for Translate method
+*/
+type providerTranslate struct {
+	lang string
+	key  string
+	args map[string]string
+	text string
+}
+
+var testProviderTranslate = []providerTranslate{
+	{"en", "notification.event_updated.title", map[string]string{"location": "Porto"}, "The event at Porto was updated"}, // english
+	{"pt", "notification.event_updated.title", map[string]string{"location": "Porto"}, "O evento em Porto foi alterado"}, // translated
+	{"PT", "notification.event_updated.title", map[string]string{"location": "Porto"}, "O evento em Porto foi alterado"}, // upper case
+	{"de", "notification.event_updated.title", map[string]string{"location": "Porto"}, "The event at Porto was updated"}, // not translated
+	{"pt", "notification.unknown.title", nil, "notification.unknown.title"},                                              // unknown key
+}
+
+/* Test for Translate method */
+func TestTranslate(t *testing.T) {
+
+	c, err := New()
+	assert.Nil(t, err)
+
+	for _, pair := range testProviderTranslate {
+		// Assertions
+		assert.Equal(t, pair.text, c.Translate(pair.lang, pair.key, pair.args))
+	}
+}
+
+/* Test for the translations managed by the admins */
+func TestLoad(t *testing.T) {
+
+	c, err := New()
+	assert.Nil(t, err)
+
+	c.Load([]*models.Translation{
+		{Language: "PT", Key: "email.verify.subject", Text: "Confirme o email"},
+		{Language: "es", Key: "email.verify.subject", Text: "Confirme su email"},
+	})
+
+	// Assertions
+	assert.Equal(t, "Confirme o email", c.Translate("pt", "email.verify.subject", nil))
+	assert.Equal(t, "Confirme su email", c.Translate("es", "email.verify.subject", nil))
+	assert.Equal(t, "Reset your password", c.Translate("es", "email.reset.subject", nil))
+	assert.True(t, c.Has("es"))
+	assert.Equal(t, "es", c.Match("es-ES"))
+}
+
+/*
+Provider struct for Match method
+*/
+type providerMatch struct {
+	accept string
+	lang   string
+}
+
+var testProviderMatch = []providerMatch{
+	{"", DefaultLanguage},             // no header
+	{"pt-PT,pt;q=0.9,en;q=0.8", "pt"}, // region
+	{"de-DE,en;q=0.8", "en"},          // first not translated
+	{"fr", DefaultLanguage},           // not translated
+}
+
+/* Test for Match method */
+func TestMatch(t *testing.T) {
+
+	c, err := New()
+	assert.Nil(t, err)
+
+	for _, pair := range testProviderMatch {
+		// Assertions
+		assert.Equal(t, pair.lang, c.Match(pair.accept))
+	}
+}
+
+/*
+Provider struct for Locale method
+*/
+type providerLocale struct {
+	claims bool
+	store  *fakeLanguageStore
+	accept string
+	lang   string
+}
+
+var testProviderLocale = []providerLocale{
+	{true, &fakeLanguageStore{lang: "pt"}, "en", "pt"},              // profile language
+	{true, &fakeLanguageStore{lang: "de"}, "pt", "pt"},              // profile language not translated
+	{true, &fakeLanguageStore{err: errors.New("down")}, "pt", "pt"}, // profile not available
+	{false, &fakeLanguageStore{lang: "pt"}, "en-US", "en"},          // not logged
+	{false, &fakeLanguageStore{}, "", DefaultLanguage},              // nothing
+}
+
+/* Test for Locale method */
+func TestLocale(t *testing.T) {
+
+	c, err := New()
+	assert.Nil(t, err)
+
+	for _, pair := range testProviderLocale {
+
+		req := httptest.NewRequest(echo.GET, "/", nil)
+		req.Header.Set("Accept-Language", pair.accept)
+		ctx := echo.New().NewContext(req, httptest.NewRecorder())
+		if pair.claims {
+			ctx.Set("claims", &stru.TokenClaims{ID: 1})
+		}
+
+		// Assertions
+		assert.Equal(t, pair.lang, c.Locale(ctx, pair.store))
+	}
+}
+
+/* Test for Problem method */
+func TestProblem(t *testing.T) {
+
+	c, err := New()
+	assert.Nil(t, err)
+
+	v := validator.New()
+	verr := v.Struct(&struct {
+		Email string `validate:"required,email"`
+	}{})
+
+	p := c.Problem(er.Validation(verr), "pt")
+
+	// Assertions
+	assert.Equal(t, "Erros de validação", p.Title)
+	assert.Len(t, p.ValErrors, 1)
+	assert.Equal(t, "Email é obrigatório", p.ValErrors[0].Error)
+	assert.Equal(t, er.ErrUserNotFound.Title, c.Problem(er.ErrUserNotFound, "en").Title)
+	assert.Equal(t, "Utilizador não encontrado", c.Problem(er.ErrUserNotFound, "pt").Title)
+	assert.True(t, c.Known("error.user-not-found"))
+	assert.True(t, c.Known("validation.required"))
+	assert.False(t, c.Known("error.unknown"))
+}
@@ -0,0 +1,74 @@
+package i18n
+
+import (
+	"gopkg.in/go-playground/validator.v9"
+	"strings"
+)
+
+// iso639 The ISO 639-2 codes of each ISO 639-1 code, the terminology code first and the bibliographic one when different
+var iso639 = map[string][]string{
+	"aa": {"aar"}, "ab": {"abk"}, "ae": {"ave"}, "af": {"afr"}, "ak": {"aka"}, "am": {"amh"}, "an": {"arg"},
+	"ar": {"ara"}, "as": {"asm"}, "av": {"ava"}, "ay": {"aym"}, "az": {"aze"}, "ba": {"bak"}, "be": {"bel"},
+	"bg": {"bul"}, "bh": {"bih"}, "bi": {"bis"}, "bm": {"bam"}, "bn": {"ben"}, "bo": {"bod", "tib"}, "br": {"bre"},
+	"bs": {"bos"}, "ca": {"cat"}, "ce": {"che"}, "ch": {"cha"}, "co": {"cos"}, "cr": {"cre"}, "cs": {"ces", "cze"},
+	"cu": {"chu"}, "cv": {"chv"}, "cy": {"cym", "wel"}, "da": {"dan"}, "de": {"deu", "ger"}, "dv": {"div"}, "dz": {"dzo"},
+	"ee": {"ewe"}, "el": {"ell", "gre"}, "en": {"eng"}, "eo": {"epo"}, "es": {"spa"}, "et": {"est"}, "eu": {"eus", "baq"},
+	"fa": {"fas", "per"}, "ff": {"ful"}, "fi": {"fin"}, "fj": {"fij"}, "fo": {"fao"}, "fr": {"fra", "fre"}, "fy": {"fry"},
+	"ga": {"gle"}, "gd": {"gla"}, "gl": {"glg"}, "gn": {"grn"}, "gu": {"guj"}, "gv": {"glv"}, "ha": {"hau"},
+	"he": {"heb"}, "hi": {"hin"}, "ho": {"hmo"}, "hr": {"hrv"}, "ht": {"hat"}, "hu": {"hun"}, "hy": {"hye", "arm"},
+	"hz": {"her"}, "ia": {"ina"}, "id": {"ind"}, "ie": {"ile"}, "ig": {"ibo"}, "ii": {"iii"}, "ik": {"ipk"},
+	"io": {"ido"}, "is": {"isl", "ice"}, "it": {"ita"}, "iu": {"iku"}, "ja": {"jpn"}, "jv": {"jav"}, "ka": {"kat", "geo"},
+	"kg": {"kon"}, "ki": {"kik"}, "kj": {"kua"}, "kk": {"kaz"}, "kl": {"kal"}, "km": {"khm"}, "kn": {"kan"},
+	"ko": {"kor"}, "kr": {"kau"}, "ks": {"kas"}, "ku": {"kur"}, "kv": {"kom"}, "kw": {"cor"}, "ky": {"kir"},
+	"la": {"lat"}, "lb": {"ltz"}, "lg": {"lug"}, "li": {"lim"}, "ln": {"lin"}, "lo": {"lao"}, "lt": {"lit"},
+	"lu": {"lub"}, "lv": {"lav"}, "mg": {"mlg"}, "mh": {"mah"}, "mi": {"mri", "mao"}, "mk": {"mkd", "mac"}, "ml": {"mal"},
+	"mn": {"mon"}, "mr": {"mar"}, "ms": {"msa", "may"}, "mt": {"mlt"}, "my": {"mya", "bur"}, "na": {"nau"}, "nb": {"nob"},
+	"nd": {"nde"}, "ne": {"nep"}, "ng": {"ndo"}, "nl": {"nld", "dut"}, "nn": {"nno"}, "no": {"nor"}, "nr": {"nbl"},
+	"nv": {"nav"}, "ny": {"nya"}, "oc": {"oci"}, "oj": {"oji"}, "om": {"orm"}, "or": {"ori"}, "os": {"oss"},
+	"pa": {"pan"}, "pi": {"pli"}, "pl": {"pol"}, "ps": {"pus"}, "pt": {"por"}, "qu": {"que"}, "rm": {"roh"},
+	"rn": {"run"}, "ro": {"ron", "rum"}, "ru": {"rus"}, "rw": {"kin"}, "sa": {"san"}, "sc": {"srd"}, "sd": {"snd"},
+	"se": {"sme"}, "sg": {"sag"}, "si": {"sin"}, "sk": {"slk", "slo"}, "sl": {"slv"}, "sm": {"smo"}, "sn": {"sna"},
+	"so": {"som"}, "sq": {"sqi", "alb"}, "sr": {"srp"}, "ss": {"ssw"}, "st": {"sot"}, "su": {"sun"}, "sv": {"swe"},
+	"sw": {"swa"}, "ta": {"tam"}, "te": {"tel"}, "tg": {"tgk"}, "th": {"tha"}, "ti": {"tir"}, "tk": {"tuk"},
+	"tl": {"tgl"}, "tn": {"tsn"}, "to": {"ton"}, "tr": {"tur"}, "ts": {"tso"}, "tt": {"tat"}, "tw": {"twi"},
+	"ty": {"tah"}, "ug": {"uig"}, "uk": {"ukr"}, "ur": {"urd"}, "uz": {"uzb"}, "ve": {"ven"}, "vi": {"vie"},
+	"vo": {"vol"}, "wa": {"wln"}, "wo": {"wol"}, "xh": {"xho"}, "yi": {"yid"}, "yo": {"yor"}, "za": {"zha"},
+	"zh": {"zho", "chi"}, "zu": {"zul"},
+}
+
+// IsISO6391 Checks if the code is an ISO 639-1 language code, in any case
+func IsISO6391(code string) bool {
+	_, ok := iso639[strings.ToLower(code)]
+	return ok
+}
+
+// IsISO6392 Checks if the code is the ISO 639-2 code of an ISO 639-1 language, in any case
+func IsISO6392(code string) bool {
+	code = strings.ToLower(code)
+	for _, cs := range iso639 {
+		for _, c := range cs {
+			if c == code {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// MatchISO639 Checks if the ISO 639-2 code is of the same language as the ISO 639-1 code
+func MatchISO639(iso2 string, iso3 string) bool {
+	for _, c := range iso639[strings.ToLower(iso2)] {
+		if c == strings.ToLower(iso3) {
+			return true
+		}
+	}
+	return false
+}
+
+// RegisterValidation Registers the iso639_1 and iso639_2 validation tags
+func RegisterValidation(v *validator.Validate) error {
+	if err := v.RegisterValidation("iso639_1", func(fl validator.FieldLevel) bool { return IsISO6391(fl.Field().String()) }); err != nil {
+		return err
+	}
+	return v.RegisterValidation("iso639_2", func(fl validator.FieldLevel) bool { return IsISO6392(fl.Field().String()) })
+}
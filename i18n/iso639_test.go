@@ -0,0 +1,45 @@
+package i18n
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/go-playground/validator.v9"
+	"testing"
+)
+
+/*
+Provider struct for the ISO 639 validation
+*/
+type providerISO639 struct {
+	iso2   string
+	iso3   string
+	iserro bool
+	match  bool
+}
+
+var testProviderISO639 = []providerISO639{
+	{"pt", "por", false, true},  // portuguese
+	{"DE", "GER", false, true},  // bibliographic code in upper case
+	{"de", "deu", false, true},  // terminology code
+	{"pt", "eng", false, false}, // codes of different languages
+	{"xx", "eng", true, false},  // unknown ISO 639-1 code
+	{"en", "xxx", true, false},  // unknown ISO 639-2 code
+}
+
+/* Test for RegisterValidation and MatchISO639 methods */
+func TestISO639(t *testing.T) {
+
+	v := validator.New()
+	assert.Nil(t, RegisterValidation(v))
+
+	for _, pair := range testProviderISO639 {
+
+		err := v.Struct(&struct {
+			Iso2 string `validate:"iso639_1"`
+			Iso3 string `validate:"iso639_2"`
+		}{pair.iso2, pair.iso3})
+
+		// Assertions
+		assert.Equal(t, pair.iserro, (err != nil))
+		assert.Equal(t, pair.match, MatchISO639(pair.iso2, pair.iso3))
+	}
+}
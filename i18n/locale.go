@@ -0,0 +1,33 @@
+package i18n
+
+import (
+	"github.com/labstack/echo"
+	stru "github.com/pintobikez/popmeet/secure/structures"
+)
+
+// LanguageStore Gets the profile language of the users
+type LanguageStore interface {
+	GetUserLanguage(userId int64) (string, error)
+}
+
+// Language Gets the language of a user, the default language when it has no translations
+func (c *Catalog) Language(ls LanguageStore, userId int64) string {
+	lang, err := ls.GetUserLanguage(userId)
+	if err != nil || !c.Has(lang) {
+		return DefaultLanguage
+	}
+	return lang
+}
+
+// Locale Gets the language of the request: the profile language of the logged user,
+// otherwise the Accept-Language header
+func (c *Catalog) Locale(ctx echo.Context, ls LanguageStore) string {
+
+	if cl, ok := ctx.Get("claims").(*stru.TokenClaims); ok {
+		if lang, err := ls.GetUserLanguage(cl.ID); err == nil && c.Has(lang) {
+			return lang
+		}
+	}
+
+	return c.Match(ctx.Request().Header.Get("Accept-Language"))
+}
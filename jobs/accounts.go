@@ -0,0 +1,78 @@
+package jobs
+
+import (
+	"errors"
+	"github.com/pintobikez/popmeet/api/models"
+	serror "github.com/pintobikez/popmeet/errors"
+	"github.com/pintobikez/popmeet/notification"
+	repo "github.com/pintobikez/popmeet/repository"
+	"strconv"
+	"time"
+)
+
+const erasureBatchSize = 50
+
+// RegisterAccountErasure erases the accounts whose grace period is over. The upcoming events they
+// host are transferred to one of their attendees or cancelled, as chosen by the user
+func RegisterAccountErasure(r *Runner, rpo repo.Repository, n notification.Notifier) {
+
+	r.Every(func(now time.Time) error {
+		us, err := rpo.GetUsersToErase(now, erasureBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, u := range us {
+			if err = eraseAccount(rpo, n, u, now); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// eraseAccount erases the data of the user, handing over or cancelling its upcoming events, and notifies the
+// attendees of the events. A login since the account was read keeps it with all its events
+func eraseAccount(rpo repo.Repository, n notification.Notifier, u *models.AccountErasure, now time.Time) error {
+
+	evs, err := rpo.EraseUser(u.UserID, u.HostedEvents, now)
+	if errors.Is(err, serror.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err = rpo.InsertAuditEntry(&models.AuditEntry{Action: models.AuditAccountErase, UserID: u.UserID, CreatedAt: now}); err != nil {
+		return err
+	}
+
+	var ns []*models.Notification
+	for _, ev := range evs {
+		if ev.NewHost > 0 {
+			ns = append(ns, eventNotification(ev.Event, ev.NewHost, notification.TypeEventTransferred))
+			continue
+		}
+		for _, a := range ev.Attendees {
+			ns = append(ns, eventNotification(ev.Event, a, notification.TypeEventCancelled))
+		}
+	}
+
+	if len(ns) == 0 {
+		return nil
+	}
+
+	return n.Notify(ns)
+}
+
+// eventNotification builds a notification of the given type about an event, its text is the translation of the type
+func eventNotification(ev *models.Event, idUser int64, tp string) *models.Notification {
+	return &models.Notification{
+		UserID: idUser,
+		Type:   tp,
+		Key:    tp,
+		Args:   map[string]string{"location": ev.Location, "date": ev.StartDate.Format(time.RFC1123)},
+		Data:   map[string]string{"event_id": strconv.FormatInt(ev.ID, 10)},
+	}
+}
@@ -0,0 +1,94 @@
+package jobs
+
+import (
+	"github.com/pintobikez/popmeet/api/models"
+	serror "github.com/pintobikez/popmeet/errors"
+	"github.com/pintobikez/popmeet/notification"
+	repo "github.com/pintobikez/popmeet/repository"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+/*
+Fake repository handing over the events hosted by the erased user
+*/
+type fakeErasureRepository struct {
+	repo.Repository
+	events  []*models.HandedOverEvent
+	erased  []int64
+	audited []string
+	kept    bool
+}
+
+func (f *fakeErasureRepository) EraseUser(id int64, hostedEvents string, now time.Time) ([]*models.HandedOverEvent, error) {
+	if f.kept {
+		return nil, serror.ErrNotFound
+	}
+	f.erased = append(f.erased, id)
+	return f.events, nil
+}
+
+func (f *fakeErasureRepository) InsertAuditEntry(e *models.AuditEntry) error {
+	f.audited = append(f.audited, e.Action)
+	return nil
+}
+
+/*
+Fake notifier keeping the notifications sent
+*/
+type fakeNotifier struct {
+	sent []*models.Notification
+}
+
+func (f *fakeNotifier) Notify(ns []*models.Notification) error {
+	f.sent = append(f.sent, ns...)
+	return nil
+}
+
+/*
+Provider struct for eraseAccount method
+*/
+type providerEraseAccount struct {
+	events   []*models.HandedOverEvent
+	kept     bool
+	notified map[int64]string
+	erased   []int64
+	audited  []string
+}
+
+var testProviderEraseAccount = []providerEraseAccount{
+	// the new host of a transferred event is notified, the attendees of a cancelled one too
+	{[]*models.HandedOverEvent{{Event: &models.Event{ID: 1}, NewHost: 20, Attendees: []int64{20, 21}}, {Event: &models.Event{ID: 2}, Attendees: []int64{22}}},
+		false, map[int64]string{20: notification.TypeEventTransferred, 22: notification.TypeEventCancelled}, []int64{10}, []string{models.AuditAccountErase}},
+	// all the upcoming events are cancelled, their attendees notified
+	{[]*models.HandedOverEvent{{Event: &models.Event{ID: 1}, Attendees: []int64{20, 21}}, {Event: &models.Event{ID: 2}}},
+		false, map[int64]string{20: notification.TypeEventCancelled, 21: notification.TypeEventCancelled}, []int64{10}, []string{models.AuditAccountErase}},
+	// a login kept the account meanwhile, its events are untouched and it is not erased nor audited
+	{nil, true, map[int64]string{}, nil, nil},
+}
+
+/* Test for eraseAccount method */
+func TestEraseAccount(t *testing.T) {
+
+	now := time.Date(2018, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	for _, pair := range testProviderEraseAccount {
+
+		rp := &fakeErasureRepository{events: pair.events, kept: pair.kept}
+		n := &fakeNotifier{}
+
+		err := eraseAccount(rp, n, &models.AccountErasure{UserID: 10, HostedEvents: models.HostedEventsTransfer}, now)
+
+		notified := make(map[int64]string)
+		for _, ns := range n.sent {
+			notified[ns.UserID] = ns.Type
+		}
+
+		// Assertions
+		assert.Nil(t, err)
+		assert.Equal(t, pair.notified, notified)
+		assert.Equal(t, pair.erased, rp.erased)
+		assert.Equal(t, pair.audited, rp.audited)
+	}
+}
@@ -0,0 +1,41 @@
+package jobs
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock gives the current time to the runner
+type Clock interface {
+	Now() time.Time
+}
+
+// RealClock uses the system time
+type RealClock struct{}
+
+func (RealClock) Now() time.Time {
+	return time.Now().UTC()
+}
+
+// FakeClock is a manually driven clock, to be used in tests
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by the given duration
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
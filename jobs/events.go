@@ -0,0 +1,27 @@
+package jobs
+
+import (
+	repo "github.com/pintobikez/popmeet/repository"
+	"time"
+)
+
+const archiveBatchSize = 500
+
+// RegisterEventLifecycle marks the finished events as completed and moves the
+// events completed more than archiveAfter ago into the archive tables
+func RegisterEventLifecycle(r *Runner, rpo repo.Repository, archiveAfter time.Duration) {
+
+	r.Every(func(now time.Time) error {
+		_, err := rpo.CompletePastEvents(now)
+		return err
+	})
+
+	if archiveAfter <= 0 {
+		return
+	}
+
+	r.Every(func(now time.Time) error {
+		_, err := rpo.ArchiveEvents(now.Add(-archiveAfter), archiveBatchSize)
+		return err
+	})
+}
@@ -0,0 +1,68 @@
+package jobs
+
+import (
+	"github.com/pintobikez/popmeet/api/models"
+	"github.com/pintobikez/popmeet/notification"
+	repo "github.com/pintobikez/popmeet/repository"
+	"strconv"
+	"time"
+)
+
+const (
+	TypeEventReminder24h = "event_reminder_24h"
+	TypeEventReminder1h  = "event_reminder_1h"
+
+	// reminderWindow is how far around now the reminders are scheduled, so a
+	// restart shorter than it doesn't lose any
+	reminderWindow = time.Hour
+)
+
+// RegisterEventReminders schedules and sends the reminders 24h and 1h before the start of each event
+func RegisterEventReminders(r *Runner, rpo repo.Repository, n notification.Notifier) {
+
+	reminders := map[string]time.Duration{
+		TypeEventReminder24h: 24 * time.Hour,
+		TypeEventReminder1h:  time.Hour,
+	}
+
+	for tp, before := range reminders {
+		tp, before := tp, before
+
+		r.Every(func(now time.Time) error {
+			return rpo.ScheduleEventReminders(tp, before, now, reminderWindow)
+		})
+
+		r.Handle(tp, func(j *models.Job) error {
+			return sendEventReminder(rpo, n, j, tp)
+		})
+	}
+}
+
+// sendEventReminder notifies the creator and all the users of the event in the job, the
+// text of the notification is the translation of the job type
+func sendEventReminder(rpo repo.Repository, n notification.Notifier, j *models.Job, tp string) error {
+
+	ev, err := rpo.GetEventById(j.RefID)
+	if err != nil {
+		return err
+	}
+	// cancelled events don't get reminders
+	if !ev.Active {
+		return nil
+	}
+
+	users := append([]*models.User{ev.CreatedBy}, ev.Users...)
+	ns := make([]*models.Notification, 0, len(users))
+
+	for _, u := range users {
+		ns = append(ns, &models.Notification{
+			UserID: u.ID,
+			Type:   notification.TypeEventReminder,
+			Key:    tp,
+			Args:   map[string]string{"location": ev.Location, "date": ev.StartDate.Format(time.RFC1123)},
+			Data:   map[string]string{"event_id": strconv.FormatInt(ev.ID, 10)},
+		})
+	}
+
+	return n.Notify(ns)
+}
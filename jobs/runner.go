@@ -0,0 +1,218 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"github.com/labstack/echo"
+	"github.com/pintobikez/popmeet/api/models"
+	repo "github.com/pintobikez/popmeet/repository"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	batchSize    = 50
+	maxAttempts  = 3
+	retryBackoff = 5 * time.Minute
+	// jobLease is how long a claimed job belongs to the instance, renewed while it runs
+	jobLease = 5 * time.Minute
+)
+
+// Handler runs a job of a given type
+type Handler func(j *models.Job) error
+
+// Task runs periodically before the due jobs are processed, usually to schedule new jobs
+type Task func(now time.Time) error
+
+// Runner processes the persisted jobs in the background
+type Runner struct {
+	rp       repo.Repository
+	clock    Clock
+	owner    string
+	lease    time.Duration
+	interval time.Duration
+	logger   echo.Logger
+	handlers map[string]Handler
+	tasks    []Task
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+func NewRunner(rpo repo.Repository, clock Clock, interval time.Duration, logger echo.Logger) *Runner {
+	return &Runner{
+		rp:       rpo,
+		clock:    clock,
+		owner:    newOwner(),
+		lease:    jobLease,
+		interval: interval,
+		logger:   logger,
+		handlers: make(map[string]Handler),
+	}
+}
+
+// Handle registers the handler of a job type
+func (r *Runner) Handle(tp string, h Handler) {
+	r.handlers[tp] = h
+}
+
+// Every registers a task to run on each tick of the runner
+func (r *Runner) Every(t Task) {
+	r.tasks = append(r.tasks, t)
+}
+
+// newOwner Identifies the instance claiming the jobs, unique among the instances sharing the database
+func newOwner() string {
+	host, _ := os.Hostname()
+	if len(host) > 32 {
+		host = host[:32]
+	}
+	return fmt.Sprintf("%s-%d-%d", host, os.Getpid(), time.Now().UnixNano())
+}
+
+// Start starts processing in a new go routine
+func (r *Runner) Start() error {
+
+	r.stop = make(chan struct{})
+	r.wg.Add(1)
+
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		for {
+			r.RunOnce()
+
+			select {
+			case <-r.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop waits for the current tick to finish or for the context to be done
+func (r *Runner) Stop(ctx context.Context) error {
+
+	if r.stop == nil {
+		return nil
+	}
+	close(r.stop)
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RunOnce gives back the jobs of the expired leases, runs the periodic tasks and then every job due at the current time
+func (r *Runner) RunOnce() {
+
+	now := r.clock.Now()
+
+	// the jobs of the instances that stopped while running them, the jobs of the running instances keep their lease
+	if n, err := r.rp.ResetExpiredJobs(now); err != nil {
+		r.logger.Errorf("Error resetting the expired jobs: %s", err.Error())
+	} else if n > 0 {
+		r.logger.Warnf("Reset %d jobs of an expired lease", n)
+	}
+
+	for _, t := range r.tasks {
+		if err := t(now); err != nil {
+			r.logger.Errorf("Error running periodic task: %s", err.Error())
+		}
+	}
+
+	for {
+		js, err := r.rp.ClaimDueJobs(r.owner, now, now.Add(r.lease), batchSize)
+		if err != nil {
+			r.logger.Errorf("Error claiming jobs: %s", err.Error())
+			return
+		}
+
+		for _, j := range js {
+			r.run(j)
+		}
+
+		if len(js) < batchSize {
+			return
+		}
+	}
+}
+
+// run runs a claimed job and records its outcome
+func (r *Runner) run(j *models.Job) {
+
+	// the jobs later in the batch may have waited past their lease and been given to another instance
+	owned, err := r.rp.RenewJobLease(j.ID, r.owner, r.clock.Now().Add(r.lease))
+	if err != nil {
+		r.logger.Errorf(err.Error())
+		return
+	}
+	if !owned {
+		r.logger.Warnf("Job %d of type %s lost its lease, skipped", j.ID, j.Type)
+		return
+	}
+
+	h, ok := r.handlers[j.Type]
+	if !ok {
+		r.fail(j, fmt.Errorf("No handler for job type %s", j.Type), true)
+		return
+	}
+
+	done := make(chan struct{})
+	go r.heartbeat(j, done)
+	err = h(j)
+	close(done)
+
+	if err != nil {
+		r.fail(j, err, j.Attempts >= maxAttempts)
+		return
+	}
+
+	if err := r.rp.CompleteJob(j.ID); err != nil {
+		r.logger.Errorf(err.Error())
+	}
+}
+
+// heartbeat renews the lease of a running job until done is closed
+func (r *Runner) heartbeat(j *models.Job, done chan struct{}) {
+
+	ticker := time.NewTicker(r.lease / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if _, err := r.rp.RenewJobLease(j.ID, r.owner, r.clock.Now().Add(r.lease)); err != nil {
+				r.logger.Errorf(err.Error())
+			}
+		}
+	}
+}
+
+// fail records the job error, retrying it later unless final
+func (r *Runner) fail(j *models.Job, jerr error, final bool) {
+
+	r.logger.Errorf("Job %d of type %s failed: %s", j.ID, j.Type, jerr.Error())
+
+	retryAt := r.clock.Now().Add(time.Duration(j.Attempts) * retryBackoff)
+	if err := r.rp.FailJob(j.ID, jerr.Error(), retryAt, final); err != nil {
+		r.logger.Errorf(err.Error())
+	}
+}
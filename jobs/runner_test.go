@@ -0,0 +1,178 @@
+package jobs
+
+import (
+	"errors"
+	"github.com/labstack/echo"
+	"github.com/pintobikez/popmeet/api/models"
+	repo "github.com/pintobikez/popmeet/repository"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+/*
+Fake repository keeping the jobs in memory
+*/
+type fakeRepository struct {
+	repo.Repository
+	jobs   []*models.Job
+	owners map[int64]string
+	leases map[int64]time.Time
+}
+
+func (f *fakeRepository) lease(id int64, owner string, lease time.Time) {
+	if f.owners == nil {
+		f.owners, f.leases = make(map[int64]string), make(map[int64]time.Time)
+	}
+	f.owners[id], f.leases[id] = owner, lease
+}
+
+func (f *fakeRepository) ClaimDueJobs(owner string, now time.Time, lease time.Time, limit int) ([]*models.Job, error) {
+	var resp []*models.Job
+	for _, j := range f.jobs {
+		if j.Status == models.JobStatusPending && !j.RunAt.After(now) && len(resp) < limit {
+			j.Status = models.JobStatusRunning
+			j.Attempts++
+			f.lease(j.ID, owner, lease)
+			resp = append(resp, j)
+		}
+	}
+	return resp, nil
+}
+
+func (f *fakeRepository) RenewJobLease(id int64, owner string, lease time.Time) (bool, error) {
+	if f.jobs[id-1].Status != models.JobStatusRunning || f.owners[id] != owner {
+		return false, nil
+	}
+	f.lease(id, owner, lease)
+	return true, nil
+}
+
+func (f *fakeRepository) ResetExpiredJobs(now time.Time) (int64, error) {
+	var n int64
+	for _, j := range f.jobs {
+		if j.Status == models.JobStatusRunning && f.leases[j.ID].Before(now) {
+			j.Status = models.JobStatusPending
+			f.lease(j.ID, "", time.Time{})
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (f *fakeRepository) CompleteJob(id int64) error {
+	f.jobs[id-1].Status = models.JobStatusDone
+	return nil
+}
+
+func (f *fakeRepository) FailJob(id int64, msg string, retryAt time.Time, final bool) error {
+	j := f.jobs[id-1]
+	j.Status, j.RunAt, j.LastError = models.JobStatusPending, retryAt, msg
+	if final {
+		j.Status = models.JobStatusFailed
+	}
+	return nil
+}
+
+/*
+Provider struct for RunOnce method
+*/
+type providerRunOnce struct {
+	handlerErr error
+	advance    []time.Duration
+	runs       int
+	status     string
+}
+
+var testProviderRunOnce = []providerRunOnce{
+	{nil, []time.Duration{0}, 0, models.JobStatusPending},                                                        // not due yet
+	{nil, []time.Duration{time.Hour}, 1, models.JobStatusDone},                                                   // due
+	{nil, []time.Duration{time.Hour, time.Hour}, 1, models.JobStatusDone},                                        // not run twice
+	{errors.New("fail"), []time.Duration{time.Hour, 0}, 1, models.JobStatusPending},                              // waits for the retry
+	{errors.New("fail"), []time.Duration{time.Hour, time.Hour, time.Hour, time.Hour}, 3, models.JobStatusFailed}, // gives up
+}
+
+/* Test for RunOnce method */
+func TestRunOnce(t *testing.T) {
+
+	for _, pair := range testProviderRunOnce {
+
+		clock := NewFakeClock(time.Date(2018, 1, 1, 10, 0, 0, 0, time.UTC))
+		rp := &fakeRepository{jobs: []*models.Job{{ID: 1, Type: "test", RunAt: clock.Now().Add(time.Hour), Status: models.JobStatusPending}}}
+
+		runs := 0
+		r := NewRunner(rp, clock, time.Minute, echo.New().Logger)
+		r.Handle("test", func(j *models.Job) error {
+			runs++
+			return pair.handlerErr
+		})
+
+		for _, d := range pair.advance {
+			clock.Advance(d)
+			r.RunOnce()
+		}
+
+		// Assertions
+		assert.Equal(t, pair.runs, runs)
+		assert.Equal(t, pair.status, rp.jobs[0].Status)
+	}
+}
+
+/*
+Provider struct for the leases of the RunOnce method
+*/
+type providerRunOnceLease struct {
+	lease time.Duration
+	runs  int
+}
+
+var testProviderRunOnceLease = []providerRunOnceLease{
+	{time.Minute, 0},      // running in another instance
+	{-time.Minute, 1},     // the other instance stopped, its lease expired
+	{-time.Second * 1, 1}, // the lease just expired
+}
+
+/* Test for RunOnce method with the jobs claimed by another instance */
+func TestRunOnceLease(t *testing.T) {
+
+	for _, pair := range testProviderRunOnceLease {
+
+		clock := NewFakeClock(time.Date(2018, 1, 1, 10, 0, 0, 0, time.UTC))
+		rp := &fakeRepository{jobs: []*models.Job{{ID: 1, Type: "test", RunAt: clock.Now(), Status: models.JobStatusRunning, Attempts: 1}}}
+		rp.lease(1, "other", clock.Now().Add(pair.lease))
+
+		runs := 0
+		r := NewRunner(rp, clock, time.Minute, echo.New().Logger)
+		r.Handle("test", func(j *models.Job) error {
+			runs++
+			return nil
+		})
+		r.RunOnce()
+
+		// Assertions
+		assert.Equal(t, pair.runs, runs)
+		assert.NotEqual(t, "other", r.owner)
+	}
+}
+
+/* Test for run method, a job of the batch given to another instance while it waited is skipped */
+func TestRunLostLease(t *testing.T) {
+
+	clock := NewFakeClock(time.Date(2018, 1, 1, 10, 0, 0, 0, time.UTC))
+	rp := &fakeRepository{jobs: []*models.Job{{ID: 1, Type: "test", RunAt: clock.Now(), Status: models.JobStatusPending}}}
+
+	runs := 0
+	r := NewRunner(rp, clock, time.Minute, echo.New().Logger)
+	r.Handle("test", func(j *models.Job) error {
+		runs++
+		return nil
+	})
+
+	js, _ := rp.ClaimDueJobs(r.owner, clock.Now(), clock.Now().Add(r.lease), batchSize)
+	rp.lease(1, "other", clock.Now().Add(r.lease))
+	r.run(js[0])
+
+	// Assertions
+	assert.Equal(t, 0, runs)
+	assert.Equal(t, models.JobStatusRunning, rp.jobs[0].Status)
+}
@@ -0,0 +1,67 @@
+package logging
+
+import (
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const Redacted = "[REDACTED]"
+
+// sensitive lists the fields and parameters never written to the logs
+var sensitive = []string{"password", "current_password", "new_password", "token", "challenge_token", "code", "secret", "authorization", "cookie"}
+
+var (
+	jsonFieldRe = regexp.MustCompile(`(?i)("(?:` + strings.Join(sensitive, "|") + `)"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+	bearerRe    = regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9\-_.=+/]+`)
+)
+
+// Redact Hides the values of the sensitive JSON fields and the bearer tokens of a log line
+func Redact(s string) string {
+	s = jsonFieldRe.ReplaceAllString(s, `$1"`+Redacted+`"`)
+	return bearerRe.ReplaceAllString(s, "${1}"+Redacted)
+}
+
+// RedactQuery Hides the values of the sensitive parameters of an uri
+func RedactQuery(uri string) string {
+	i := strings.Index(uri, "?")
+	if i < 0 {
+		return uri
+	}
+
+	q, err := url.ParseQuery(uri[i+1:])
+	if err != nil {
+		return uri[:i] + "?" + Redacted
+	}
+	for k := range q {
+		if isSensitive(k) {
+			q.Set(k, Redacted)
+		}
+	}
+
+	return uri[:i] + "?" + q.Encode()
+}
+
+func isSensitive(name string) bool {
+	name = strings.ToLower(name)
+	for _, s := range sensitive {
+		if name == s {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactingWriter redacts every write before passing it to the wrapped writer
+type RedactingWriter struct {
+	W io.Writer
+}
+
+func (r *RedactingWriter) Write(p []byte) (int, error) {
+	if _, err := r.W.Write([]byte(Redact(string(p)))); err != nil {
+		return 0, err
+	}
+	// the caller wrote all its bytes even if the redacted line has a different size
+	return len(p), nil
+}
@@ -0,0 +1,144 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const backupTimeFormat = "20060102-150405"
+
+// RotateConfig sets when a log file is rotated and how many old files are kept.
+// Zero values disable the matching rule
+type RotateConfig struct {
+	MaxSize    int64
+	Interval   time.Duration
+	MaxBackups int
+	MaxAge     time.Duration
+}
+
+// RotatingWriter writes to a file, renaming it with a timestamp suffix when it gets too big or too old
+type RotatingWriter struct {
+	mu       sync.Mutex
+	path     string
+	cnf      RotateConfig
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	now      func() time.Time
+}
+
+// NewRotatingWriter Opens, or creates, the file in the given path
+func NewRotatingWriter(path string, c RotateConfig) (*RotatingWriter, error) {
+	w := &RotatingWriter{path: path, cnf: c, now: time.Now}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write Writes to the current file, rotating it before when needed
+func (w *RotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.shouldRotate(int64(len(p))) {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Close Closes the current file
+func (w *RotatingWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.file.Close()
+}
+
+// shouldRotate Checks the size and age of the current file, empty files are never rotated
+func (w *RotatingWriter) shouldRotate(next int64) bool {
+	if w.size == 0 {
+		return false
+	}
+	if w.cnf.MaxSize > 0 && w.size+next > w.cnf.MaxSize {
+		return true
+	}
+	return w.cnf.Interval > 0 && w.now().Sub(w.openedAt) >= w.cnf.Interval
+}
+
+// open Opens the file in append mode
+func (w *RotatingWriter) open() error {
+	file, err := os.OpenFile(w.path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	w.file, w.size, w.openedAt = file, info.Size(), w.now()
+	return nil
+}
+
+// rotate Renames the current file, opens a new one and removes the backups out of the retention
+func (w *RotatingWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+
+	backup := w.path + "." + w.now().Format(backupTimeFormat)
+	for i := 1; exists(backup); i++ {
+		backup = fmt.Sprintf("%s.%s.%d", w.path, w.now().Format(backupTimeFormat), i)
+	}
+	if err := os.Rename(w.path, backup); err != nil {
+		return err
+	}
+
+	if err := w.open(); err != nil {
+		return err
+	}
+
+	return w.cleanup()
+}
+
+// cleanup Removes the oldest backups over MaxBackups and the ones older than MaxAge
+func (w *RotatingWriter) cleanup() error {
+	backups, err := filepath.Glob(w.path + ".*")
+	if err != nil {
+		return err
+	}
+	// the timestamp suffix sorts them from the oldest
+	sort.Strings(backups)
+
+	for i, b := range backups {
+		remove := w.cnf.MaxBackups > 0 && i < len(backups)-w.cnf.MaxBackups
+		if !remove && w.cnf.MaxAge > 0 {
+			if info, err := os.Stat(b); err == nil && w.now().Sub(info.ModTime()) > w.cnf.MaxAge {
+				remove = true
+			}
+		}
+		if remove {
+			if err := os.Remove(b); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
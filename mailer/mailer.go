@@ -0,0 +1,59 @@
+package mailer
+
+import (
+	"github.com/labstack/echo"
+	"sync"
+)
+
+// Mailer sends plain text emails
+type Mailer interface {
+	Send(to string, subject string, body string) error
+}
+
+// LogMailer writes the emails into a logger instead of sending them, for development
+type LogMailer struct {
+	Logger echo.Logger
+}
+
+func (m *LogMailer) Send(to string, subject string, body string) error {
+	m.Logger.Infoj(map[string]interface{}{
+		"mail_to": to,
+		"subject": subject,
+		"body":    body,
+	})
+	return nil
+}
+
+// Mail is an email kept by the FakeMailer
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// FakeMailer keeps the sent emails in memory, to be used in tests
+type FakeMailer struct {
+	Err  error
+	mu   sync.Mutex
+	sent []Mail
+}
+
+func (m *FakeMailer) Send(to string, subject string, body string) error {
+	if m.Err != nil {
+		return m.Err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, Mail{to, subject, body})
+
+	return nil
+}
+
+// Sent returns the emails sent so far
+func (m *FakeMailer) Sent() []Mail {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]Mail{}, m.sent...)
+}
@@ -0,0 +1,55 @@
+package mailer
+
+import (
+	"bytes"
+	"fmt"
+	cnfs "github.com/pintobikez/popmeet/config/structures"
+	"mime"
+	"net/smtp"
+	"strconv"
+	"strings"
+)
+
+// SmtpMailer sends the emails through an SMTP server
+type SmtpMailer struct {
+	Config *cnfs.SmtpConfig
+}
+
+func (m *SmtpMailer) Send(to string, subject string, body string) error {
+
+	msg, err := message(m.Config.From, to, subject, body)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.Config.User != "" {
+		auth = smtp.PlainAuth("", m.Config.User, m.Config.Pw, m.Config.Host)
+	}
+
+	addr := m.Config.Host + ":" + strconv.Itoa(m.Config.Port)
+	if err := smtp.SendMail(addr, auth, m.Config.From, []string{to}, msg); err != nil {
+		return fmt.Errorf("Error sending email to %s: %s", to, err.Error())
+	}
+
+	return nil
+}
+
+// message Builds the email. The subject has user texts like names and locations, a line break in it would
+// add headers so they are replaced by spaces, and it is encoded for the non ASCII characters
+func message(from string, to string, subject string, body string) ([]byte, error) {
+
+	if strings.ContainsAny(to, "\r\n") {
+		return nil, fmt.Errorf("Invalid email address %q", to)
+	}
+	subject = strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ").Replace(subject)
+
+	msg := new(bytes.Buffer)
+	fmt.Fprintf(msg, "From: %s\r\n", from)
+	fmt.Fprintf(msg, "To: %s\r\n", to)
+	fmt.Fprintf(msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(msg, "MIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n")
+	fmt.Fprintf(msg, "%s\r\n", body)
+
+	return msg.Bytes(), nil
+}
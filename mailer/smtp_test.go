@@ -0,0 +1,41 @@
+package mailer
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+/*
+Provider struct for message method
+*/
+type providerMessage struct {
+	to      string
+	subject string
+	iserro  bool
+	header  string
+}
+
+var testProviderMessage = []providerMessage{
+	{"user@popmeet.com", "Reset your password", false, "Subject: Reset your password\r\n"},                    // ASCII subject
+	{"user@popmeet.com", "Novo evento em Évora", false, "Subject: =?utf-8?q?Novo_evento_em_=C3=89vora?=\r\n"}, // non ASCII subject
+	{"user@popmeet.com", "x\r\nBcc: victim@popmeet.com", false, "Subject: x Bcc: victim@popmeet.com\r\n"},     // header injection in the subject
+	{"user@popmeet.com\r\nBcc: victim@popmeet.com", "Reset your password", true, ""},                          // header injection in the address
+}
+
+/* Test for message method */
+func TestMessage(t *testing.T) {
+
+	for _, pair := range testProviderMessage {
+
+		msg, err := message("noreply@popmeet.com", pair.to, pair.subject, "body")
+
+		// Assertions
+		assert.Equal(t, pair.iserro, (err != nil))
+		if err == nil {
+			headers := strings.SplitN(string(msg), "\r\n\r\n", 2)[0] + "\r\n"
+			assert.Contains(t, headers, pair.header)
+			assert.NotContains(t, headers, "\r\nBcc:")
+		}
+	}
+}
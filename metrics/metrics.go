@@ -0,0 +1,115 @@
+package metrics
+
+import (
+	"database/sql"
+	"github.com/prometheus/client_golang/prometheus"
+	"time"
+)
+
+const namespace = "popmeet"
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Number of HTTP requests by route and status.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of the HTTP requests by route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	repoDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "repository_call_duration_seconds",
+		Help:      "Duration of the repository calls by method.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"method"})
+
+	// Registrations counts the new users
+	Registrations = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "registrations_total",
+		Help:      "Number of registered users.",
+	})
+
+	// Logins counts the logins by result
+	Logins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logins_total",
+		Help:      "Number of logins by result.",
+	}, []string{"result"})
+
+	// EventsCreated counts the new events
+	EventsCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "events_created_total",
+		Help:      "Number of created events.",
+	})
+
+	// EventJoins counts the users added to events
+	EventJoins = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "event_joins_total",
+		Help:      "Number of users that joined an event.",
+	})
+)
+
+const (
+	LoginSuccess    = "success"
+	LoginFailure    = "failure"
+	LoginChallenged = "challenged"
+)
+
+func init() {
+	prometheus.MustRegister(httpRequests, httpDuration, repoDuration, Registrations, Logins, EventsCreated, EventJoins)
+}
+
+// ObserveRequest Records a served HTTP request
+func ObserveRequest(method string, route string, status string, d time.Duration) {
+	httpRequests.WithLabelValues(method, route, status).Inc()
+	httpDuration.WithLabelValues(method, route, status).Observe(d.Seconds())
+}
+
+// RegisterDBStats Exposes the connection pool stats of a database
+func RegisterDBStats(stats func() sql.DBStats) error {
+	return prometheus.Register(&dbStatsCollector{stats: stats})
+}
+
+// observe Records the duration of a repository call started at the given time
+func observe(method string, start time.Time) {
+	repoDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
+
+var (
+	dbOpenDesc     = prometheus.NewDesc(namespace+"_db_open_connections", "Number of open connections to the database.", nil, nil)
+	dbInUseDesc    = prometheus.NewDesc(namespace+"_db_in_use_connections", "Number of connections in use.", nil, nil)
+	dbIdleDesc     = prometheus.NewDesc(namespace+"_db_idle_connections", "Number of idle connections.", nil, nil)
+	dbWaitDesc     = prometheus.NewDesc(namespace+"_db_wait_count_total", "Number of connections waited for.", nil, nil)
+	dbWaitTimeDesc = prometheus.NewDesc(namespace+"_db_wait_duration_seconds_total", "Time blocked waiting for a connection.", nil, nil)
+)
+
+// dbStatsCollector reads the pool stats on each scrape
+type dbStatsCollector struct {
+	stats func() sql.DBStats
+}
+
+func (c *dbStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- dbOpenDesc
+	ch <- dbInUseDesc
+	ch <- dbIdleDesc
+	ch <- dbWaitDesc
+	ch <- dbWaitTimeDesc
+}
+
+func (c *dbStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.stats()
+	ch <- prometheus.MustNewConstMetric(dbOpenDesc, prometheus.GaugeValue, float64(s.OpenConnections))
+	ch <- prometheus.MustNewConstMetric(dbInUseDesc, prometheus.GaugeValue, float64(s.InUse))
+	ch <- prometheus.MustNewConstMetric(dbIdleDesc, prometheus.GaugeValue, float64(s.Idle))
+	ch <- prometheus.MustNewConstMetric(dbWaitDesc, prometheus.CounterValue, float64(s.WaitCount))
+	ch <- prometheus.MustNewConstMetric(dbWaitTimeDesc, prometheus.CounterValue, s.WaitDuration.Seconds())
+}
@@ -0,0 +1,512 @@
+package metrics
+
+import (
+	"github.com/pintobikez/popmeet/api/models"
+	repo "github.com/pintobikez/popmeet/repository"
+	"time"
+)
+
+// Repository times every call of the wrapped repository.
+// Methods not listed here are still served by the wrapped repository, without timing
+type Repository struct {
+	repo.Repository
+}
+
+func NewRepository(rp repo.Repository) *Repository {
+	return &Repository{Repository: rp}
+}
+
+func (r *Repository) UpdateUserInterests(interests []*models.Interest, id int64) error {
+	defer observe("UpdateUserInterests", time.Now())
+	return r.Repository.UpdateUserInterests(interests, id)
+}
+
+func (r *Repository) GetAllInterestByUserProfileId(id int64) ([]*models.Interest, error) {
+	defer observe("GetAllInterestByUserProfileId", time.Now())
+	return r.Repository.GetAllInterestByUserProfileId(id)
+}
+
+func (r *Repository) GetInterestById(id int64) (*models.Interest, error) {
+	defer observe("GetInterestById", time.Now())
+	return r.Repository.GetInterestById(id)
+}
+
+func (r *Repository) GetAllInterests() ([]*models.Interest, error) {
+	defer observe("GetAllInterests", time.Now())
+	return r.Repository.GetAllInterests()
+}
+
+func (r *Repository) InsertUser(u *models.User) error {
+	defer observe("InsertUser", time.Now())
+	return r.Repository.InsertUser(u)
+}
+
+func (r *Repository) UpdateUser(u *models.User) error {
+	defer observe("UpdateUser", time.Now())
+	return r.Repository.UpdateUser(u)
+}
+
+func (r *Repository) GetUserById(id int64) (*models.User, error) {
+	defer observe("GetUserById", time.Now())
+	return r.Repository.GetUserById(id)
+}
+
+func (r *Repository) FindUserById(id int64) (bool, error) {
+	defer observe("FindUserById", time.Now())
+	return r.Repository.FindUserById(id)
+}
+
+func (r *Repository) FindUserByEmail(email string) (bool, error) {
+	defer observe("FindUserByEmail", time.Now())
+	return r.Repository.FindUserByEmail(email)
+}
+
+func (r *Repository) GetUserByEmail(email string) (*models.User, error) {
+	defer observe("GetUserByEmail", time.Now())
+	return r.Repository.GetUserByEmail(email)
+}
+
+func (r *Repository) InsertUserProfile(u *models.UserProfile, id int64) error {
+	defer observe("InsertUserProfile", time.Now())
+	return r.Repository.InsertUserProfile(u, id)
+}
+
+func (r *Repository) UpdateUserProfile(u *models.UserProfile, id int64) error {
+	defer observe("UpdateUserProfile", time.Now())
+	return r.Repository.UpdateUserProfile(u, id)
+}
+
+func (r *Repository) GetUserProfileByUserId(id int64) (*models.UserProfile, error) {
+	defer observe("GetUserProfileByUserId", time.Now())
+	return r.Repository.GetUserProfileByUserId(id)
+}
+
+func (r *Repository) GetLanguageById(id int64) (*models.Language, error) {
+	defer observe("GetLanguageById", time.Now())
+	return r.Repository.GetLanguageById(id)
+}
+
+func (r *Repository) GetAllLanguage() ([]*models.Language, error) {
+	defer observe("GetAllLanguage", time.Now())
+	return r.Repository.GetAllLanguage()
+}
+
+func (r *Repository) InsertLanguage(l *models.Language) error {
+	defer observe("InsertLanguage", time.Now())
+	return r.Repository.InsertLanguage(l)
+}
+
+func (r *Repository) SetLanguageActive(id int64, active bool) error {
+	defer observe("SetLanguageActive", time.Now())
+	return r.Repository.SetLanguageActive(id, active)
+}
+
+func (r *Repository) GetUserLanguage(userId int64) (string, error) {
+	defer observe("GetUserLanguage", time.Now())
+	return r.Repository.GetUserLanguage(userId)
+}
+
+func (r *Repository) GetAllTranslations() ([]*models.Translation, error) {
+	defer observe("GetAllTranslations", time.Now())
+	return r.Repository.GetAllTranslations()
+}
+
+func (r *Repository) SaveTranslation(t *models.Translation) error {
+	defer observe("SaveTranslation", time.Now())
+	return r.Repository.SaveTranslation(t)
+}
+
+func (r *Repository) SetEmailVerified(id int64) error {
+	defer observe("SetEmailVerified", time.Now())
+	return r.Repository.SetEmailVerified(id)
+}
+
+func (r *Repository) SetPendingEmail(t *models.UserToken) error {
+	defer observe("SetPendingEmail", time.Now())
+	return r.Repository.SetPendingEmail(t)
+}
+
+func (r *Repository) ConfirmPendingEmail(id int64, email string) error {
+	defer observe("ConfirmPendingEmail", time.Now())
+	return r.Repository.ConfirmPendingEmail(id, email)
+}
+
+func (r *Repository) InsertUserToken(t *models.UserToken) error {
+	defer observe("InsertUserToken", time.Now())
+	return r.Repository.InsertUserToken(t)
+}
+
+func (r *Repository) GetUserTokenByHash(purpose string, hash string) (*models.UserToken, error) {
+	defer observe("GetUserTokenByHash", time.Now())
+	return r.Repository.GetUserTokenByHash(purpose, hash)
+}
+
+func (r *Repository) UseUserToken(id int64) (bool, error) {
+	defer observe("UseUserToken", time.Now())
+	return r.Repository.UseUserToken(id)
+}
+
+func (r *Repository) InsertUserSecurity(u *models.UserSecurity, id int64) error {
+	defer observe("InsertUserSecurity", time.Now())
+	return r.Repository.InsertUserSecurity(u, id)
+}
+
+func (r *Repository) UpdateUserSecurity(u *models.UserSecurity, id int64) error {
+	defer observe("UpdateUserSecurity", time.Now())
+	return r.Repository.UpdateUserSecurity(u, id)
+}
+
+func (r *Repository) ChangePassword(id int64, hash string) (int64, error) {
+	defer observe("ChangePassword", time.Now())
+	return r.Repository.ChangePassword(id, hash)
+}
+
+func (r *Repository) GetSessionVersion(userId int64) (int64, error) {
+	defer observe("GetSessionVersion", time.Now())
+	return r.Repository.GetSessionVersion(userId)
+}
+
+func (r *Repository) SetUserRole(id int64, role string) error {
+	defer observe("SetUserRole", time.Now())
+	return r.Repository.SetUserRole(id, role)
+}
+
+func (r *Repository) GetSecurityInfoByUserId(id int64) (*models.UserSecurity, error) {
+	defer observe("GetSecurityInfoByUserId", time.Now())
+	return r.Repository.GetSecurityInfoByUserId(id)
+}
+
+func (r *Repository) SetTotpSecret(userId int64, secret string) error {
+	defer observe("SetTotpSecret", time.Now())
+	return r.Repository.SetTotpSecret(userId, secret)
+}
+
+func (r *Repository) EnableTotp(userId int64, codeHashes []string) error {
+	defer observe("EnableTotp", time.Now())
+	return r.Repository.EnableTotp(userId, codeHashes)
+}
+
+func (r *Repository) DisableTotp(userId int64) error {
+	defer observe("DisableTotp", time.Now())
+	return r.Repository.DisableTotp(userId)
+}
+
+func (r *Repository) UseTotpStep(userId int64, step int64) (bool, error) {
+	defer observe("UseTotpStep", time.Now())
+	return r.Repository.UseTotpStep(userId, step)
+}
+
+func (r *Repository) UseRecoveryCode(userId int64, hash string) (bool, error) {
+	defer observe("UseRecoveryCode", time.Now())
+	return r.Repository.UseRecoveryCode(userId, hash)
+}
+
+func (r *Repository) GetLoginProviderById(id int64) (*models.LoginProvider, error) {
+	defer observe("GetLoginProviderById", time.Now())
+	return r.Repository.GetLoginProviderById(id)
+}
+
+func (r *Repository) GetAllLoginProvider() ([]*models.LoginProvider, error) {
+	defer observe("GetAllLoginProvider", time.Now())
+	return r.Repository.GetAllLoginProvider()
+}
+
+func (r *Repository) InsertLoginProvider(p *models.LoginProvider) error {
+	defer observe("InsertLoginProvider", time.Now())
+	return r.Repository.InsertLoginProvider(p)
+}
+
+func (r *Repository) UpdateLoginProvider(p *models.LoginProvider) error {
+	defer observe("UpdateLoginProvider", time.Now())
+	return r.Repository.UpdateLoginProvider(p)
+}
+
+func (r *Repository) UpdateLoginData(u *models.UserSecurity) error {
+	defer observe("UpdateLoginData", time.Now())
+	return r.Repository.UpdateLoginData(u)
+}
+
+func (r *Repository) RecordLoginFailure(id int64, machine string, at time.Time) (int64, error) {
+	defer observe("RecordLoginFailure", time.Now())
+	return r.Repository.RecordLoginFailure(id, machine, at)
+}
+
+func (r *Repository) LockUserSecurity(id int64, until time.Time) error {
+	defer observe("LockUserSecurity", time.Now())
+	return r.Repository.LockUserSecurity(id, until)
+}
+
+func (r *Repository) ResetLoginFailures(id int64) error {
+	defer observe("ResetLoginFailures", time.Now())
+	return r.Repository.ResetLoginFailures(id)
+}
+
+func (r *Repository) AddUserToEvent(idEvent int64, idUser int64) error {
+	defer observe("AddUserToEvent", time.Now())
+	return r.Repository.AddUserToEvent(idEvent, idUser)
+}
+
+func (r *Repository) RemoveUserFromEvent(idEvent int64, idUser int64) error {
+	defer observe("RemoveUserFromEvent", time.Now())
+	return r.Repository.RemoveUserFromEvent(idEvent, idUser)
+}
+
+func (r *Repository) InsertEvent(u *models.Event) error {
+	defer observe("InsertEvent", time.Now())
+	return r.Repository.InsertEvent(u)
+}
+
+func (r *Repository) UpdateEvent(u *models.Event) error {
+	defer observe("UpdateEvent", time.Now())
+	return r.Repository.UpdateEvent(u)
+}
+
+func (r *Repository) FindEventById(id int64) (bool, error) {
+	defer observe("FindEventById", time.Now())
+	return r.Repository.FindEventById(id)
+}
+
+func (r *Repository) GetEventById(id int64) (*models.Event, error) {
+	defer observe("GetEventById", time.Now())
+	return r.Repository.GetEventById(id)
+}
+
+func (r *Repository) GetUserEventsByUserId(id int64) ([]*models.Event, error) {
+	defer observe("GetUserEventsByUserId", time.Now())
+	return r.Repository.GetUserEventsByUserId(id)
+}
+
+func (r *Repository) CompletePastEvents(now time.Time) (int64, error) {
+	defer observe("CompletePastEvents", time.Now())
+	return r.Repository.CompletePastEvents(now)
+}
+
+func (r *Repository) ArchiveEvents(before time.Time, limit int) (int64, error) {
+	defer observe("ArchiveEvents", time.Now())
+	return r.Repository.ArchiveEvents(before, limit)
+}
+
+func (r *Repository) HaveSharedEvent(idUser int64, idOther int64) (bool, error) {
+	defer observe("HaveSharedEvent", time.Now())
+	return r.Repository.HaveSharedEvent(idUser, idOther)
+}
+
+func (r *Repository) IsUserBlocked(idUser int64, idOther int64) (bool, error) {
+	defer observe("IsUserBlocked", time.Now())
+	return r.Repository.IsUserBlocked(idUser, idOther)
+}
+
+func (r *Repository) BlockUser(idUser int64, idBlocked int64) error {
+	defer observe("BlockUser", time.Now())
+	return r.Repository.BlockUser(idUser, idBlocked)
+}
+
+func (r *Repository) UnblockUser(idUser int64, idBlocked int64) error {
+	defer observe("UnblockUser", time.Now())
+	return r.Repository.UnblockUser(idUser, idBlocked)
+}
+
+func (r *Repository) GetBlockedUsersByUserId(id int64) ([]*models.User, error) {
+	defer observe("GetBlockedUsersByUserId", time.Now())
+	return r.Repository.GetBlockedUsersByUserId(id)
+}
+
+func (r *Repository) GetBlockRelatedUserIds(id int64) ([]int64, error) {
+	defer observe("GetBlockRelatedUserIds", time.Now())
+	return r.Repository.GetBlockRelatedUserIds(id)
+}
+
+func (r *Repository) FollowUser(idUser int64, idFollowed int64) error {
+	defer observe("FollowUser", time.Now())
+	return r.Repository.FollowUser(idUser, idFollowed)
+}
+
+func (r *Repository) UnfollowUser(idUser int64, idFollowed int64) error {
+	defer observe("UnfollowUser", time.Now())
+	return r.Repository.UnfollowUser(idUser, idFollowed)
+}
+
+func (r *Repository) GetFollowersByUserId(id int64) ([]*models.User, error) {
+	defer observe("GetFollowersByUserId", time.Now())
+	return r.Repository.GetFollowersByUserId(id)
+}
+
+func (r *Repository) GetFollowingByUserId(id int64) ([]*models.User, error) {
+	defer observe("GetFollowingByUserId", time.Now())
+	return r.Repository.GetFollowingByUserId(id)
+}
+
+func (r *Repository) GetFollowerIdsByUserId(id int64) ([]int64, error) {
+	defer observe("GetFollowerIdsByUserId", time.Now())
+	return r.Repository.GetFollowerIdsByUserId(id)
+}
+
+func (r *Repository) AreMutualFollowers(idUser int64, idOther int64) (bool, error) {
+	defer observe("AreMutualFollowers", time.Now())
+	return r.Repository.AreMutualFollowers(idUser, idOther)
+}
+
+func (r *Repository) GetFeedEventsByUserId(id int64, limit int) ([]*models.Event, error) {
+	defer observe("GetFeedEventsByUserId", time.Now())
+	return r.Repository.GetFeedEventsByUserId(id, limit)
+}
+
+func (r *Repository) InsertReport(rp *models.Report) error {
+	defer observe("InsertReport", time.Now())
+	return r.Repository.InsertReport(rp)
+}
+
+func (r *Repository) GetReportById(id int64) (*models.Report, error) {
+	defer observe("GetReportById", time.Now())
+	return r.Repository.GetReportById(id)
+}
+
+func (r *Repository) GetReportsByStatus(status string) ([]*models.Report, error) {
+	defer observe("GetReportsByStatus", time.Now())
+	return r.Repository.GetReportsByStatus(status)
+}
+
+func (r *Repository) GetReportsByTarget(idUser int64, idEvent int64) ([]*models.Report, error) {
+	defer observe("GetReportsByTarget", time.Now())
+	return r.Repository.GetReportsByTarget(idUser, idEvent)
+}
+
+func (r *Repository) ResolveReport(ac *models.ModerationAction) error {
+	defer observe("ResolveReport", time.Now())
+	return r.Repository.ResolveReport(ac)
+}
+
+func (r *Repository) GetModerationActionsByReportId(id int64) ([]*models.ModerationAction, error) {
+	defer observe("GetModerationActionsByReportId", time.Now())
+	return r.Repository.GetModerationActionsByReportId(id)
+}
+
+func (r *Repository) InsertMessage(m *models.Message) error {
+	defer observe("InsertMessage", time.Now())
+	return r.Repository.InsertMessage(m)
+}
+
+func (r *Repository) GetMessagesBetweenUsers(idUser int64, idPeer int64, before int64, limit int) ([]*models.Message, error) {
+	defer observe("GetMessagesBetweenUsers", time.Now())
+	return r.Repository.GetMessagesBetweenUsers(idUser, idPeer, before, limit)
+}
+
+func (r *Repository) GetConversationsByUserId(id int64) ([]*models.Conversation, error) {
+	defer observe("GetConversationsByUserId", time.Now())
+	return r.Repository.GetConversationsByUserId(id)
+}
+
+func (r *Repository) MarkMessagesAsRead(idUser int64, idPeer int64) error {
+	defer observe("MarkMessagesAsRead", time.Now())
+	return r.Repository.MarkMessagesAsRead(idUser, idPeer)
+}
+
+func (r *Repository) CountUnreadMessages(id int64) (int64, error) {
+	defer observe("CountUnreadMessages", time.Now())
+	return r.Repository.CountUnreadMessages(id)
+}
+
+func (r *Repository) InsertNotification(n *models.Notification) error {
+	defer observe("InsertNotification", time.Now())
+	return r.Repository.InsertNotification(n)
+}
+
+func (r *Repository) GetNotificationsByUserId(id int64, unreadOnly bool, limit int) ([]*models.Notification, error) {
+	defer observe("GetNotificationsByUserId", time.Now())
+	return r.Repository.GetNotificationsByUserId(id, unreadOnly, limit)
+}
+
+func (r *Repository) MarkNotificationsAsRead(idUser int64, id int64) error {
+	defer observe("MarkNotificationsAsRead", time.Now())
+	return r.Repository.MarkNotificationsAsRead(idUser, id)
+}
+
+func (r *Repository) GetNotificationPreferencesByUserId(id int64) ([]*models.NotificationPreference, error) {
+	defer observe("GetNotificationPreferencesByUserId", time.Now())
+	return r.Repository.GetNotificationPreferencesByUserId(id)
+}
+
+func (r *Repository) UpdateNotificationPreferences(id int64, ps []*models.NotificationPreference) error {
+	defer observe("UpdateNotificationPreferences", time.Now())
+	return r.Repository.UpdateNotificationPreferences(id, ps)
+}
+
+func (r *Repository) InsertJob(j *models.Job) error {
+	defer observe("InsertJob", time.Now())
+	return r.Repository.InsertJob(j)
+}
+
+func (r *Repository) ScheduleEventReminders(tp string, before time.Duration, now time.Time, window time.Duration) error {
+	defer observe("ScheduleEventReminders", time.Now())
+	return r.Repository.ScheduleEventReminders(tp, before, now, window)
+}
+
+func (r *Repository) ClaimDueJobs(owner string, now time.Time, lease time.Time, limit int) ([]*models.Job, error) {
+	defer observe("ClaimDueJobs", time.Now())
+	return r.Repository.ClaimDueJobs(owner, now, lease, limit)
+}
+
+func (r *Repository) RenewJobLease(id int64, owner string, lease time.Time) (bool, error) {
+	defer observe("RenewJobLease", time.Now())
+	return r.Repository.RenewJobLease(id, owner, lease)
+}
+
+func (r *Repository) CompleteJob(id int64) error {
+	defer observe("CompleteJob", time.Now())
+	return r.Repository.CompleteJob(id)
+}
+
+func (r *Repository) FailJob(id int64, msg string, retryAt time.Time, final bool) error {
+	defer observe("FailJob", time.Now())
+	return r.Repository.FailJob(id, msg, retryAt, final)
+}
+
+func (r *Repository) ResetExpiredJobs(now time.Time) (int64, error) {
+	defer observe("ResetExpiredJobs", time.Now())
+	return r.Repository.ResetExpiredJobs(now)
+}
+
+func (r *Repository) ScheduleUserDeletion(id int64, at time.Time, hostedEvents string) error {
+	defer observe("ScheduleUserDeletion", time.Now())
+	return r.Repository.ScheduleUserDeletion(id, at, hostedEvents)
+}
+
+func (r *Repository) CancelUserDeletion(id int64) error {
+	defer observe("CancelUserDeletion", time.Now())
+	return r.Repository.CancelUserDeletion(id)
+}
+
+func (r *Repository) GetUsersToErase(now time.Time, limit int) ([]*models.AccountErasure, error) {
+	defer observe("GetUsersToErase", time.Now())
+	return r.Repository.GetUsersToErase(now, limit)
+}
+
+func (r *Repository) EraseUser(id int64, hostedEvents string, now time.Time) ([]*models.HandedOverEvent, error) {
+	defer observe("EraseUser", time.Now())
+	return r.Repository.EraseUser(id, hostedEvents, now)
+}
+
+func (r *Repository) GetHostedEventsByUserId(id int64) ([]*models.Event, error) {
+	defer observe("GetHostedEventsByUserId", time.Now())
+	return r.Repository.GetHostedEventsByUserId(id)
+}
+
+func (r *Repository) GetJoinedEventsByUserId(id int64) ([]*models.Event, error) {
+	defer observe("GetJoinedEventsByUserId", time.Now())
+	return r.Repository.GetJoinedEventsByUserId(id)
+}
+
+func (r *Repository) GetMessagesByUserId(id int64) ([]*models.Message, error) {
+	defer observe("GetMessagesByUserId", time.Now())
+	return r.Repository.GetMessagesByUserId(id)
+}
+
+func (r *Repository) InsertAuditEntry(e *models.AuditEntry) error {
+	defer observe("InsertAuditEntry", time.Now())
+	return r.Repository.InsertAuditEntry(e)
+}
+
+func (r *Repository) GetAuditEntries(f *models.AuditFilter) ([]*models.AuditEntry, error) {
+	defer observe("GetAuditEntries", time.Now())
+	return r.Repository.GetAuditEntries(f)
+}
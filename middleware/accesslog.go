@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"encoding/json"
+	"github.com/labstack/echo"
+	er "github.com/pintobikez/popmeet/errors"
+	"github.com/pintobikez/popmeet/logging"
+	stru "github.com/pintobikez/popmeet/secure/structures"
+	"github.com/pintobikez/popmeet/tracing"
+	"io"
+	"sync"
+	"time"
+)
+
+type accessLine struct {
+	Time      string  `json:"time"`
+	RequestID string  `json:"request_id"`
+	TraceID   string  `json:"trace_id,omitempty"`
+	UserID    int64   `json:"user_id,omitempty"`
+	RemoteIP  string  `json:"remote_ip"`
+	Method    string  `json:"method"`
+	Route     string  `json:"route"`
+	Uri       string  `json:"uri"`
+	Status    int     `json:"status"`
+	Latency   float64 `json:"latency_ms"`
+	BytesIn   int64   `json:"bytes_in"`
+	BytesOut  int64   `json:"bytes_out"`
+	UserAgent string  `json:"user_agent"`
+}
+
+// AccessLog Middleware, writes a JSON line for each request with the sensitive parameters redacted
+func AccessLog(w io.Writer) echo.MiddlewareFunc {
+	var mu sync.Mutex
+	enc := json.NewEncoder(w)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+
+			start := time.Now()
+			err := next(c)
+
+			req := c.Request()
+			l := &accessLine{
+				Time:      start.UTC().Format(time.RFC3339),
+				RequestID: c.Response().Header().Get(echo.HeaderXRequestID),
+				TraceID:   tracing.TraceID(req.Context()),
+				RemoteIP:  RealIP(c),
+				Method:    req.Method,
+				Route:     c.Path(),
+				Uri:       logging.RedactQuery(req.RequestURI),
+				Status:    c.Response().Status,
+				Latency:   float64(time.Since(start).Nanoseconds()) / float64(time.Millisecond),
+				BytesIn:   req.ContentLength,
+				BytesOut:  c.Response().Size,
+				UserAgent: req.UserAgent(),
+			}
+
+			// the errors are written after the middlewares, same codes as the error handler
+			if err != nil {
+				l.Status = er.From(err).Status
+			}
+			if cl, ok := c.Get("claims").(*stru.TokenClaims); ok {
+				l.UserID = cl.ID
+			}
+
+			mu.Lock()
+			if e := enc.Encode(l); e != nil {
+				c.Logger().Errorf("Error writing the access log: %s", e.Error())
+			}
+			mu.Unlock()
+
+			return err
+		}
+	}
+}
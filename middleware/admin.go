@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"github.com/labstack/echo"
+	"github.com/pintobikez/popmeet/api/models"
+	er "github.com/pintobikez/popmeet/errors"
+	stru "github.com/pintobikez/popmeet/secure/structures"
+)
+
+// Admin Middleware, must be used after the Authorization one
+func Admin() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+
+			cl, ok := c.Get("claims").(*stru.TokenClaims)
+			if !ok || cl.Role != models.RoleAdmin {
+				return er.ErrForbidden
+			}
+
+			return next(c)
+		}
+	}
+}
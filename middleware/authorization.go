@@ -4,17 +4,26 @@ import (
 	"github.com/labstack/echo"
 	er "github.com/pintobikez/popmeet/errors"
 	"github.com/pintobikez/popmeet/secure"
-	"net/http"
 )
 
-// Authorization Middleware
-func Authorization(sec *secure.TokenManager) echo.MiddlewareFunc {
+// SessionStore Gets the current session version of the users
+type SessionStore interface {
+	GetSessionVersion(userId int64) (int64, error)
+}
+
+// Authorization Middleware, rejects the tokens of sessions ended by a password change
+func Authorization(sec *secure.TokenManager, ss SessionStore) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 
-			claims, err := sec.ValidateToken(c.Request().Header.Get(echo.HeaderAuthorization), "")
+			claims, err := sec.ValidateTokenContext(c.Request().Context(), c.Request().Header.Get(echo.HeaderAuthorization), "")
 			if err != nil {
-				return c.JSON(http.StatusUnauthorized, er.GeneralErrorJson(http.StatusUnauthorized, "Invalid token"))
+				return er.ErrUnauthorized
+			}
+
+			version, err := ss.GetSessionVersion(claims.ID)
+			if err != nil || version != claims.SessionVersion {
+				return er.ErrUnauthorized
 			}
 
 			c.Set("claims", claims)
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"github.com/labstack/echo"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ClientIP Middleware, resolves the ip of the client once for the rate limits, logins and logs
+func ClientIP(trusted []*net.IPNet) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("client_ip", ResolveIP(c.Request(), trusted))
+			return next(c)
+		}
+	}
+}
+
+// RealIP Gets the ip of the client resolved by ClientIP, the direct peer without it
+func RealIP(c echo.Context) string {
+	if ip, ok := c.Get("client_ip").(string); ok {
+		return ip
+	}
+	return ResolveIP(c.Request(), nil)
+}
+
+// ResolveIP Gets the ip of the client of a request. The forwarded headers are set by the clients,
+// they are only honoured when the direct peer is one of the trusted proxies
+func ResolveIP(r *http.Request, trusted []*net.IPNet) string {
+
+	peer, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		peer = r.RemoteAddr
+	}
+	if !contains(trusted, peer) {
+		return peer
+	}
+
+	// every proxy appends the address it received the request from, the first untrusted one from the right is the client
+	hops := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if net.ParseIP(hop) == nil {
+			break
+		}
+		if !contains(trusted, hop) {
+			return hop
+		}
+	}
+
+	if ip := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(ip) != nil {
+		return ip
+	}
+
+	return peer
+}
+
+// contains Checks if the ip is in any of the networks
+func contains(networks []*net.IPNet, ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, n := range networks {
+		if n.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net"
+	"net/http/httptest"
+	"testing"
+)
+
+/*
+Provider struct for ResolveIP method
+*/
+type providerResolveIP struct {
+	remote    string
+	forwarded string
+	real      string
+	ip        string
+}
+
+var testProviderResolveIP = []providerResolveIP{
+	{"203.0.113.5:5000", "", "", "203.0.113.5"},                              // direct client
+	{"203.0.113.5:5000", "198.51.100.7", "198.51.100.9", "203.0.113.5"},      // headers of an untrusted peer
+	{"10.0.0.2:5000", "198.51.100.7", "", "198.51.100.7"},                    // client behind a trusted proxy
+	{"10.0.0.2:5000", "1.2.3.4, 198.51.100.7, 10.0.0.3", "", "198.51.100.7"}, // spoofed first hop and a chain of trusted proxies
+	{"10.0.0.2:5000", "", "198.51.100.9", "198.51.100.9"},                    // real ip header of a trusted proxy
+	{"10.0.0.2:5000", "", "", "10.0.0.2"},                                    // trusted proxy without headers
+	{"[::1]:5000", "198.51.100.7", "", "::1"},                                // ipv6 peer
+}
+
+/* Test for ResolveIP method */
+func TestResolveIP(t *testing.T) {
+
+	_, proxies, _ := net.ParseCIDR("10.0.0.0/8")
+
+	for _, pair := range testProviderResolveIP {
+
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = pair.remote
+		if pair.forwarded != "" {
+			req.Header.Set("X-Forwarded-For", pair.forwarded)
+		}
+		if pair.real != "" {
+			req.Header.Set("X-Real-IP", pair.real)
+		}
+
+		// Assertions
+		assert.Equal(t, pair.ip, ResolveIP(req, []*net.IPNet{proxies}))
+	}
+}
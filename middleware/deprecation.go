@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"github.com/labstack/echo"
+	"net/http"
+	"time"
+)
+
+const (
+	HeaderDeprecation = "Deprecation"
+	HeaderSunset      = "Sunset"
+	HeaderLink        = "Link"
+)
+
+// Deprecated Middleware, marks the legacy routes with the Deprecation and Sunset headers
+// and links to the same route under the prefix of its successor version
+func Deprecated(sunset time.Time, successor string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+
+			h := c.Response().Header()
+			h.Set(HeaderDeprecation, "true")
+			if !sunset.IsZero() {
+				h.Set(HeaderSunset, sunset.UTC().Format(http.TimeFormat))
+			}
+			h.Add(HeaderLink, "<"+successor+c.Request().URL.Path+`>; rel="successor-version"`)
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"github.com/labstack/echo"
+	er "github.com/pintobikez/popmeet/errors"
+	"github.com/pintobikez/popmeet/metrics"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Metrics Middleware, records the count and latency of the requests by route
+func Metrics() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+
+			start := time.Now()
+			err := next(c)
+
+			// the errors are written after the middlewares, same codes as the error handler
+			status := c.Response().Status
+			if err != nil {
+				status = er.From(err).Status
+			}
+
+			// unknown routes are grouped to keep the labels bounded
+			route := c.Path()
+			if route == "" {
+				route = "unmatched"
+			}
+
+			metrics.ObserveRequest(c.Request().Method, route, strconv.Itoa(status), time.Since(start))
+
+			return err
+		}
+	}
+}
+
+// MetricsAccess Middleware, allows the requests from the allowed networks or with the bearer token.
+// The client ip is read from the forwarded headers only when the peer is one of the trusted proxies
+func MetricsAccess(allow []*net.IPNet, trusted []*net.IPNet, token string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+
+			given := strings.TrimPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+			if token != "" && subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1 {
+				return next(c)
+			}
+
+			if contains(allow, ResolveIP(c.Request(), trusted)) {
+				return next(c)
+			}
+
+			return er.ErrForbidden
+		}
+	}
+}
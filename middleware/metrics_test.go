@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"github.com/labstack/echo"
+	er "github.com/pintobikez/popmeet/errors"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+/*
+Provider struct for MetricsAccess method
+*/
+type providerMetricsAccess struct {
+	remote    string
+	forwarded string
+	token     string
+	status    int
+}
+
+var testProviderMetricsAccess = []providerMetricsAccess{
+	{"127.0.0.1:5000", "", "", http.StatusOK},                             // allowed peer
+	{"203.0.113.5:5000", "", "", http.StatusForbidden},                    // peer not allowed
+	{"203.0.113.5:5000", "127.0.0.1", "", http.StatusForbidden},           // spoofed header from a peer not allowed
+	{"10.0.0.2:5000", "127.0.0.1", "", http.StatusOK},                     // allowed client behind a trusted proxy
+	{"10.0.0.2:5000", "127.0.0.1, 203.0.113.5", "", http.StatusForbidden}, // spoofed header forwarded by a trusted proxy
+	{"203.0.113.5:5000", "", "secret", http.StatusOK},                     // bearer token
+	{"203.0.113.5:5000", "", "wrong", http.StatusForbidden},               // wrong bearer token
+}
+
+/* Test for MetricsAccess method */
+func TestMetricsAccess(t *testing.T) {
+
+	_, local, _ := net.ParseCIDR("127.0.0.1/32")
+	_, proxies, _ := net.ParseCIDR("10.0.0.0/8")
+	access := MetricsAccess([]*net.IPNet{local}, []*net.IPNet{proxies}, "secret")
+
+	e := echo.New()
+	next := func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+
+	for _, pair := range testProviderMetricsAccess {
+
+		req := httptest.NewRequest(echo.GET, "/metrics", nil)
+		req.RemoteAddr = pair.remote
+		if pair.forwarded != "" {
+			req.Header.Set("X-Forwarded-For", pair.forwarded)
+		}
+		if pair.token != "" {
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+pair.token)
+		}
+		rec := httptest.NewRecorder()
+
+		status := http.StatusOK
+		if err := access(next)(e.NewContext(req, rec)); err != nil {
+			status = er.From(err).Status
+		}
+
+		// Assertions
+		assert.Equal(t, pair.status, status)
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"github.com/labstack/echo"
+	er "github.com/pintobikez/popmeet/errors"
+	"github.com/pintobikez/popmeet/ratelimit"
+	"github.com/pintobikez/popmeet/secure"
+	"math"
+	"strconv"
+	"time"
+)
+
+// RateLimit Middleware, limits each route with its policy. The user key is read from the token
+// because it runs before the Authorization of the route, invalid tokens are limited by ip
+func RateLimit(l *ratelimit.Limiter, sec *secure.TokenManager) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+
+			p := l.Policy(c.Request().Method, c.Path())
+			if p == nil {
+				return next(c)
+			}
+
+			key := "ip:" + RealIP(c)
+			if p.Key == ratelimit.KeyUser {
+				if claims, err := sec.ValidateTokenContext(c.Request().Context(), c.Request().Header.Get(echo.HeaderAuthorization), ""); err == nil {
+					key = "user:" + strconv.FormatInt(claims.ID, 10)
+				}
+			}
+
+			ok, wait, err := l.Allow(p, key, time.Now())
+			if err != nil {
+				// let the request pass when the store is down
+				c.Logger().Errorf("Rate limit store error: %s", err.Error())
+				return next(c)
+			}
+
+			if !ok {
+				c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				return er.ErrTooManyRequests
+			}
+
+			return next(c)
+		}
+	}
+}
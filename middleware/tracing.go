@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"github.com/labstack/echo"
+	er "github.com/pintobikez/popmeet/errors"
+	"github.com/pintobikez/popmeet/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"net/http"
+)
+
+const HeaderXTraceID = "X-Trace-Id"
+
+// Tracing Middleware, continues the W3C trace of the request and sets its span in the request context
+func Tracing() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+
+			req := c.Request()
+			ctx := otel.GetTextMapPropagator().Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+
+			route := c.Path()
+			if route == "" {
+				route = "unmatched"
+			}
+
+			ctx, span := tracing.StartServer(ctx, req.Method+" "+route,
+				attribute.String("http.method", req.Method),
+				attribute.String("http.route", route),
+				attribute.String("http.request_id", c.Response().Header().Get(echo.HeaderXRequestID)),
+			)
+			defer span.End()
+
+			c.SetRequest(req.WithContext(ctx))
+			if id := tracing.TraceID(ctx); id != "" {
+				c.Response().Header().Set(HeaderXTraceID, id)
+			}
+
+			err := next(c)
+
+			// the errors are written after the middlewares, same codes as the error handler
+			status := c.Response().Status
+			if err != nil {
+				status = er.From(err).Status
+				span.RecordError(err)
+			}
+
+			span.SetAttributes(attribute.Int("http.status_code", status))
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+
+			return err
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package notification
+
+import (
+	"fmt"
+	"github.com/pintobikez/popmeet/api/models"
+	"github.com/pintobikez/popmeet/mailer"
+)
+
+const ChannelEmail = "email"
+
+// EmailTransport sends the notifications by email
+type EmailTransport struct {
+	Mailer mailer.Mailer
+}
+
+func (t *EmailTransport) Channel() string {
+	return ChannelEmail
+}
+
+// Send emails the notification to the user address
+func (t *EmailTransport) Send(u *models.User, n *models.Notification) error {
+
+	if u.Email == "" {
+		return fmt.Errorf("User %d has no email address", u.ID)
+	}
+
+	return t.Mailer.Send(u.Email, n.Title, n.Body)
+}
@@ -0,0 +1,39 @@
+package notification
+
+import (
+	"github.com/pintobikez/popmeet/api/models"
+	"sync"
+)
+
+// FakeTransport keeps the sent notifications in memory, to be used in tests
+type FakeTransport struct {
+	Name string
+	Err  error
+	mu   sync.Mutex
+	sent []*models.Notification
+}
+
+func (t *FakeTransport) Channel() string {
+	return t.Name
+}
+
+// Send records the notification, or fails with Err when set
+func (t *FakeTransport) Send(u *models.User, n *models.Notification) error {
+	if t.Err != nil {
+		return t.Err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.sent = append(t.sent, n)
+
+	return nil
+}
+
+// Sent returns the notifications sent so far
+func (t *FakeTransport) Sent() []*models.Notification {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return append([]*models.Notification{}, t.sent...)
+}
@@ -0,0 +1,102 @@
+package notification
+
+import (
+	"github.com/pintobikez/popmeet/api/models"
+	"github.com/pintobikez/popmeet/i18n"
+	repo "github.com/pintobikez/popmeet/repository"
+)
+
+const (
+	TypeFollowedUserEvent = "followed_user_event"
+	TypeEventUserJoined   = "event_user_joined"
+	TypeEventUpdated      = "event_updated"
+	TypeEventCancelled    = "event_cancelled"
+	TypeEventTransferred  = "event_transferred"
+	TypeEventReminder     = "event_reminder"
+)
+
+// Notifier delivers notifications to users
+type Notifier interface {
+	Notify(ns []*models.Notification) error
+}
+
+// Transport delivers a notification to a user through a channel
+type Transport interface {
+	Channel() string
+	Send(u *models.User, n *models.Notification) error
+}
+
+// Service persists the notifications in the users inbox and delivers them
+// through every transport the user didn't disable, written in the language of each user
+type Service struct {
+	rp         repo.Repository
+	cat        *i18n.Catalog
+	transports []Transport
+}
+
+func New(rpo repo.Repository, cat *i18n.Catalog, transports ...Transport) *Service {
+	return &Service{rp: rpo, cat: cat, transports: transports}
+}
+
+// Notify stores and delivers each one of the notifications, returning the first error found
+func (s *Service) Notify(ns []*models.Notification) error {
+	var first error
+
+	for _, n := range ns {
+		if err := s.notify(n); err != nil && first == nil {
+			first = err
+		}
+	}
+
+	return first
+}
+
+// notify stores a notification in the inbox and sends it through the enabled transports
+func (s *Service) notify(n *models.Notification) error {
+
+	if n.Key != "" && s.cat != nil {
+		lang := s.cat.Language(s.rp, n.UserID)
+		n.Title = s.cat.Translate(lang, "notification."+n.Key+".title", n.Args)
+		n.Body = s.cat.Translate(lang, "notification."+n.Key+".body", n.Args)
+	}
+
+	if err := s.rp.InsertNotification(n); err != nil {
+		return err
+	}
+
+	if len(s.transports) == 0 {
+		return nil
+	}
+
+	ps, err := s.rp.GetNotificationPreferencesByUserId(n.UserID)
+	if err != nil {
+		return err
+	}
+
+	u, err := s.rp.GetUserById(n.UserID)
+	if err != nil {
+		return err
+	}
+
+	var first error
+	for _, t := range s.transports {
+		if !enabled(ps, n.Type, t.Channel()) {
+			continue
+		}
+		if err := t.Send(u, n); err != nil && first == nil {
+			first = err
+		}
+	}
+
+	return first
+}
+
+// enabled Checks if a channel is enabled for a notification type, channels are enabled by default
+func enabled(ps []*models.NotificationPreference, tp string, channel string) bool {
+	for _, p := range ps {
+		if p.Type == tp && p.Channel == channel {
+			return p.Enabled
+		}
+	}
+	return true
+}
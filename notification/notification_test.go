@@ -0,0 +1,110 @@
+package notification
+
+import (
+	"errors"
+	"github.com/pintobikez/popmeet/api/models"
+	"github.com/pintobikez/popmeet/i18n"
+	repo "github.com/pintobikez/popmeet/repository"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+/*
+Fake repository with the methods used by the Service
+*/
+type fakeRepository struct {
+	repo.Repository
+	inbox []*models.Notification
+	prefs []*models.NotificationPreference
+	lang  string
+}
+
+func (f *fakeRepository) InsertNotification(n *models.Notification) error {
+	n.ID = int64(len(f.inbox) + 1)
+	f.inbox = append(f.inbox, n)
+	return nil
+}
+
+func (f *fakeRepository) GetNotificationPreferencesByUserId(id int64) ([]*models.NotificationPreference, error) {
+	return f.prefs, nil
+}
+
+func (f *fakeRepository) GetUserById(id int64) (*models.User, error) {
+	return &models.User{ID: id, Email: "teste@popmeet.com"}, nil
+}
+
+func (f *fakeRepository) GetUserLanguage(id int64) (string, error) {
+	return f.lang, nil
+}
+
+/*
+Provider struct for Notify method
+*/
+type providerNotify struct {
+	prefs     []*models.NotificationPreference
+	transErr  error
+	emailSent int
+	pushSent  int
+	iserro    bool
+}
+
+var testProviderNotify = []providerNotify{
+	{nil, nil, 1, 1, false}, // all channels enabled by default
+	{[]*models.NotificationPreference{{Type: TypeEventUpdated, Channel: ChannelEmail, Enabled: false}}, nil, 0, 1, false},   // email disabled
+	{[]*models.NotificationPreference{{Type: TypeEventCancelled, Channel: ChannelEmail, Enabled: false}}, nil, 1, 1, false}, // other type disabled
+	{nil, errors.New("unavailable"), 0, 0, true}, // transports failing
+}
+
+/* Test for Notify method */
+func TestNotify(t *testing.T) {
+
+	for _, pair := range testProviderNotify {
+
+		rp := &fakeRepository{prefs: pair.prefs}
+		email := &FakeTransport{Name: ChannelEmail, Err: pair.transErr}
+		push := &FakeTransport{Name: ChannelPush, Err: pair.transErr}
+		s := New(rp, nil, email, push)
+
+		err := s.Notify([]*models.Notification{{UserID: 1, Type: TypeEventUpdated, Title: "title", Body: "body"}})
+
+		// Assertions
+		assert.Equal(t, pair.iserro, (err != nil))
+		assert.Equal(t, 1, len(rp.inbox))
+		assert.Equal(t, pair.emailSent, len(email.Sent()))
+		assert.Equal(t, pair.pushSent, len(push.Sent()))
+	}
+}
+
+/*
+Provider struct for the translation of the notifications
+*/
+type providerNotifyLanguage struct {
+	lang  string
+	title string
+}
+
+var testProviderNotifyLanguage = []providerNotifyLanguage{
+	{"", "The event at Porto was cancelled"},   // no profile
+	{"pt", "O evento em Porto foi cancelado"},  // translated
+	{"de", "The event at Porto was cancelled"}, // not translated
+}
+
+/* Test for Notify method with the translation of the notifications */
+func TestNotifyLanguage(t *testing.T) {
+
+	cat, err := i18n.New()
+	assert.Nil(t, err)
+
+	for _, pair := range testProviderNotifyLanguage {
+
+		rp := &fakeRepository{lang: pair.lang}
+		s := New(rp, cat)
+
+		err := s.Notify([]*models.Notification{{UserID: 1, Type: TypeEventCancelled, Key: TypeEventCancelled, Args: map[string]string{"location": "Porto", "date": "today"}}})
+
+		// Assertions
+		assert.Nil(t, err)
+		assert.Equal(t, pair.title, rp.inbox[0].Title)
+		assert.Contains(t, rp.inbox[0].Body, "Porto")
+	}
+}
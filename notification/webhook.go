@@ -0,0 +1,64 @@
+package notification
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/pintobikez/popmeet/api/models"
+	cnfs "github.com/pintobikez/popmeet/config/structures"
+	"net/http"
+	"time"
+)
+
+const (
+	ChannelPush           = "push"
+	defaultWebhookTimeout = 5
+)
+
+// WebhookTransport sends the notifications as push messages through a generic HTTP webhook
+type WebhookTransport struct {
+	Config *cnfs.WebhookConfig
+	client *http.Client
+}
+
+func NewWebhookTransport(cnfg *cnfs.WebhookConfig) *WebhookTransport {
+	timeout := cnfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+	return &WebhookTransport{Config: cnfg, client: &http.Client{Timeout: time.Duration(timeout) * time.Second}}
+}
+
+func (t *WebhookTransport) Channel() string {
+	return ChannelPush
+}
+
+// Send posts the notification as JSON to the webhook url
+func (t *WebhookTransport) Send(u *models.User, n *models.Notification) error {
+
+	body, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, t.Config.Url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if t.Config.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+t.Config.Token)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("Error sending push notification %d to user %d: %s", n.ID, u.ID, err.Error())
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("Error sending push notification %d to user %d: webhook answered %d", n.ID, u.ID, resp.StatusCode)
+	}
+
+	return nil
+}
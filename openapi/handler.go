@@ -0,0 +1,69 @@
+package openapi
+
+import (
+	"embed"
+	"fmt"
+	"github.com/labstack/echo"
+	er "github.com/pintobikez/popmeet/errors"
+	"html"
+	"net/http"
+	"path"
+)
+
+// assets are the scripts and styles of the swagger-ui-dist package, shipped with the service
+// instead of loaded from a cdn
+//
+//go:embed swagger-ui/*.css swagger-ui/*.js
+var assets embed.FS
+
+// uiPage is the Swagger UI page, the scripts and styles are served by Assets under the given path
+const uiPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>%[1]s</title>
+  <link rel="stylesheet" href="%[2]s/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="%[2]s/swagger-ui-bundle.js"></script>
+  <script>
+    window.onload = function() {
+      window.ui = SwaggerUIBundle({url: "%[3]s", dom_id: "#swagger-ui"});
+    };
+  </script>
+</body>
+</html>
+`
+
+// Handler Handler to GET the specification
+func Handler(doc *Document) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return c.JSON(http.StatusOK, doc)
+	}
+}
+
+// UI Handler to GET the Swagger UI page of the specification at the given url, with the assets at assetsURL
+func UI(title string, specURL string, assetsURL string) echo.HandlerFunc {
+	page := fmt.Sprintf(uiPage, html.EscapeString(title), html.EscapeString(assetsURL), html.EscapeString(specURL))
+	return func(c echo.Context) error {
+		return c.HTML(http.StatusOK, page)
+	}
+}
+
+// Assets Handler to GET the scripts and styles of the Swagger UI page, by the file param
+func Assets() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		file := path.Base(c.Param("file"))
+		content, err := assets.ReadFile("swagger-ui/" + file)
+		if err != nil {
+			return er.ErrNotFound.WithDetail("Asset %s not found", file)
+		}
+		ct := echo.MIMEApplicationJavaScriptCharsetUTF8
+		if path.Ext(file) == ".css" {
+			ct = "text/css; charset=utf-8"
+		}
+		c.Response().Header().Set("Cache-Control", "public, max-age=86400")
+		return c.Blob(http.StatusOK, ct, content)
+	}
+}
@@ -0,0 +1,69 @@
+package openapi
+
+import (
+	"github.com/labstack/echo"
+	er "github.com/pintobikez/popmeet/errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+/* Test for UI method, the page only loads the assets shipped with the service */
+func TestUI(t *testing.T) {
+
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	err := UI("popmeet", "/openapi.json", "/docs")(e.NewContext(httptest.NewRequest(echo.GET, "/docs", nil), rec))
+
+	// Assertions
+	assert.Nil(t, err)
+	assert.NotContains(t, rec.Body.String(), "http://")
+	assert.NotContains(t, rec.Body.String(), "https://")
+	assert.Contains(t, rec.Body.String(), `href="/docs/swagger-ui.css"`)
+	assert.Contains(t, rec.Body.String(), `src="/docs/swagger-ui-bundle.js"`)
+	assert.Contains(t, rec.Body.String(), `url: "/openapi.json"`)
+}
+
+/*
+Provider struct for Assets method
+*/
+type providerAssets struct {
+	file        string
+	status      int
+	contentType string
+}
+
+var testProviderAssets = []providerAssets{
+	{"swagger-ui.css", http.StatusOK, "text/css; charset=utf-8"},
+	{"swagger-ui-bundle.js", http.StatusOK, echo.MIMEApplicationJavaScriptCharsetUTF8},
+	{"swagger-ui.js", http.StatusNotFound, ""},
+	{"README.md", http.StatusNotFound, ""},
+	{"../handler.go", http.StatusNotFound, ""},
+}
+
+/* Test for Assets method */
+func TestAssets(t *testing.T) {
+
+	e := echo.New()
+
+	for _, pair := range testProviderAssets {
+
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(echo.GET, "/docs/"+pair.file, nil), rec)
+		c.SetParamNames("file")
+		c.SetParamValues(pair.file)
+
+		status := http.StatusOK
+		if err := Assets()(c); err != nil {
+			status = er.From(err).Status
+		}
+
+		// Assertions
+		assert.Equal(t, pair.status, status)
+		if pair.status == http.StatusOK {
+			assert.Equal(t, pair.contentType, rec.Header().Get(echo.HeaderContentType))
+			assert.NotEmpty(t, rec.Body.Bytes())
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package openapi
+
+import (
+	"github.com/labstack/echo"
+	er "github.com/pintobikez/popmeet/errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const Version = "3.0.3"
+
+// Operation describes a route of the api, the models are only used for their type
+type Operation struct {
+	Method     string
+	Path       string
+	Tag        string
+	Summary    string
+	Auth       bool
+	Admin      bool
+	Query      []*Param
+	Request    interface{}
+	Response   interface{}
+	Token      bool
+	Deprecated bool
+}
+
+// Document is the root of the OpenAPI 3 specification
+type Document struct {
+	OpenAPI    string                          `json:"openapi"`
+	Info       *Info                           `json:"info"`
+	Paths      map[string]map[string]*PathItem `json:"paths"`
+	Components *Components                     `json:"components"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type PathItem struct {
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	OperationID string                `json:"operationId"`
+	Parameters  []*Param              `json:"parameters,omitempty"`
+	RequestBody *Body                 `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+}
+
+type Param struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required"`
+	Schema      *Schema `json:"schema"`
+}
+
+type Body struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Headers     map[string]*Header    `json:"headers,omitempty"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+// QueryParam Creates an optional query parameter of the given type
+func QueryParam(name string, tp string, description string) *Param {
+	return &Param{Name: name, In: "query", Description: description, Schema: &Schema{Type: tp}}
+}
+
+// Mount Copies the operations of an api version to the given prefix
+func Mount(ops []*Operation, prefix string, deprecated bool) []*Operation {
+	mounted := make([]*Operation, 0, len(ops))
+	for _, op := range ops {
+		m := *op
+		m.Path = prefix + op.Path
+		m.Deprecated = deprecated
+		mounted = append(mounted, &m)
+	}
+	return mounted
+}
+
+// Build Creates the specification of the given operations
+func Build(title string, version string, ops []*Operation) *Document {
+
+	b := newBuilder()
+	errRef := b.schema(er.Problem{})
+
+	doc := &Document{
+		OpenAPI:    Version,
+		Info:       &Info{Title: title, Version: version},
+		Paths:      make(map[string]map[string]*PathItem),
+		Components: &Components{Schemas: b.schemas, SecuritySchemes: map[string]*SecurityScheme{"bearer": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"}}},
+	}
+
+	for _, op := range ops {
+
+		path, params := convertPath(op.Path)
+		item := &PathItem{
+			Summary:     op.Summary,
+			OperationID: operationID(op.Method, op.Path),
+			Parameters:  append(params, op.Query...),
+			Responses:   map[string]*Response{"default": problemResponse("Error", errRef)},
+			Deprecated:  op.Deprecated,
+		}
+		if op.Tag != "" {
+			item.Tags = []string{op.Tag}
+		}
+
+		ok := &Response{Description: "OK"}
+		if op.Response != nil {
+			ok = jsonResponse("OK", b.schema(op.Response))
+		}
+		if op.Token {
+			ok.Headers = map[string]*Header{echo.HeaderAuthorization: {Description: "Session token of the user", Schema: &Schema{Type: "string"}}}
+		}
+		item.Responses[strconv.Itoa(http.StatusOK)] = ok
+
+		if op.Request != nil {
+			item.RequestBody = &Body{Required: true, Content: map[string]*MediaType{echo.MIMEApplicationJSON: {Schema: b.schema(op.Request)}}}
+			item.Responses[strconv.Itoa(http.StatusUnprocessableEntity)] = problemResponse("Validation error", errRef)
+		}
+		if op.Auth {
+			item.Security = []map[string][]string{{"bearer": {}}}
+			item.Responses[strconv.Itoa(http.StatusUnauthorized)] = problemResponse("Missing or invalid token", errRef)
+		}
+		if op.Admin {
+			item.Responses[strconv.Itoa(http.StatusForbidden)] = problemResponse("Admin role required", errRef)
+		}
+
+		if _, ok := doc.Paths[path]; !ok {
+			doc.Paths[path] = make(map[string]*PathItem)
+		}
+		doc.Paths[path][strings.ToLower(op.Method)] = item
+	}
+
+	return doc
+}
+
+// Missing Gets the routes without an entry in the specification, as "METHOD /path"
+func (d *Document) Missing(routes []*echo.Route) []string {
+
+	var missing []string
+	for _, r := range routes {
+		path, _ := convertPath(r.Path)
+		if _, ok := d.Paths[path][strings.ToLower(r.Method)]; !ok {
+			missing = append(missing, r.Method+" "+r.Path)
+		}
+	}
+	sort.Strings(missing)
+
+	return missing
+}
+
+// convertPath Changes the echo path parameters, /event/:id, to the OpenAPI ones, /event/{id}
+func convertPath(path string) (string, []*Param) {
+
+	var params []*Param
+	parts := strings.Split(path, "/")
+	for i, p := range parts {
+		if strings.HasPrefix(p, ":") {
+			name := p[1:]
+			sc := &Schema{Type: "string"}
+			if name == "id" {
+				sc = &Schema{Type: "integer", Format: "int64"}
+			}
+			params = append(params, &Param{Name: name, In: "path", Required: true, Schema: sc})
+			parts[i] = "{" + name + "}"
+		}
+	}
+
+	return strings.Join(parts, "/"), params
+}
+
+// operationID Creates a readable id of the operation, GET /event/:id/user => getEventIdUser
+func operationID(method string, path string) string {
+
+	id := strings.ToLower(method)
+	for _, p := range strings.FieldsFunc(path, func(r rune) bool { return r == '/' || r == ':' || r == '-' || r == '.' }) {
+		id += strings.ToUpper(p[:1]) + p[1:]
+	}
+
+	return id
+}
+
+func jsonResponse(description string, sc *Schema) *Response {
+	return &Response{Description: description, Content: map[string]*MediaType{echo.MIMEApplicationJSON: {Schema: sc}}}
+}
+
+// problemResponse Error response, sent as a problem+json (RFC 7807)
+func problemResponse(description string, sc *Schema) *Response {
+	return &Response{Description: description, Content: map[string]*MediaType{er.MIMEApplicationProblemJSON: {Schema: sc}}}
+}
@@ -0,0 +1,74 @@
+package openapi
+
+import (
+	"github.com/labstack/echo"
+	"github.com/pintobikez/popmeet/api/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+/*
+Provider struct for convertPath method
+*/
+type providerConvertPath struct {
+	path   string
+	result string
+	params int
+}
+
+var testProviderConvertPath = []providerConvertPath{
+	{"/event", "/event", 0},
+	{"/event/:id", "/event/{id}", 1},
+	{"/event/:id/user/:user", "/event/{id}/user/{user}", 2},
+}
+
+/* Test for convertPath method */
+func TestConvertPath(t *testing.T) {
+
+	for _, pair := range testProviderConvertPath {
+
+		path, params := convertPath(pair.path)
+
+		// Assertions
+		assert.Equal(t, pair.result, path)
+		assert.Len(t, params, pair.params)
+	}
+}
+
+/* Test for the schemas created from the validate tags */
+func TestSchemaRules(t *testing.T) {
+
+	b := newBuilder()
+	ref := b.schema(&models.NewUser{})
+	sc := b.schemas["NewUser"]
+
+	// Assertions
+	assert.Equal(t, "#/components/schemas/NewUser", ref.Ref)
+	assert.Equal(t, []string{"email", "name", "login_provider"}, sc.Required)
+	assert.Equal(t, "email", sc.Properties["email"].Format)
+	assert.Equal(t, 1, *sc.Properties["name"].MinLength)
+	assert.Equal(t, 255, *sc.Properties["name"].MaxLength)
+	assert.Equal(t, "int64", sc.Properties["login_provider"].Format)
+
+	b.schema(models.UserProfile{})
+	sc = b.schemas["UserProfile"]
+
+	assert.Equal(t, []string{"male", "female"}, sc.Properties["sex"].Enum)
+	assert.Equal(t, "array", sc.Properties["interests"].Type)
+	assert.Equal(t, "#/components/schemas/Interest", sc.Properties["interests"].Items.Ref)
+	assert.Equal(t, "date-time", sc.Properties["updated_at"].Format)
+	assert.NotContains(t, sc.Required, "interests")
+
+	b.schema(models.UserSecurity{})
+	assert.NotContains(t, b.schemas["UserSecurity"].Properties, "TotpSecret")
+}
+
+/* Test for Missing method */
+func TestMissing(t *testing.T) {
+
+	doc := Build("test", "1", []*Operation{{Method: echo.GET, Path: "/event/:id"}})
+	routes := []*echo.Route{{Method: echo.GET, Path: "/event/:id"}, {Method: echo.DELETE, Path: "/event/:id"}}
+
+	// Assertions
+	assert.Equal(t, []string{"DELETE /event/:id"}, doc.Missing(routes))
+}
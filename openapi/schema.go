@@ -0,0 +1,208 @@
+package openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema is the JSON schema of a model, the named models are referenced from the components
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// builder keeps the schemas of the named models already described
+type builder struct {
+	schemas map[string]*Schema
+}
+
+func newBuilder() *builder {
+	return &builder{schemas: make(map[string]*Schema)}
+}
+
+// schema Gets the schema of the value type
+func (b *builder) schema(v interface{}) *Schema {
+	return b.typeSchema(reflect.TypeOf(v))
+}
+
+func (b *builder) typeSchema(t reflect.Type) *Schema {
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Kind() == reflect.Struct:
+		return b.structRef(t)
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		return &Schema{Type: "array", Items: b.typeSchema(t.Elem())}
+	case t.Kind() == reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.typeSchema(t.Elem())}
+	}
+
+	return kindSchema(t.Kind())
+}
+
+// structRef Describes a struct in the components, returning the reference to it
+func (b *builder) structRef(t reflect.Type) *Schema {
+
+	ref := &Schema{Ref: "#/components/schemas/" + t.Name()}
+	if _, ok := b.schemas[t.Name()]; ok {
+		return ref
+	}
+
+	sc := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	// set before the fields, the models can reference themselves
+	b.schemas[t.Name()] = sc
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		name, _ := parseTag(f.Tag.Get("json"))
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		fs := b.typeSchema(f.Type)
+		if fs.Ref != "" {
+			// a reference can not have siblings, the rules go to the referenced schema
+			sc.Properties[name] = fs
+		} else {
+			sc.Properties[name] = applyRules(fs, f.Tag.Get("validate"))
+		}
+
+		if isRequired(f.Tag.Get("validate")) {
+			sc.Required = append(sc.Required, name)
+		}
+	}
+
+	return ref
+}
+
+// kindSchema Gets the schema of the basic types
+func kindSchema(k reflect.Kind) *Schema {
+	switch k {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	}
+	return &Schema{}
+}
+
+// applyRules Sets the limits of the validate tag in the schema
+func applyRules(sc *Schema, tag string) *Schema {
+
+	for _, rule := range strings.Split(tag, ",") {
+
+		if rule == "dive" {
+			// the rules after dive are for the items
+			break
+		}
+
+		name, param := rule, ""
+		if i := strings.Index(rule, "="); i >= 0 {
+			name, param = rule[:i], rule[i+1:]
+		}
+
+		switch name {
+		case "email":
+			sc.Format = "email"
+		case "oneof":
+			sc.Enum = strings.Fields(param)
+		case "min", "max", "len":
+			setLimit(sc, name, param)
+		}
+	}
+
+	return sc
+}
+
+// setLimit Sets the min, max or len rule, its meaning depends on the type
+func setLimit(sc *Schema, name string, param string) {
+
+	n, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+	i := int(n)
+
+	switch sc.Type {
+	case "string":
+		if name != "max" {
+			sc.MinLength = &i
+		}
+		if name != "min" {
+			sc.MaxLength = &i
+		}
+	case "array":
+		if name != "max" {
+			sc.MinItems = &i
+		}
+		if name != "min" {
+			sc.MaxItems = &i
+		}
+	case "integer", "number":
+		if name != "max" {
+			sc.Minimum = &n
+		}
+		if name != "min" {
+			sc.Maximum = &n
+		}
+	}
+}
+
+// isRequired Checks if the validate tag makes the field mandatory, omitempty makes it optional
+func isRequired(tag string) bool {
+	required := false
+	for _, rule := range strings.Split(tag, ",") {
+		switch rule {
+		case "omitempty", "dive":
+			return false
+		case "required":
+			required = true
+		}
+	}
+	return required
+}
+
+// parseTag Splits the json tag in the name and the options
+func parseTag(tag string) (string, string) {
+	if i := strings.Index(tag, ","); i >= 0 {
+		return tag[:i], tag[i+1:]
+	}
+	return tag, ""
+}
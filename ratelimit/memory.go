@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+const pruneSize = 10000
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	policy *Policy
+}
+
+// MemoryStore keeps the buckets in the memory of the instance
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket)}
+}
+
+// Take removes a token from the bucket of the key, creating it full
+func (s *MemoryStore) Take(key string, p *Policy, now time.Time) (bool, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.buckets[key]
+	if !ok {
+		if len(s.buckets) >= pruneSize {
+			s.prune(now)
+		}
+		b = &bucket{tokens: float64(p.Burst), last: now, policy: p}
+		s.buckets[key] = b
+	}
+
+	b.refill(now)
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0, nil
+	}
+
+	// rounded to the millisecond
+	wait := time.Duration(math.Floor((1-b.tokens)/p.Rate*1000+0.5)) * time.Millisecond
+	return false, wait, nil
+}
+
+// prune Removes the full buckets, they are the same as new ones
+func (s *MemoryStore) prune(now time.Time) {
+	for k, b := range s.buckets {
+		b.refill(now)
+		if b.tokens >= float64(b.policy.Burst) {
+			delete(s.buckets, k)
+		}
+	}
+}
+
+// refill Adds the tokens earned since the last refill
+func (b *bucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(float64(b.policy.Burst), b.tokens+elapsed*b.policy.Rate)
+		b.last = now
+	}
+}
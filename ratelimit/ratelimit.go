@@ -0,0 +1,119 @@
+package ratelimit
+
+import (
+	"fmt"
+	cnf "github.com/pintobikez/popmeet/config/structures"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	KeyIp   = "ip"
+	KeyUser = "user"
+)
+
+// Policy is a token bucket refilled at Rate tokens per second up to Burst tokens
+type Policy struct {
+	Name  string
+	Rate  float64
+	Burst int
+	Key   string
+}
+
+// Store keeps the token buckets, it can be shared between instances
+type Store interface {
+	// Take removes a token from the bucket of the key, returning the wait until the next token when it is empty
+	Take(key string, p *Policy, now time.Time) (bool, time.Duration, error)
+}
+
+// Limiter finds the policy of each route and takes the tokens from the store
+type Limiter struct {
+	store    Store
+	routes   map[string]*Policy
+	fallback *Policy
+}
+
+// DefaultConfig Limits the anonymous authentication routes by ip
+func DefaultConfig() *cnf.RateLimitConfig {
+	return &cnf.RateLimitConfig{
+		Policies: map[string]*cnf.RatePolicyConfig{
+			"auth": {PerMinute: 10, Burst: 10, Key: KeyIp},
+		},
+		Routes: map[string]string{
+			"PUT /register":         "auth",
+			"POST /login":           "auth",
+			"POST /login/2fa":       "auth",
+			"POST /password/forgot": "auth",
+			"POST /password/reset":  "auth",
+		},
+	}
+}
+
+// New Builds a Limiter with the policies of the config
+func New(c *cnf.RateLimitConfig, s Store) (*Limiter, error) {
+
+	policies := make(map[string]*Policy)
+	for name, pc := range c.Policies {
+		if pc == nil || pc.PerMinute <= 0 || pc.Burst <= 0 {
+			return nil, fmt.Errorf("Rate limit policy %s must have per_minute and burst", name)
+		}
+		key := pc.Key
+		if key == "" {
+			key = KeyIp
+		}
+		if key != KeyIp && key != KeyUser {
+			return nil, fmt.Errorf("Rate limit policy %s has an invalid key %s", name, key)
+		}
+		policies[name] = &Policy{Name: name, Rate: pc.PerMinute / 60, Burst: pc.Burst, Key: key}
+	}
+
+	l := &Limiter{store: s, routes: make(map[string]*Policy)}
+
+	for route, name := range c.Routes {
+		p, ok := policies[name]
+		if !ok {
+			return nil, fmt.Errorf("Rate limit policy %s of route %s not found", name, route)
+		}
+		l.routes[route] = p
+	}
+
+	if c.Default != "" {
+		p, ok := policies[c.Default]
+		if !ok {
+			return nil, fmt.Errorf("Rate limit default policy %s not found", c.Default)
+		}
+		l.fallback = p
+	}
+
+	return l, nil
+}
+
+// Policy Gets the policy of a route, nil when the route isn't limited.
+// The routes are configured without the api version, /v1/login has the policy of /login
+func (l *Limiter) Policy(method string, path string) *Policy {
+	if p, ok := l.routes[method+" "+path]; ok {
+		return p
+	}
+	if p, ok := l.routes[method+" "+unversioned(path)]; ok {
+		return p
+	}
+	return l.fallback
+}
+
+// unversioned Removes the api version prefix of a path, /v1/login => /login
+func unversioned(path string) string {
+	parts := strings.SplitN(path, "/", 3)
+	if len(parts) < 3 || len(parts[1]) < 2 || parts[1][0] != 'v' {
+		return path
+	}
+	if _, err := strconv.Atoi(parts[1][1:]); err != nil {
+		return path
+	}
+	return "/" + parts[2]
+}
+
+// Allow Takes a token for the key under the policy
+func (l *Limiter) Allow(p *Policy, key string, now time.Time) (bool, time.Duration, error) {
+	return l.store.Take(p.Name+":"+key, p, now)
+}
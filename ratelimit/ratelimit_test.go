@@ -0,0 +1,88 @@
+package ratelimit
+
+import (
+	cnf "github.com/pintobikez/popmeet/config/structures"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+/*
+Provider struct for Take method
+*/
+type providerTake struct {
+	requests int
+	after    time.Duration
+	allowed  bool
+	wait     time.Duration
+}
+
+var testProviderTake = []providerTake{
+	{1, 0, true, 0},                                // full bucket
+	{2, 0, true, 0},                                // burst
+	{3, 0, false, 30 * time.Second},                // empty bucket
+	{3, 30 * time.Second, true, 0},                 // refilled
+	{4, 15 * time.Second, false, 15 * time.Second}, // half a token
+}
+
+/* Test for Take method */
+func TestTake(t *testing.T) {
+
+	p := &Policy{Name: "test", Rate: 2.0 / 60, Burst: 2, Key: KeyIp}
+
+	for _, pair := range testProviderTake {
+
+		s := NewMemoryStore()
+		now := time.Date(2018, 1, 1, 10, 0, 0, 0, time.UTC)
+
+		for i := 1; i < pair.requests; i++ {
+			s.Take("key", p, now)
+		}
+		allowed, wait, err := s.Take("key", p, now.Add(pair.after))
+
+		// Assertions
+		assert.Nil(t, err)
+		assert.Equal(t, pair.allowed, allowed)
+		assert.Equal(t, pair.wait, wait)
+	}
+}
+
+/*
+Provider struct for New method
+*/
+type providerNew struct {
+	config *cnf.RateLimitConfig
+	iserro bool
+}
+
+var testProviderNew = []providerNew{
+	{DefaultConfig(), false}, // OK
+	{&cnf.RateLimitConfig{Policies: map[string]*cnf.RatePolicyConfig{"a": {PerMinute: 0, Burst: 1}}}, true},               // no rate
+	{&cnf.RateLimitConfig{Policies: map[string]*cnf.RatePolicyConfig{"a": {PerMinute: 1, Burst: 1, Key: "email"}}}, true}, // invalid key
+	{&cnf.RateLimitConfig{Routes: map[string]string{"POST /login": "a"}}, true},                                           // policy not found
+	{&cnf.RateLimitConfig{Default: "a"}, true},                                                                            // default not found
+}
+
+/* Test for New method */
+func TestNew(t *testing.T) {
+
+	for _, pair := range testProviderNew {
+
+		_, err := New(pair.config, NewMemoryStore())
+
+		// Assertions
+		assert.Equal(t, pair.iserro, (err != nil))
+	}
+}
+
+/* Test for Policy method */
+func TestPolicy(t *testing.T) {
+
+	l, _ := New(DefaultConfig(), NewMemoryStore())
+
+	// Assertions
+	assert.Equal(t, "auth", l.Policy("POST", "/login").Name)
+	assert.Nil(t, l.Policy("GET", "/login"))
+	assert.Equal(t, "auth", l.Policy("POST", "/v1/login").Name)
+	assert.Nil(t, l.Policy("POST", "/vx/login"))
+}
@@ -0,0 +1,201 @@
+package mysql
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/pintobikez/popmeet/api/models"
+	serror "github.com/pintobikez/popmeet/errors"
+	"time"
+)
+
+// ScheduleUserDeletion Sets when the account of a given user is erased and what happens to its hosted events,
+// ending all its sessions
+func (r *Client) ScheduleUserDeletion(id int64, at time.Time, hostedEvents string) error {
+	// a transaction of its own, the client is shared with the requests and the jobs
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec("UPDATE `user` SET deletion_at=?,deletion_events=?,updated_at=now() WHERE id=?", at, hostedEvents, id); err != nil {
+		return fmt.Errorf("Could not schedule the deletion of userID %d : %s", id, err.Error())
+	}
+
+	if _, err = tx.Exec("UPDATE `user_security` SET session_version=session_version+1,updated_at=now() WHERE fk_user=?", id); err != nil {
+		return fmt.Errorf("Could not end the sessions of userID %d : %s", id, err.Error())
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("Could not schedule the deletion of userID %d : %s", id, err.Error())
+	}
+
+	return nil
+}
+
+// CancelUserDeletion Keeps the account of a given user that was waiting for the erasure
+func (r *Client) CancelUserDeletion(id int64) error {
+
+	stmt, err := r.db.Prepare("UPDATE `user` SET deletion_at=NULL,deletion_events=NULL,updated_at=now() WHERE id=? AND erased_at IS NULL")
+	if err != nil {
+		return fmt.Errorf("Error in cancel user deletion prepared statement: %s", err.Error())
+	}
+
+	_, err = stmt.Exec(id)
+	defer stmt.Close()
+
+	if err != nil {
+		return fmt.Errorf("Could not cancel the deletion of userID %d : %s", id, err.Error())
+	}
+
+	return nil
+}
+
+// GetUsersToErase Gets the accounts whose grace period is over, the oldest first
+func (r *Client) GetUsersToErase(now time.Time, limit int) ([]*models.AccountErasure, error) {
+
+	resp := []*models.AccountErasure{}
+
+	rows, err := r.db.Query("SELECT id,IFNULL(deletion_events,?) FROM user WHERE deletion_at<=? AND erased_at IS NULL ORDER BY deletion_at LIMIT ?", models.HostedEventsCancel, now, limit)
+	if err != nil {
+		return resp, err
+	}
+
+	for rows.Next() {
+		var n = new(models.AccountErasure)
+
+		if err = rows.Scan(&n.UserID, &n.HostedEvents); err != nil {
+			defer rows.Close()
+			return resp, fmt.Errorf("Error reading rows: %s", err.Error())
+		}
+
+		resp = append(resp, n)
+	}
+
+	rows.Close()
+
+	return resp, nil
+}
+
+// EraseUser Removes the data of a given user and anonymizes its account. The account is kept for the
+// reports, moderation actions, past events and the messages received by the other users. The audit entries
+// are kept as the security record, without the ip and user agent of the user.
+// The upcoming events it hosts are transferred to one of their active attendees or cancelled, as given by
+// hostedEvents, and returned to notify the attendees.
+// Fails with not found when the account is no longer waiting for the erasure, kept by a login
+func (r *Client) EraseUser(id int64, hostedEvents string, now time.Time) ([]*models.HandedOverEvent, error) {
+	// a transaction of its own, the client is shared with the requests and the jobs
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// the account is anonymized first, locking it from a login that would keep it
+	res, err := tx.Exec("UPDATE `user` SET email=CONCAT('deleted-',id,'@erased.invalid'),name='Deleted user',active=0,role='user',email_verified_at=NULL,"+
+		"deletion_at=NULL,deletion_events=NULL,erased_at=now(),updated_at=now() WHERE id=? AND deletion_at IS NOT NULL AND deletion_at<=now() AND erased_at IS NULL", id)
+	if err != nil {
+		return nil, fmt.Errorf("Error erasing userID %d : %s", id, err.Error())
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, serror.ErrNotFound.WithDetail("User with id %d is not waiting for the erasure", id)
+	}
+
+	// the events are only handed over once the account can no longer be kept
+	evs, err := handOverEvents(tx, id, hostedEvents, now)
+	if err != nil {
+		return nil, err
+	}
+
+	queries := []struct {
+		query string
+		args  []interface{}
+	}{
+		{"DELETE FROM `event_users` WHERE fk_user=?", []interface{}{id}},
+		{"DELETE FROM `event_users_archive` WHERE fk_user=?", []interface{}{id}},
+		{"DELETE upi FROM `users_profile_interests` upi INNER JOIN user_profile up ON upi.fk_user_profile=up.id WHERE up.fk_user=?", []interface{}{id}},
+		{"DELETE upl FROM `user_profile_language` upl INNER JOIN user_profile up ON upl.fk_user_profile=up.id WHERE up.fk_user=?", []interface{}{id}},
+		{"DELETE FROM `user_profile` WHERE fk_user=?", []interface{}{id}},
+		{"DELETE FROM `user_token` WHERE fk_user=?", []interface{}{id}},
+		{"DELETE FROM `user_recovery_code` WHERE fk_user=?", []interface{}{id}},
+		{"DELETE FROM `notification` WHERE fk_user=?", []interface{}{id}},
+		{"DELETE FROM `notification_preference` WHERE fk_user=?", []interface{}{id}},
+		{"DELETE FROM `user_follow` WHERE fk_follower=? OR fk_followed=?", []interface{}{id, id}},
+		{"DELETE FROM `user_block` WHERE fk_blocker=? OR fk_blocked=?", []interface{}{id, id}},
+		{"DELETE FROM `message` WHERE fk_sender=?", []interface{}{id}},
+		{"UPDATE `user_security` SET hash='',totp_secret=NULL,totp_enabled_at=NULL,totp_last_step=NULL,failed_attempts=0,last_failed_at=NULL," +
+			"last_failed_machine=NULL,locked_until=NULL,last_machine='',session_version=session_version+1,updated_at=now() WHERE fk_user=?", []interface{}{id}},
+		{"UPDATE `audit_log` SET ip='',user_agent='' WHERE fk_actor=?", []interface{}{id}},
+	}
+
+	for _, q := range queries {
+		if _, err = tx.Exec(q.query, q.args...); err != nil {
+			return nil, fmt.Errorf("Error erasing userID %d : %s", id, err.Error())
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("Error erasing userID %d : %s", id, err.Error())
+	}
+
+	return evs, nil
+}
+
+// handOverEvents Transfers the upcoming events hosted by a given user to one of their active attendees, who
+// is no longer an attendee, or cancels them. Without attendees an event can't be transferred, so it is cancelled
+func handOverEvents(tx *sql.Tx, id int64, hostedEvents string, now time.Time) ([]*models.HandedOverEvent, error) {
+
+	resp := []*models.HandedOverEvent{}
+
+	rows, err := tx.Query("SELECT id,location,start_datetime FROM event WHERE fk_created_by=? AND active=1 AND start_datetime>? FOR UPDATE", id, now)
+	if err != nil {
+		return nil, err
+	}
+
+	for rows.Next() {
+		var ev = &models.HandedOverEvent{Event: new(models.Event)}
+
+		if err = rows.Scan(&ev.Event.ID, &ev.Event.Location, &ev.Event.StartDate); err != nil {
+			defer rows.Close()
+			return nil, fmt.Errorf("Error reading rows: %s", err.Error())
+		}
+
+		resp = append(resp, ev)
+	}
+
+	rows.Close()
+
+	for _, ev := range resp {
+
+		rows, err = tx.Query("SELECT eu.fk_user FROM event_users eu INNER JOIN user u ON eu.fk_user=u.id WHERE eu.fk_event=? AND u.active=1 ORDER BY eu.fk_user", ev.Event.ID)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var a int64
+			if err = rows.Scan(&a); err != nil {
+				defer rows.Close()
+				return nil, fmt.Errorf("Error reading rows: %s", err.Error())
+			}
+			ev.Attendees = append(ev.Attendees, a)
+		}
+		rows.Close()
+
+		if hostedEvents == models.HostedEventsTransfer && len(ev.Attendees) > 0 {
+			ev.NewHost = ev.Attendees[0]
+			if _, err = tx.Exec("UPDATE `event` SET fk_created_by=? WHERE id=?", ev.NewHost, ev.Event.ID); err != nil {
+				return nil, fmt.Errorf("Could not transfer eventID %d : %s", ev.Event.ID, err.Error())
+			}
+			if _, err = tx.Exec("DELETE FROM `event_users` WHERE fk_event=? AND fk_user=?", ev.Event.ID, ev.NewHost); err != nil {
+				return nil, fmt.Errorf("Could not transfer eventID %d : %s", ev.Event.ID, err.Error())
+			}
+			continue
+		}
+
+		if _, err = tx.Exec("UPDATE `event` SET active=0 WHERE id=?", ev.Event.ID); err != nil {
+			return nil, fmt.Errorf("Could not cancel eventID %d : %s", ev.Event.ID, err.Error())
+		}
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,30 @@
+package mysql
+
+import (
+	"github.com/pintobikez/popmeet/api/models"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+/* Test for EraseUser method, the account is claimed before its events are handed over */
+func TestEraseUserClaimsFirst(t *testing.T) {
+
+	r, err := newFakeClient()
+	assert.Nil(t, err)
+	fakeStatements()
+
+	_, err = r.EraseUser(1, models.HostedEventsTransfer, time.Now())
+	statements := fakeStatements()
+
+	// Assertions
+	assert.Nil(t, err)
+	if assert.True(t, len(statements) > 3) {
+		assert.Equal(t, "BEGIN", statements[0])
+		assert.Contains(t, statements[1], "deletion_at IS NOT NULL AND deletion_at<=now() AND erased_at IS NULL")
+		assert.True(t, strings.HasPrefix(statements[2], "SELECT id,location,start_datetime FROM event"))
+		assert.Contains(t, statements[2], "FOR UPDATE")
+		assert.Equal(t, "COMMIT", statements[len(statements)-1])
+	}
+}
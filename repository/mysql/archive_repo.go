@@ -0,0 +1,81 @@
+package mysql
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CompletePastEvents Marks as completed the events that already ended. Returns the number of events completed
+func (r *Client) CompletePastEvents(now time.Time) (int64, error) {
+
+	stmt, err := r.db.Prepare("UPDATE `event` SET completed_at=? WHERE completed_at IS NULL AND end_datetime<=?")
+	if err != nil {
+		return 0, fmt.Errorf("Error in complete events prepared statement: %s", err.Error())
+	}
+
+	res, err := stmt.Exec(now, now)
+	defer stmt.Close()
+
+	if err != nil {
+		return 0, fmt.Errorf("Could not complete past events : %s", err.Error())
+	}
+
+	return res.RowsAffected()
+}
+
+// archivedEvent The columns of an event copied into the archive, listed so both tables can change apart
+const archivedEvent = "id,created_at,start_datetime,end_datetime,location,latitude,longitude,active,fk_created_by,completed_at"
+
+// ArchiveEvents Moves up to limit events completed before the given date, and their users, into the archive tables.
+// Events with reports are kept for moderation. Returns the number of events archived
+func (r *Client) ArchiveEvents(before time.Time, limit int) (int64, error) {
+	var ids []interface{}
+
+	// a transaction of its own, the client is shared with the requests
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query("SELECT e.id FROM event e WHERE e.completed_at IS NOT NULL AND e.end_datetime<? "+
+		"AND NOT EXISTS (SELECT 1 FROM report rp WHERE rp.fk_event=e.id) LIMIT ? FOR UPDATE", before, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	for rows.Next() {
+		var id int64
+		if err = rows.Scan(&id); err != nil {
+			defer rows.Close()
+			return 0, fmt.Errorf("Error reading rows: %s", err.Error())
+		}
+		ids = append(ids, id)
+	}
+
+	rows.Close()
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	in := "(" + strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",") + ")"
+	queries := []string{
+		"INSERT INTO `event_archive` (" + archivedEvent + ",archived_at) SELECT " + archivedEvent + ",now() FROM event e WHERE e.id IN " + in,
+		"INSERT IGNORE INTO `event_users_archive` SELECT eu.fk_event,eu.fk_user FROM event_users eu WHERE eu.fk_event IN " + in,
+		"DELETE FROM `event_users` WHERE fk_event IN " + in,
+		"DELETE FROM `event` WHERE id IN " + in,
+	}
+
+	for _, q := range queries {
+		if _, err = tx.Exec(q, ids...); err != nil {
+			return 0, fmt.Errorf("Error archiving events: %s", err.Error())
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("Error archiving events: %s", err.Error())
+	}
+
+	return int64(len(ids)), nil
+}
@@ -0,0 +1,86 @@
+package mysql
+
+import (
+	"fmt"
+	"github.com/pintobikez/popmeet/api/models"
+	"strings"
+)
+
+// InsertAuditEntry Appends an entry to the audit log, the entries are never updated or deleted
+func (r *Client) InsertAuditEntry(e *models.AuditEntry) error {
+
+	stmt, err := r.db.Prepare("INSERT INTO `audit_log` (action,fk_user,fk_actor,target,target_id,ip,user_agent,request_id,created_at) VALUES (?,?,?,?,?,?,?,?,?)")
+	if err != nil {
+		return fmt.Errorf("Error in insert audit entry prepared statement: %s", err.Error())
+	}
+
+	var target interface{}
+	if e.Target != "" {
+		target = e.Target
+	}
+
+	res, err := stmt.Exec(e.Action, nullInt64(e.UserID), nullInt64(e.ActorID), target, nullInt64(e.TargetID), e.IP, e.UserAgent, e.RequestID, e.CreatedAt)
+	defer stmt.Close()
+
+	if err != nil {
+		return fmt.Errorf("Error in insert audit entry %s: %s", e.Action, err.Error())
+	}
+
+	e.ID, _ = res.LastInsertId()
+
+	return nil
+}
+
+// GetAuditEntries Gets the entries of the audit log matching the filter, newest first
+func (r *Client) GetAuditEntries(f *models.AuditFilter) ([]*models.AuditEntry, error) {
+
+	resp := []*models.AuditEntry{}
+
+	var where []string
+	var args []interface{}
+	if f.UserID > 0 {
+		where, args = append(where, "fk_user=?"), append(args, f.UserID)
+	}
+	if f.ActorID > 0 {
+		where, args = append(where, "fk_actor=?"), append(args, f.ActorID)
+	}
+	if f.Action != "" {
+		where, args = append(where, "action=?"), append(args, f.Action)
+	}
+	if f.From != nil {
+		where, args = append(where, "created_at>=?"), append(args, *f.From)
+	}
+	if f.To != nil {
+		where, args = append(where, "created_at<?"), append(args, *f.To)
+	}
+	if f.Before > 0 {
+		where, args = append(where, "id<?"), append(args, f.Before)
+	}
+
+	query := "SELECT id,action,IFNULL(fk_user,0),IFNULL(fk_actor,0),IFNULL(target,''),IFNULL(target_id,0),ip,user_agent,request_id,created_at FROM audit_log"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY id DESC LIMIT ?"
+
+	rows, err := r.db.Query(query, append(args, f.Limit)...)
+	if err != nil {
+		return resp, err
+	}
+
+	for rows.Next() {
+		var n = new(models.AuditEntry)
+
+		err = rows.Scan(&n.ID, &n.Action, &n.UserID, &n.ActorID, &n.Target, &n.TargetID, &n.IP, &n.UserAgent, &n.RequestID, &n.CreatedAt)
+		if err != nil {
+			defer rows.Close()
+			return resp, fmt.Errorf("Error reading rows: %s", err.Error())
+		}
+
+		resp = append(resp, n)
+	}
+
+	rows.Close()
+
+	return resp, nil
+}
@@ -0,0 +1,116 @@
+package mysql
+
+import (
+	"fmt"
+	"github.com/pintobikez/popmeet/api/models"
+)
+
+// IsUserBlocked Checks if any of the given users has blocked the other one
+func (r *Client) IsUserBlocked(idUser int64, idOther int64) (bool, error) {
+
+	var found bool
+	err := r.db.QueryRow("SELECT IF(COUNT(*),'true','false') FROM user_block WHERE (fk_blocker=? AND fk_blocked=?) OR (fk_blocker=? AND fk_blocked=?)", idUser, idOther, idOther, idUser).Scan(&found)
+	if err != nil {
+		return false, err
+	}
+
+	return found, nil
+}
+
+// BlockUser Inserts a block of idBlocked by idUser into the user_block table and removes the follows between them
+func (r *Client) BlockUser(idUser int64, idBlocked int64) error {
+
+	// the block and the follows it removes change together, apart from the requests sharing the client
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec("INSERT IGNORE INTO `user_block` VALUES (?,?,now())", idUser, idBlocked); err != nil {
+		return fmt.Errorf("Error blocking user %d by user %d - %w", idBlocked, idUser, typed(err))
+	}
+
+	_, err = tx.Exec("DELETE FROM `user_follow` WHERE (fk_follower=? AND fk_followed=?) OR (fk_follower=? AND fk_followed=?)", idUser, idBlocked, idBlocked, idUser)
+	if err != nil {
+		return fmt.Errorf("Error removing the follows between user %d and user %d - %s", idUser, idBlocked, err.Error())
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("Error blocking user %d by user %d - %s", idBlocked, idUser, err.Error())
+	}
+
+	return nil
+}
+
+// UnblockUser Removes the block of idBlocked by idUser from the user_block table
+func (r *Client) UnblockUser(idUser int64, idBlocked int64) error {
+
+	stmt, err := r.db.Prepare("DELETE FROM `user_block` WHERE fk_blocker=? AND fk_blocked=?")
+	if err != nil {
+		return fmt.Errorf("Error in unblock user prepared statement: %s", err.Error())
+	}
+
+	_, err = stmt.Exec(idUser, idBlocked)
+	defer stmt.Close()
+
+	if err != nil {
+		return fmt.Errorf("Error unblocking user %d by user %d - %s", idBlocked, idUser, err.Error())
+	}
+
+	return nil
+}
+
+// GetBlockedUsersByUserId Gets the users blocked by a given user
+func (r *Client) GetBlockedUsersByUserId(id int64) ([]*models.User, error) {
+
+	resp := []*models.User{}
+
+	rows, err := r.db.Query("SELECT u.id,u.name FROM user_block ub INNER JOIN user u ON ub.fk_blocked=u.id WHERE ub.fk_blocker=? ORDER BY ub.created_at DESC", id)
+	if err != nil {
+		return resp, err
+	}
+
+	for rows.Next() {
+		var u = new(models.User)
+
+		err = rows.Scan(&u.ID, &u.Name)
+		if err != nil {
+			defer rows.Close()
+			return resp, fmt.Errorf("Error reading rows: %s", err.Error())
+		}
+
+		resp = append(resp, u)
+	}
+
+	rows.Close()
+
+	return resp, nil
+}
+
+// GetBlockRelatedUserIds Gets the ids of the users that blocked or were blocked by a given user
+func (r *Client) GetBlockRelatedUserIds(id int64) ([]int64, error) {
+
+	var resp []int64
+
+	rows, err := r.db.Query("SELECT fk_blocked FROM user_block WHERE fk_blocker=? UNION SELECT fk_blocker FROM user_block WHERE fk_blocked=?", id, id)
+	if err != nil {
+		return resp, err
+	}
+
+	for rows.Next() {
+		var n int64
+
+		err = rows.Scan(&n)
+		if err != nil {
+			defer rows.Close()
+			return resp, fmt.Errorf("Error reading rows: %s", err.Error())
+		}
+
+		resp = append(resp, n)
+	}
+
+	rows.Close()
+
+	return resp, nil
+}
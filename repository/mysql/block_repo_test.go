@@ -0,0 +1,26 @@
+package mysql
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+/* Test for BlockUser method, the follows between both users are removed with the block */
+func TestBlockUser(t *testing.T) {
+
+	r, err := newFakeClient()
+	assert.Nil(t, err)
+	fakeStatements()
+
+	err = r.BlockUser(1, 2)
+	statements := fakeStatements()
+
+	// Assertions
+	assert.Nil(t, err)
+	if assert.Len(t, statements, 4) {
+		assert.Equal(t, "BEGIN", statements[0])
+		assert.Contains(t, statements[1], "INSERT IGNORE INTO `user_block`")
+		assert.Contains(t, statements[2], "DELETE FROM `user_follow`")
+		assert.Equal(t, "COMMIT", statements[3])
+	}
+}
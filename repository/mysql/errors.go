@@ -0,0 +1,35 @@
+package mysql
+
+import (
+	"database/sql"
+	driver "github.com/go-sql-driver/mysql"
+	serror "github.com/pintobikez/popmeet/errors"
+)
+
+const (
+	errDuplicateEntry  = 1062
+	errRowIsReferenced = 1451
+	errNoReferencedRow = 1452
+
+	// the changes already made by a migration that stopped midway
+	errTableExists     = 1050
+	errDuplicateColumn = 1060
+	errDuplicateKey    = 1061
+	errCantDropKey     = 1091
+	errDuplicateFk     = 1826
+)
+
+// typed Converts the errors of the mysql driver to the errors of the catalog.
+// Duplicated keys and broken references are conflicts, the missing rows are not found
+func typed(err error) error {
+	if err == sql.ErrNoRows {
+		return serror.ErrNotFound.Wrap(err)
+	}
+	if me, ok := err.(*driver.MySQLError); ok {
+		switch me.Number {
+		case errDuplicateEntry, errRowIsReferenced, errNoReferencedRow:
+			return serror.ErrConflict.Wrap(err)
+		}
+	}
+	return err
+}
@@ -0,0 +1,73 @@
+package mysql
+
+import (
+	"fmt"
+	"github.com/pintobikez/popmeet/api/models"
+)
+
+const exportEventColumns = "e.id,e.created_at,e.start_datetime,e.end_datetime,e.location,e.latitude,e.longitude,e.active,e.completed_at"
+
+// GetHostedEventsByUserId Gets all the events created by a given user, the archived ones included
+func (r *Client) GetHostedEventsByUserId(id int64) ([]*models.Event, error) {
+	return r.queryExportEvents("SELECT "+exportEventColumns+" FROM event e WHERE e.fk_created_by=? "+
+		"UNION ALL SELECT "+exportEventColumns+" FROM event_archive e WHERE e.fk_created_by=? ORDER BY start_datetime", id, id)
+}
+
+// GetJoinedEventsByUserId Gets all the events joined by a given user, the archived ones included
+func (r *Client) GetJoinedEventsByUserId(id int64) ([]*models.Event, error) {
+	return r.queryExportEvents("SELECT "+exportEventColumns+" FROM event e INNER JOIN event_users eu ON eu.fk_event=e.id WHERE eu.fk_user=? "+
+		"UNION ALL SELECT "+exportEventColumns+" FROM event_archive e INNER JOIN event_users_archive eu ON eu.fk_event=e.id WHERE eu.fk_user=? ORDER BY start_datetime", id, id)
+}
+
+func (r *Client) queryExportEvents(query string, args ...interface{}) ([]*models.Event, error) {
+
+	resp := []*models.Event{}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return resp, err
+	}
+
+	for rows.Next() {
+		var ev = new(models.Event)
+
+		err = rows.Scan(&ev.ID, &ev.CreatedAt, &ev.StartDate, &ev.EndDate, &ev.Location, &ev.Latitude, &ev.Longitude, &ev.Active, &ev.CompletedAt)
+		if err != nil {
+			defer rows.Close()
+			return resp, fmt.Errorf("Error reading rows: %s", err.Error())
+		}
+
+		resp = append(resp, ev)
+	}
+
+	rows.Close()
+
+	return resp, nil
+}
+
+// GetMessagesByUserId Gets all the messages sent and received by a given user, oldest first
+func (r *Client) GetMessagesByUserId(id int64) ([]*models.Message, error) {
+
+	resp := []*models.Message{}
+
+	rows, err := r.db.Query("SELECT id,fk_sender,fk_recipient,body,created_at,read_at FROM message WHERE fk_sender=? OR fk_recipient=? ORDER BY id", id, id)
+	if err != nil {
+		return resp, err
+	}
+
+	for rows.Next() {
+		var m = new(models.Message)
+
+		err = rows.Scan(&m.ID, &m.Sender, &m.Recipient, &m.Body, &m.CreatedAt, &m.ReadAt)
+		if err != nil {
+			defer rows.Close()
+			return resp, fmt.Errorf("Error reading rows: %s", err.Error())
+		}
+
+		resp = append(resp, m)
+	}
+
+	rows.Close()
+
+	return resp, nil
+}
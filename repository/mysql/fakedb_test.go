@@ -0,0 +1,114 @@
+package mysql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"sync"
+)
+
+// fakeDriver is a database that accepts every statement, each one changes a row and the queries find none.
+// It lets the tests use a real Client, to check with the race detector how it shares the connections
+type fakeDriver struct{}
+
+func init() {
+	sql.Register("popmeet-fake", &fakeDriver{})
+}
+
+// fakeLog is the statements run on the fake database, with the begin, commit and rollback of the transactions
+var fakeLog struct {
+	sync.Mutex
+	statements []string
+}
+
+// logFake Appends a statement to the fakeLog
+func logFake(statement string) {
+	fakeLog.Lock()
+	fakeLog.statements = append(fakeLog.statements, statement)
+	fakeLog.Unlock()
+}
+
+// fakeStatements Gets the statements run since the last call
+func fakeStatements() []string {
+	fakeLog.Lock()
+	defer fakeLog.Unlock()
+	statements := fakeLog.statements
+	fakeLog.statements = nil
+	return statements
+}
+
+// newFakeClient Creates a Client on the fake database
+func newFakeClient() (*Client, error) {
+	db, err := sql.Open("popmeet-fake", "")
+	if err != nil {
+		return nil, err
+	}
+	return &Client{db: db}, nil
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) {
+	return &fakeConn{}, nil
+}
+
+type fakeConn struct{}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{query: query}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	logFake("BEGIN")
+	return &fakeTx{}, nil
+}
+
+type fakeTx struct{}
+
+func (t *fakeTx) Commit() error {
+	logFake("COMMIT")
+	return nil
+}
+
+func (t *fakeTx) Rollback() error {
+	logFake("ROLLBACK")
+	return nil
+}
+
+type fakeStmt struct {
+	query string
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	logFake(s.query)
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	logFake(s.query)
+	return &fakeRows{}, nil
+}
+
+type fakeRows struct{}
+
+func (r *fakeRows) Columns() []string {
+	return []string{}
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	return io.EOF
+}
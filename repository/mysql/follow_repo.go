@@ -0,0 +1,150 @@
+package mysql
+
+import (
+	"fmt"
+	"github.com/pintobikez/popmeet/api/models"
+)
+
+// FollowUser Inserts a follow of idFollowed by idUser into the user_follow table
+func (r *Client) FollowUser(idUser int64, idFollowed int64) error {
+
+	stmt, err := r.db.Prepare("INSERT IGNORE INTO `user_follow` VALUES (?,?,now())")
+	if err != nil {
+		return fmt.Errorf("Error in follow user prepared statement: %s", err.Error())
+	}
+
+	_, err = stmt.Exec(idUser, idFollowed)
+	defer stmt.Close()
+
+	if err != nil {
+		return fmt.Errorf("Error following user %d by user %d - %w", idFollowed, idUser, typed(err))
+	}
+
+	return nil
+}
+
+// UnfollowUser Removes the follow of idFollowed by idUser from the user_follow table
+func (r *Client) UnfollowUser(idUser int64, idFollowed int64) error {
+
+	stmt, err := r.db.Prepare("DELETE FROM `user_follow` WHERE fk_follower=? AND fk_followed=?")
+	if err != nil {
+		return fmt.Errorf("Error in unfollow user prepared statement: %s", err.Error())
+	}
+
+	_, err = stmt.Exec(idUser, idFollowed)
+	defer stmt.Close()
+
+	if err != nil {
+		return fmt.Errorf("Error unfollowing user %d by user %d - %s", idFollowed, idUser, err.Error())
+	}
+
+	return nil
+}
+
+// GetFollowersByUserId Gets the active users following a given user
+func (r *Client) GetFollowersByUserId(id int64) ([]*models.User, error) {
+	return r.queryFollowUsers("SELECT u.id,u.name FROM user_follow uf INNER JOIN user u ON uf.fk_follower=u.id WHERE uf.fk_followed=? AND u.active=1 ORDER BY uf.created_at DESC", id)
+}
+
+// GetFollowingByUserId Gets the active users followed by a given user
+func (r *Client) GetFollowingByUserId(id int64) ([]*models.User, error) {
+	return r.queryFollowUsers("SELECT u.id,u.name FROM user_follow uf INNER JOIN user u ON uf.fk_followed=u.id WHERE uf.fk_follower=? AND u.active=1 ORDER BY uf.created_at DESC", id)
+}
+
+// GetFollowerIdsByUserId Gets the ids of the active users following a given user, without a block between them
+func (r *Client) GetFollowerIdsByUserId(id int64) ([]int64, error) {
+
+	var resp []int64
+
+	rows, err := r.db.Query("SELECT uf.fk_follower FROM user_follow uf INNER JOIN user u ON uf.fk_follower=u.id WHERE uf.fk_followed=? AND u.active=1 "+
+		"AND NOT EXISTS (SELECT 1 FROM user_block ub WHERE (ub.fk_blocker=? AND ub.fk_blocked=u.id) OR (ub.fk_blocker=u.id AND ub.fk_blocked=?))", id, id, id)
+	if err != nil {
+		return resp, err
+	}
+
+	for rows.Next() {
+		var n int64
+
+		err = rows.Scan(&n)
+		if err != nil {
+			defer rows.Close()
+			return resp, fmt.Errorf("Error reading rows: %s", err.Error())
+		}
+
+		resp = append(resp, n)
+	}
+
+	rows.Close()
+
+	return resp, nil
+}
+
+// AreMutualFollowers Checks if both users follow each other
+func (r *Client) AreMutualFollowers(idUser int64, idOther int64) (bool, error) {
+
+	var found bool
+	err := r.db.QueryRow("SELECT IF(COUNT(*)=2,'true','false') FROM user_follow WHERE (fk_follower=? AND fk_followed=?) OR (fk_follower=? AND fk_followed=?)", idUser, idOther, idOther, idUser).Scan(&found)
+	if err != nil {
+		return false, err
+	}
+
+	return found, nil
+}
+
+// GetFeedEventsByUserId Gets the upcoming active events created by the users followed by a given user, ordered by start date
+func (r *Client) GetFeedEventsByUserId(id int64, limit int) ([]*models.Event, error) {
+
+	evs := []*models.Event{}
+
+	rows, err := r.db.Query("SELECT e.id,e.created_at,e.start_datetime,e.end_datetime,e.location,e.latitude,e.longitude,e.active,u.id,u.name "+
+		"FROM user_follow uf INNER JOIN event e ON e.fk_created_by=uf.fk_followed INNER JOIN user u ON e.fk_created_by=u.id "+
+		"WHERE uf.fk_follower=? AND e.active=1 AND u.active=1 AND e.start_datetime>now() "+
+		"AND NOT EXISTS (SELECT 1 FROM user_block ub WHERE (ub.fk_blocker=? AND ub.fk_blocked=u.id) OR (ub.fk_blocker=u.id AND ub.fk_blocked=?)) "+
+		"ORDER BY e.start_datetime ASC LIMIT ?", id, id, id, limit)
+	if err != nil {
+		return evs, err
+	}
+
+	for rows.Next() {
+		var ev = &models.Event{CreatedBy: new(models.User)}
+
+		err = rows.Scan(&ev.ID, &ev.CreatedAt, &ev.StartDate, &ev.EndDate, &ev.Location, &ev.Latitude, &ev.Longitude, &ev.Active, &ev.CreatedBy.ID, &ev.CreatedBy.Name)
+		if err != nil {
+			defer rows.Close()
+			return evs, fmt.Errorf("Error reading rows: %s", err.Error())
+		}
+
+		evs = append(evs, ev)
+	}
+
+	rows.Close()
+
+	return evs, nil
+}
+
+// queryFollowUsers runs a query returning the id and name of users
+func (r *Client) queryFollowUsers(query string, id int64) ([]*models.User, error) {
+
+	resp := []*models.User{}
+
+	rows, err := r.db.Query(query, id)
+	if err != nil {
+		return resp, err
+	}
+
+	for rows.Next() {
+		var u = new(models.User)
+
+		err = rows.Scan(&u.ID, &u.Name)
+		if err != nil {
+			defer rows.Close()
+			return resp, fmt.Errorf("Error reading rows: %s", err.Error())
+		}
+
+		resp = append(resp, u)
+	}
+
+	rows.Close()
+
+	return resp, nil
+}
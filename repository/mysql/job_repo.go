@@ -0,0 +1,201 @@
+package mysql
+
+import (
+	"fmt"
+	"github.com/pintobikez/popmeet/api/models"
+	"time"
+)
+
+// InsertJob Inserts a pending job, ignoring it when a job of the same type already exists for the reference
+func (r *Client) InsertJob(j *models.Job) error {
+
+	stmt, err := r.db.Prepare("INSERT IGNORE INTO `job` (type,ref_id,run_at,status,created_at,updated_at) VALUES (?,?,?,?,now(),now())")
+	if err != nil {
+		return fmt.Errorf("Error in insert job prepared statement: %s", err.Error())
+	}
+
+	res, err := stmt.Exec(j.Type, j.RefID, j.RunAt, models.JobStatusPending)
+	defer stmt.Close()
+
+	if err != nil {
+		return fmt.Errorf("Error in insert job %s for reference %d: %s", j.Type, j.RefID, err.Error())
+	}
+
+	j.ID, _ = res.LastInsertId()
+	j.Status = models.JobStatusPending
+
+	return nil
+}
+
+// ScheduleEventReminders Inserts a reminder job, running the given duration before the start date,
+// for each active event whose reminder falls in the window around now.
+// The pending reminders follow the start date of their event whatever the window, the ones of the events
+// cancelled, started or moved too close for the reminder are removed
+func (r *Client) ScheduleEventReminders(tp string, before time.Duration, now time.Time, window time.Duration) error {
+
+	// the reminders change together, the client is shared with the requests
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	secs := int64(before / time.Second)
+
+	_, err = tx.Exec("DELETE j FROM `job` j LEFT JOIN event e ON e.id=j.ref_id "+
+		"WHERE j.type=? AND j.status=? AND (e.id IS NULL OR e.active=0 OR e.start_datetime<=? OR DATE_SUB(e.start_datetime,INTERVAL ? SECOND)<?)",
+		tp, models.JobStatusPending, now, secs, now.Add(-window))
+	if err != nil {
+		return fmt.Errorf("Error removing the stale %s reminders: %s", tp, err.Error())
+	}
+
+	_, err = tx.Exec("UPDATE `job` j INNER JOIN event e ON e.id=j.ref_id "+
+		"SET j.run_at=DATE_SUB(e.start_datetime,INTERVAL ? SECOND),j.updated_at=now() "+
+		"WHERE j.type=? AND j.status=? AND j.run_at<>DATE_SUB(e.start_datetime,INTERVAL ? SECOND)",
+		secs, tp, models.JobStatusPending, secs)
+	if err != nil {
+		return fmt.Errorf("Error moving the %s reminders: %s", tp, err.Error())
+	}
+
+	_, err = tx.Exec("INSERT IGNORE INTO `job` (type,ref_id,run_at,status,created_at,updated_at) "+
+		"SELECT ?,e.id,DATE_SUB(e.start_datetime,INTERVAL ? SECOND),?,now(),now() FROM event e "+
+		"WHERE e.active=1 AND e.start_datetime>? AND DATE_SUB(e.start_datetime,INTERVAL ? SECOND) BETWEEN ? AND ?",
+		tp, secs, models.JobStatusPending, now, secs, now.Add(-window), now.Add(window))
+	if err != nil {
+		return fmt.Errorf("Error scheduling %s reminders: %s", tp, err.Error())
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("Error scheduling %s reminders: %s", tp, err.Error())
+	}
+
+	return nil
+}
+
+// ClaimDueJobs Marks as running by the owner until the lease ends and returns the pending jobs due at the given time
+func (r *Client) ClaimDueJobs(owner string, now time.Time, lease time.Time, limit int) ([]*models.Job, error) {
+	resp := []*models.Job{}
+
+	// a transaction of its own, the client is shared with the requests
+	tx, err := r.db.Begin()
+	if err != nil {
+		return resp, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query("SELECT id,type,ref_id,run_at,status,attempts,last_error FROM job WHERE status=? AND run_at<=? ORDER BY run_at ASC LIMIT ? FOR UPDATE", models.JobStatusPending, now, limit)
+	if err != nil {
+		return resp, err
+	}
+
+	for rows.Next() {
+		var j = new(models.Job)
+
+		err = rows.Scan(&j.ID, &j.Type, &j.RefID, &j.RunAt, &j.Status, &j.Attempts, &j.LastError)
+		if err != nil {
+			defer rows.Close()
+			return []*models.Job{}, fmt.Errorf("Error reading rows: %s", err.Error())
+		}
+
+		resp = append(resp, j)
+	}
+
+	rows.Close()
+
+	for _, j := range resp {
+		if _, err = tx.Exec("UPDATE `job` SET status=?,owner=?,lease_until=?,attempts=attempts+1,updated_at=now() WHERE id=?", models.JobStatusRunning, owner, lease, j.ID); err != nil {
+			return []*models.Job{}, fmt.Errorf("Could not claim jobID %d : %s", j.ID, err.Error())
+		}
+		j.Status = models.JobStatusRunning
+		j.Attempts++
+	}
+
+	if err = tx.Commit(); err != nil {
+		return []*models.Job{}, fmt.Errorf("Could not claim the jobs : %s", err.Error())
+	}
+
+	return resp, nil
+}
+
+// CompleteJob Marks a job as done
+func (r *Client) CompleteJob(id int64) error {
+
+	stmt, err := r.db.Prepare("UPDATE `job` SET status=?,owner=NULL,lease_until=NULL,last_error='',updated_at=now() WHERE id=?")
+	if err != nil {
+		return fmt.Errorf("Error in complete job prepared statement: %s", err.Error())
+	}
+
+	_, err = stmt.Exec(models.JobStatusDone, id)
+	defer stmt.Close()
+
+	if err != nil {
+		return fmt.Errorf("Could not complete jobID %d : %s", id, err.Error())
+	}
+
+	return nil
+}
+
+// FailJob Records the error of a job and sets it to run again at retryAt, or as failed when final
+func (r *Client) FailJob(id int64, msg string, retryAt time.Time, final bool) error {
+
+	status := models.JobStatusPending
+	if final {
+		status = models.JobStatusFailed
+	}
+
+	stmt, err := r.db.Prepare("UPDATE `job` SET status=?,owner=NULL,lease_until=NULL,run_at=?,last_error=?,updated_at=now() WHERE id=?")
+	if err != nil {
+		return fmt.Errorf("Error in fail job prepared statement: %s", err.Error())
+	}
+
+	if len(msg) > 1000 {
+		msg = msg[:1000]
+	}
+
+	_, err = stmt.Exec(status, retryAt, msg, id)
+	defer stmt.Close()
+
+	if err != nil {
+		return fmt.Errorf("Could not fail jobID %d : %s", id, err.Error())
+	}
+
+	return nil
+}
+
+// RenewJobLease Extends the lease of a running job of the owner. Returns false when the job is no longer
+// running for the owner, its lease ended and it was given back
+func (r *Client) RenewJobLease(id int64, owner string, lease time.Time) (bool, error) {
+
+	_, err := r.db.Exec("UPDATE `job` SET lease_until=?,updated_at=now() WHERE id=? AND owner=? AND status=?", lease, id, owner, models.JobStatusRunning)
+	if err != nil {
+		return false, fmt.Errorf("Could not renew the lease of jobID %d : %s", id, err.Error())
+	}
+
+	// the rows affected are 0 when the lease is unchanged, the owner is read instead
+	var owned bool
+	err = r.db.QueryRow("SELECT IF(COUNT(*),'true','false') FROM job WHERE id=? AND owner=? AND status=?", id, owner, models.JobStatusRunning).Scan(&owned)
+	if err != nil {
+		return false, fmt.Errorf("Could not read the owner of jobID %d : %s", id, err.Error())
+	}
+
+	return owned, nil
+}
+
+// ResetExpiredJobs Sets back to pending the running jobs whose lease ended before now, their owner stopped.
+// Returns the number of jobs reset
+func (r *Client) ResetExpiredJobs(now time.Time) (int64, error) {
+
+	stmt, err := r.db.Prepare("UPDATE `job` SET status=?,owner=NULL,lease_until=NULL,updated_at=now() WHERE status=? AND (lease_until IS NULL OR lease_until<?)")
+	if err != nil {
+		return 0, fmt.Errorf("Error in reset jobs prepared statement: %s", err.Error())
+	}
+
+	res, err := stmt.Exec(models.JobStatusPending, models.JobStatusRunning, now)
+	defer stmt.Close()
+
+	if err != nil {
+		return 0, fmt.Errorf("Could not reset the expired jobs : %s", err.Error())
+	}
+
+	return res.RowsAffected()
+}
@@ -0,0 +1,56 @@
+package mysql
+
+import (
+	"github.com/pintobikez/popmeet/api/models"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+/* Test for ClaimDueJobs method, the runner claims the jobs while the requests use the same client */
+func TestClaimDueJobsConcurrent(t *testing.T) {
+
+	r, err := newFakeClient()
+	assert.Nil(t, err)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			_, err := r.ClaimDueJobs("test", time.Now(), time.Now().Add(time.Minute), 10)
+			assert.Nil(t, err)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			u := &models.User{Name: "test", Email: "test@popmeet.com", Security: &models.UserSecurity{Provider: &models.LoginProvider{ID: 1}}}
+			assert.Nil(t, r.InsertUser(u))
+		}
+	}()
+	wg.Wait()
+}
+
+/* Test for ScheduleEventReminders method, the pending reminders follow their event whatever the window */
+func TestScheduleEventReminders(t *testing.T) {
+
+	r, err := newFakeClient()
+	assert.Nil(t, err)
+	fakeStatements()
+
+	err = r.ScheduleEventReminders("event_reminder_1h", time.Hour, time.Now(), time.Hour)
+	statements := fakeStatements()
+
+	// Assertions
+	assert.Nil(t, err)
+	if assert.Len(t, statements, 5) {
+		assert.Equal(t, "BEGIN", statements[0])
+		assert.Contains(t, statements[1], "DELETE j FROM `job` j")
+		assert.NotContains(t, statements[2], "BETWEEN")
+		assert.Contains(t, statements[2], "SET j.run_at=")
+		assert.Contains(t, statements[3], "INSERT IGNORE INTO `job`")
+		assert.Equal(t, "COMMIT", statements[4])
+	}
+}
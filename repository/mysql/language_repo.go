@@ -0,0 +1,95 @@
+package mysql
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/pintobikez/popmeet/api/models"
+)
+
+// SetLanguageActive Enables or disables a language, the disabled ones can't be chosen by the users
+func (r *Client) SetLanguageActive(id int64, active bool) error {
+
+	stmt, err := r.db.Prepare("UPDATE `language` SET active=? WHERE id=?")
+	if err != nil {
+		return fmt.Errorf("Error in update language prepared statement: %s", err.Error())
+	}
+
+	_, err = stmt.Exec(active, id)
+	defer stmt.Close()
+
+	if err != nil {
+		return fmt.Errorf("Error in update language %d: %s", id, err.Error())
+	}
+
+	return nil
+}
+
+// updateSpokenLanguages Replaces the languages spoken by the user of a profile
+func (r *Client) updateSpokenLanguages(languages []*models.SpokenLanguage, id int64) error {
+	var err error
+	var stmtd *sql.Stmt
+	var stmti *sql.Stmt
+
+	if r.tx != nil {
+		stmtd, err = r.tx.Prepare("DELETE FROM `user_profile_language` WHERE fk_user_profile=?")
+	} else {
+		stmtd, err = r.db.Prepare("DELETE FROM `user_profile_language` WHERE fk_user_profile=?")
+	}
+	if err != nil {
+		return fmt.Errorf("Error in deleting user languages prepared statement: %s", err.Error())
+	}
+	defer stmtd.Close()
+
+	if _, err = stmtd.Exec(id); err != nil {
+		return fmt.Errorf("Error in deleting user languages for profile %d : %s", id, err.Error())
+	}
+
+	if len(languages) == 0 {
+		return nil
+	}
+
+	if r.tx != nil {
+		stmti, err = r.tx.Prepare("INSERT INTO `user_profile_language` (fk_user_profile,fk_language,proficiency) VALUES (?,?,?)")
+	} else {
+		stmti, err = r.db.Prepare("INSERT INTO `user_profile_language` (fk_user_profile,fk_language,proficiency) VALUES (?,?,?)")
+	}
+	if err != nil {
+		return fmt.Errorf("Error in inserting user languages prepared statement: %s", err.Error())
+	}
+	defer stmti.Close()
+
+	for _, l := range languages {
+		if _, err = stmti.Exec(id, l.Language.ID, l.Proficiency); err != nil {
+			return fmt.Errorf("Error in inserting user languages for profile %d : %w", id, typed(err))
+		}
+	}
+
+	return nil
+}
+
+// getSpokenLanguages Gets the languages spoken by the user of a profile
+func (r *Client) getSpokenLanguages(id int64) ([]*models.SpokenLanguage, error) {
+
+	var resp []*models.SpokenLanguage
+
+	rows, err := r.db.Query("SELECT la.id,la.name,la.name_iso2,la.name_iso3,la.active,upl.proficiency FROM user_profile_language upl INNER JOIN language la ON upl.fk_language=la.id WHERE upl.fk_user_profile=? ORDER BY la.name", id)
+	if err != nil {
+		return resp, err
+	}
+
+	for rows.Next() {
+		var n = &models.SpokenLanguage{Language: new(models.Language)}
+
+		err = rows.Scan(&n.Language.ID, &n.Language.Name, &n.Language.NameIso2, &n.Language.NameIso3, &n.Language.Active, &n.Proficiency)
+		if err != nil {
+			defer rows.Close()
+			return resp, fmt.Errorf("Error reading rows: %s", err.Error())
+		}
+
+		resp = append(resp, n)
+	}
+
+	rows.Close()
+
+	return resp, nil
+}
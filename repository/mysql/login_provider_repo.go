@@ -0,0 +1,214 @@
+package mysql
+
+import (
+	"fmt"
+	"github.com/pintobikez/popmeet/api/models"
+	serror "github.com/pintobikez/popmeet/errors"
+)
+
+// Sealer encrypts the secrets stored in the database
+type Sealer interface {
+	Seal(secret string) (string, error)
+	Open(sealed string) (string, error)
+	// Current Checks if the value is sealed with the current key
+	Current(value string) bool
+}
+
+const selectLoginProvider = "SELECT id,name,web_clientid,web_secret,android_clientid,android_secret,iphone_clientid,iphone_secret,secrets_rotated_at,updated_at FROM login_provider"
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanLoginProvider Reads a login provider with its secrets still sealed
+func scanLoginProvider(row scanner) (*models.LoginProvider, error) {
+	n := new(models.LoginProvider)
+	err := row.Scan(&n.ID, &n.Name, &n.WebClientid, &n.WebSecret, &n.AndroidClientid, &n.AndroidSecret, &n.IphoneClientid, &n.IphoneSecret, &n.SecretsRotatedAt, &n.UpdatedAt)
+	return n, err
+}
+
+// secrets The secrets of a login provider, to seal or open them all
+func secrets(p *models.LoginProvider) []*string {
+	return []*string{&p.WebSecret, &p.AndroidSecret, &p.IphoneSecret}
+}
+
+// openSecrets Decrypts the secrets of a login provider read from the database
+func (r *Client) openSecrets(p *models.LoginProvider) error {
+	if r.sealer == nil {
+		return fmt.Errorf("The secrets sealer is not set")
+	}
+	for _, s := range secrets(p) {
+		v, err := r.sealer.Open(*s)
+		if err != nil {
+			return fmt.Errorf("Error opening the secrets of login provider %d: %s", p.ID, err.Error())
+		}
+		*s = v
+	}
+	return nil
+}
+
+// sealSecrets Gets the encrypted secrets of a login provider to be stored
+func (r *Client) sealSecrets(p *models.LoginProvider) ([]interface{}, error) {
+	if r.sealer == nil {
+		return nil, fmt.Errorf("The secrets sealer is not set")
+	}
+	var resp []interface{}
+	for _, s := range secrets(p) {
+		v, err := r.sealer.Seal(*s)
+		if err != nil {
+			return nil, fmt.Errorf("Error sealing the secrets of login provider %d: %s", p.ID, err.Error())
+		}
+		resp = append(resp, v)
+	}
+	return resp, nil
+}
+
+// GetAllLoginProvider Gets all login providers
+func (r *Client) GetAllLoginProvider() ([]*models.LoginProvider, error) {
+
+	var resp []*models.LoginProvider
+
+	rows, err := r.db.Query(selectLoginProvider + " ORDER BY id")
+	if err != nil {
+		return resp, err
+	}
+
+	for rows.Next() {
+		n, err := scanLoginProvider(rows)
+		if err != nil {
+			defer rows.Close()
+			return resp, fmt.Errorf("Error reading rows: %s", err.Error())
+		}
+
+		if err = r.openSecrets(n); err != nil {
+			defer rows.Close()
+			return resp, err
+		}
+
+		resp = append(resp, n)
+	}
+
+	rows.Close()
+	if len(resp) == 0 {
+		return resp, fmt.Errorf("No Login providers found")
+	}
+
+	return resp, nil
+}
+
+// GetLoginProviderById Gets a LoginProvider by its Id
+func (r *Client) GetLoginProviderById(id int64) (*models.LoginProvider, error) {
+
+	var found bool
+	resp := &models.LoginProvider{}
+
+	err := r.db.QueryRow("SELECT IF(COUNT(*),'true','false') FROM login_provider WHERE id=?", id).Scan(&found)
+	if err != nil {
+		return resp, err
+	}
+
+	if !found {
+		return resp, serror.ErrNotFound.WithDetail("Login Provider with id %d not found", id)
+	}
+
+	resp, err = scanLoginProvider(r.db.QueryRow(selectLoginProvider+" WHERE id=?", id))
+	if err != nil {
+		return resp, err
+	}
+
+	if err = r.openSecrets(resp); err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// InsertLoginProvider Inserts a login provider with its secrets sealed
+func (r *Client) InsertLoginProvider(p *models.LoginProvider) error {
+
+	sealed, err := r.sealSecrets(p)
+	if err != nil {
+		return err
+	}
+
+	stmt, err := r.db.Prepare("INSERT INTO `login_provider` (name,web_clientid,web_secret,android_clientid,android_secret,iphone_clientid,iphone_secret,secrets_rotated_at,updated_at) VALUES (?,?,?,?,?,?,?,?,?)")
+	if err != nil {
+		return fmt.Errorf("Error in insert login provider prepared statement: %s", err.Error())
+	}
+
+	res, err := stmt.Exec(p.Name, p.WebClientid, sealed[0], p.AndroidClientid, sealed[1], p.IphoneClientid, sealed[2], p.SecretsRotatedAt, p.UpdatedAt)
+	defer stmt.Close()
+
+	if err != nil {
+		return fmt.Errorf("Error in insert login provider %s: %w", p.Name, typed(err))
+	}
+
+	p.ID, _ = res.LastInsertId()
+
+	return nil
+}
+
+// UpdateLoginProvider Updates a login provider, sealing again all its secrets
+func (r *Client) UpdateLoginProvider(p *models.LoginProvider) error {
+
+	sealed, err := r.sealSecrets(p)
+	if err != nil {
+		return err
+	}
+
+	stmt, err := r.db.Prepare("UPDATE `login_provider` SET name=?,web_clientid=?,web_secret=?,android_clientid=?,android_secret=?,iphone_clientid=?,iphone_secret=?,secrets_rotated_at=?,updated_at=? WHERE id=?")
+	if err != nil {
+		return fmt.Errorf("Error in update login provider prepared statement: %s", err.Error())
+	}
+
+	_, err = stmt.Exec(p.Name, p.WebClientid, sealed[0], p.AndroidClientid, sealed[1], p.IphoneClientid, sealed[2], p.SecretsRotatedAt, p.UpdatedAt, p.ID)
+	defer stmt.Close()
+
+	if err != nil {
+		return fmt.Errorf("Error in update login provider %d: %w", p.ID, typed(err))
+	}
+
+	return nil
+}
+
+// SealLoginProviderSecrets Seals again with the current key the secrets stored in plain text or with an older key.
+// Returns the number of providers sealed again
+func (r *Client) SealLoginProviderSecrets() (int64, error) {
+
+	if r.sealer == nil {
+		return 0, fmt.Errorf("The secrets sealer is not set")
+	}
+
+	rows, err := r.db.Query(selectLoginProvider)
+	if err != nil {
+		return 0, err
+	}
+
+	var outdated []*models.LoginProvider
+	for rows.Next() {
+		n, err := scanLoginProvider(rows)
+		if err != nil {
+			defer rows.Close()
+			return 0, fmt.Errorf("Error reading rows: %s", err.Error())
+		}
+
+		for _, s := range secrets(n) {
+			if !r.sealer.Current(*s) {
+				outdated = append(outdated, n)
+				break
+			}
+		}
+	}
+	rows.Close()
+
+	for _, n := range outdated {
+		if err = r.openSecrets(n); err != nil {
+			return 0, err
+		}
+		if err = r.UpdateLoginProvider(n); err != nil {
+			return 0, err
+		}
+	}
+
+	return int64(len(outdated)), nil
+}
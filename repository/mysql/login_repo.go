@@ -0,0 +1,66 @@
+package mysql
+
+import (
+	"fmt"
+	"time"
+)
+
+// RecordLoginFailure Adds a failed login attempt to the given user security. Returns the failed attempts in a row
+func (r *Client) RecordLoginFailure(id int64, machine string, at time.Time) (int64, error) {
+	var failures int64
+
+	stmt, err := r.db.Prepare("UPDATE `user_security` SET failed_attempts=failed_attempts+1,last_failed_at=?,last_failed_machine=? WHERE id=?")
+	if err != nil {
+		return failures, fmt.Errorf("Error in record login failure prepared statement: %s", err.Error())
+	}
+
+	_, err = stmt.Exec(at, machine, id)
+	defer stmt.Close()
+
+	if err != nil {
+		return failures, fmt.Errorf("Could not record login failure for securityID %d : %s", id, err.Error())
+	}
+
+	err = r.db.QueryRow("SELECT failed_attempts FROM user_security WHERE id=?", id).Scan(&failures)
+	if err != nil {
+		return failures, fmt.Errorf("Error reading failed attempts for securityID %d : %s", id, err.Error())
+	}
+
+	return failures, nil
+}
+
+// LockUserSecurity Blocks the logins of the given user security until the given time
+func (r *Client) LockUserSecurity(id int64, until time.Time) error {
+
+	stmt, err := r.db.Prepare("UPDATE `user_security` SET locked_until=? WHERE id=?")
+	if err != nil {
+		return fmt.Errorf("Error in lock user security prepared statement: %s", err.Error())
+	}
+
+	_, err = stmt.Exec(until, id)
+	defer stmt.Close()
+
+	if err != nil {
+		return fmt.Errorf("Could not lock securityID %d : %s", id, err.Error())
+	}
+
+	return nil
+}
+
+// ResetLoginFailures Clears the failed attempts and the lock of the given user security
+func (r *Client) ResetLoginFailures(id int64) error {
+
+	stmt, err := r.db.Prepare("UPDATE `user_security` SET failed_attempts=0,locked_until=NULL WHERE id=?")
+	if err != nil {
+		return fmt.Errorf("Error in reset login failures prepared statement: %s", err.Error())
+	}
+
+	_, err = stmt.Exec(id)
+	defer stmt.Close()
+
+	if err != nil {
+		return fmt.Errorf("Could not reset login failures of securityID %d : %s", id, err.Error())
+	}
+
+	return nil
+}
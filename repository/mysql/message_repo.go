@@ -0,0 +1,148 @@
+package mysql
+
+import (
+	"fmt"
+	"github.com/pintobikez/popmeet/api/models"
+)
+
+// userEvents Selects the events a user attended or hosted, the archived ones included
+const userEvents = "SELECT fk_event FROM event_users WHERE fk_user=? UNION SELECT id FROM event WHERE fk_created_by=? " +
+	"UNION SELECT fk_event FROM event_users_archive WHERE fk_user=? UNION SELECT id FROM event_archive WHERE fk_created_by=?"
+
+// HaveSharedEvent Checks if both users attended or hosted the same event, even one already archived
+func (r *Client) HaveSharedEvent(idUser int64, idOther int64) (bool, error) {
+
+	var found bool
+	err := r.db.QueryRow("SELECT IF(COUNT(*),'true','false') FROM "+
+		"("+userEvents+") a INNER JOIN ("+userEvents+") b ON a.fk_event=b.fk_event",
+		idUser, idUser, idUser, idUser, idOther, idOther, idOther, idOther).Scan(&found)
+	if err != nil {
+		return false, err
+	}
+
+	return found, nil
+}
+
+// InsertMessage Inserts a message into the message table
+func (r *Client) InsertMessage(m *models.Message) error {
+
+	stmt, err := r.db.Prepare("INSERT INTO `message` (fk_sender,fk_recipient,body,created_at) VALUES (?,?,?,now())")
+	if err != nil {
+		return fmt.Errorf("Error in insert message prepared statement: %s", err.Error())
+	}
+
+	res, err := stmt.Exec(m.Sender, m.Recipient, m.Body)
+	defer stmt.Close()
+
+	if err != nil {
+		return fmt.Errorf("Error in insert message from user %d to user %d: %w", m.Sender, m.Recipient, typed(err))
+	}
+
+	m.ID, _ = res.LastInsertId()
+
+	return r.db.QueryRow("SELECT created_at FROM message WHERE id=?", m.ID).Scan(&m.CreatedAt)
+}
+
+// GetMessagesBetweenUsers Gets the messages exchanged between two users, newest first.
+// When before is greater than zero only messages older than that message id are returned
+func (r *Client) GetMessagesBetweenUsers(idUser int64, idPeer int64, before int64, limit int) ([]*models.Message, error) {
+
+	resp := []*models.Message{}
+
+	query := "SELECT id,fk_sender,fk_recipient,body,created_at,read_at FROM message " +
+		"WHERE ((fk_sender=? AND fk_recipient=?) OR (fk_sender=? AND fk_recipient=?))"
+	args := []interface{}{idUser, idPeer, idPeer, idUser}
+
+	if before > 0 {
+		query += " AND id<?"
+		args = append(args, before)
+	}
+	query += " ORDER BY id DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return resp, err
+	}
+
+	for rows.Next() {
+		var m = new(models.Message)
+
+		err = rows.Scan(&m.ID, &m.Sender, &m.Recipient, &m.Body, &m.CreatedAt, &m.ReadAt)
+		if err != nil {
+			defer rows.Close()
+			return resp, fmt.Errorf("Error reading rows: %s", err.Error())
+		}
+
+		resp = append(resp, m)
+	}
+
+	rows.Close()
+
+	return resp, nil
+}
+
+// GetConversationsByUserId Gets the last message and unread count of every conversation of a given user
+func (r *Client) GetConversationsByUserId(id int64) ([]*models.Conversation, error) {
+
+	resp := []*models.Conversation{}
+
+	rows, err := r.db.Query("SELECT m.id,m.fk_sender,m.fk_recipient,m.body,m.created_at,m.read_at,u.id,u.name,"+
+		"(SELECT COUNT(*) FROM message mu WHERE mu.fk_sender=u.id AND mu.fk_recipient=? AND mu.read_at IS NULL) as unread "+
+		"FROM message m "+
+		"INNER JOIN (SELECT IF(fk_sender=?,fk_recipient,fk_sender) as peer, MAX(id) as last_id FROM message WHERE fk_sender=? OR fk_recipient=? GROUP BY peer) lm ON m.id=lm.last_id "+
+		"INNER JOIN user u ON u.id=lm.peer "+
+		"WHERE NOT EXISTS (SELECT 1 FROM user_block ub WHERE (ub.fk_blocker=? AND ub.fk_blocked=u.id) OR (ub.fk_blocker=u.id AND ub.fk_blocked=?)) "+
+		"ORDER BY m.id DESC", id, id, id, id, id, id)
+	if err != nil {
+		return resp, err
+	}
+
+	for rows.Next() {
+		var c = &models.Conversation{User: new(models.User), LastMessage: new(models.Message)}
+		m := c.LastMessage
+
+		err = rows.Scan(&m.ID, &m.Sender, &m.Recipient, &m.Body, &m.CreatedAt, &m.ReadAt, &c.User.ID, &c.User.Name, &c.Unread)
+		if err != nil {
+			defer rows.Close()
+			return resp, fmt.Errorf("Error reading rows: %s", err.Error())
+		}
+
+		resp = append(resp, c)
+	}
+
+	rows.Close()
+
+	return resp, nil
+}
+
+// MarkMessagesAsRead Sets the read date of all the messages sent by idPeer to idUser
+func (r *Client) MarkMessagesAsRead(idUser int64, idPeer int64) error {
+
+	stmt, err := r.db.Prepare("UPDATE `message` SET read_at=now() WHERE fk_recipient=? AND fk_sender=? AND read_at IS NULL")
+	if err != nil {
+		return fmt.Errorf("Error in mark messages as read prepared statement: %s", err.Error())
+	}
+
+	_, err = stmt.Exec(idUser, idPeer)
+	defer stmt.Close()
+
+	if err != nil {
+		return fmt.Errorf("Error marking messages from user %d to user %d as read: %s", idPeer, idUser, err.Error())
+	}
+
+	return nil
+}
+
+// CountUnreadMessages Counts the unread messages of a given user
+func (r *Client) CountUnreadMessages(id int64) (int64, error) {
+
+	var total int64
+	err := r.db.QueryRow("SELECT COUNT(*) FROM message m WHERE m.fk_recipient=? AND m.read_at IS NULL "+
+		"AND NOT EXISTS (SELECT 1 FROM user_block ub WHERE (ub.fk_blocker=? AND ub.fk_blocked=m.fk_sender) OR (ub.fk_blocker=m.fk_sender AND ub.fk_blocked=?))", id, id, id).Scan(&total)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
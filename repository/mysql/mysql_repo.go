@@ -1,6 +1,7 @@
 package mysql
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	_ "github.com/go-sql-driver/mysql"
@@ -8,6 +9,7 @@ import (
 	cnfs "github.com/pintobikez/popmeet/config/structures"
 	serror "github.com/pintobikez/popmeet/errors"
 	"strconv"
+	"time"
 )
 
 const (
@@ -18,6 +20,7 @@ type Client struct {
 	config *cnfs.DatabaseConfig
 	db     *sql.DB
 	tx     *sql.Tx
+	sealer Sealer
 }
 
 func New(cnfg *cnfs.DatabaseConfig) (*Client, error) {
@@ -28,6 +31,11 @@ func New(cnfg *cnfs.DatabaseConfig) (*Client, error) {
 	return &Client{config: cnfg}, nil
 }
 
+// SetSealer Sets the encryption of the secrets stored in the database
+func (r *Client) SetSealer(s Sealer) {
+	r.sealer = s
+}
+
 // Connects to the mysql database
 func (r *Client) Connect() error {
 
@@ -48,6 +56,11 @@ func (r *Client) Disconnect() {
 	r.db.Close()
 }
 
+// Stats Gets the stats of the connection pool
+func (r *Client) Stats() sql.DBStats {
+	return r.db.Stats()
+}
+
 // InsertUser Creates a new record in the user table
 func (r *Client) InsertUser(u *models.User) error {
 	var err error
@@ -59,7 +72,7 @@ func (r *Client) InsertUser(u *models.User) error {
 	}
 	defer r.deferRollback()
 
-	stmt, err := r.tx.Prepare("INSERT INTO `user` VALUES (null,?,?,now(),now(),1)")
+	stmt, err := r.tx.Prepare("INSERT INTO `user` (email,name,created_at,updated_at,active) VALUES (?,?,now(),now(),1)")
 	if err != nil {
 		return fmt.Errorf("Error in insert user prepared statement: %s", err.Error())
 	}
@@ -68,7 +81,7 @@ func (r *Client) InsertUser(u *models.User) error {
 	defer stmt.Close()
 
 	if err != nil {
-		return fmt.Errorf("Error in insert user %s, email: %s %s", u.Name, u.Email, err.Error())
+		return fmt.Errorf("Error in insert user %s, email: %s %w", u.Name, u.Email, typed(err))
 	}
 
 	u.ID, _ = res.LastInsertId()
@@ -81,7 +94,7 @@ func (r *Client) InsertUser(u *models.User) error {
 	return nil
 }
 
-// UpdateUser Updates the given user in the user table
+// UpdateUser Updates the given user in the user table, the email is changed with SetPendingEmail and ConfirmPendingEmail
 func (r *Client) UpdateUser(u *models.User) error {
 	var err error
 	// start a transaction
@@ -92,23 +105,21 @@ func (r *Client) UpdateUser(u *models.User) error {
 	}
 	defer r.deferRollback()
 
-	stmt, err := r.tx.Prepare("UPDATE `user` SET email=?,name=?,updated_at=now() WHERE id=?")
+	stmt, err := r.tx.Prepare("UPDATE `user` SET name=?,updated_at=now() WHERE id=?")
 	if err != nil {
 		return fmt.Errorf("Error in update user prepared statement: %s", err.Error())
 	}
 
-	_, err = stmt.Exec(u.Email, u.Name, u.ID)
+	_, err = stmt.Exec(u.Name, u.ID)
 	defer stmt.Close()
 
-	fmt.Printf("%v", u.Profile)
-
 	if err != nil {
-		return fmt.Errorf("Could not update userID %d : %s", u.ID, err.Error())
+		return fmt.Errorf("Could not update userID %d : %w", u.ID, typed(err))
 	}
 
 	// UPDATE SECURITY
 	if u.Security != nil {
-		if err = r.UpdateUserSecurity(u.Security); err != nil {
+		if err = r.UpdateUserSecurity(u.Security, u.ID); err != nil {
 			return err
 		}
 	}
@@ -117,7 +128,7 @@ func (r *Client) UpdateUser(u *models.User) error {
 		if u.Profile.Interests == nil {
 			u.Profile.Interests = []*models.Interest{}
 		}
-		if err = r.UpdateUserProfile(u.Profile); err != nil {
+		if err = r.UpdateUserProfile(u.Profile, u.ID); err != nil {
 			return err
 		}
 	}
@@ -146,13 +157,19 @@ func (r *Client) GetUserById(id int64) (*models.User, error) {
 	}
 
 	if !found {
-		return resp, fmt.Errorf("User with id %d not found", id)
+		return resp, serror.ErrNotFound.WithDetail("User with id %d not found", id)
 	}
 
-	err = r.db.QueryRow("SELECT id, email, name, created_at, updated_at, active FROM user WHERE id=?", id).Scan(&resp.ID, &resp.Email, &resp.Name, &resp.CreatedAt, &resp.UpdatedAt, &resp.Active)
+	var verifiedAt *time.Time
+	var pending *string
+	err = r.db.QueryRow("SELECT id, email, name, created_at, updated_at, active, role, email_verified_at, pending_email, deletion_at FROM user WHERE id=?", id).Scan(&resp.ID, &resp.Email, &resp.Name, &resp.CreatedAt, &resp.UpdatedAt, &resp.Active, &resp.Role, &verifiedAt, &pending, &resp.DeletionAt)
 	if err != nil {
 		return resp, err
 	}
+	resp.EmailVerified = verifiedAt != nil
+	if pending != nil {
+		resp.PendingEmail = *pending
+	}
 
 	return resp, nil
 }
@@ -168,13 +185,19 @@ func (r *Client) GetUserByEmail(email string) (*models.User, error) {
 	}
 
 	if !found {
-		return resp, fmt.Errorf("User with email %s not found", email)
+		return resp, serror.ErrNotFound.WithDetail("User not found")
 	}
 
-	err = r.db.QueryRow("SELECT id, email, name, created_at, updated_at, active FROM user WHERE email=?", email).Scan(&resp.ID, &resp.Email, &resp.Name, &resp.CreatedAt, &resp.UpdatedAt, &resp.Active)
+	var verifiedAt *time.Time
+	var pending *string
+	err = r.db.QueryRow("SELECT id, email, name, created_at, updated_at, active, role, email_verified_at, pending_email, deletion_at FROM user WHERE email=?", email).Scan(&resp.ID, &resp.Email, &resp.Name, &resp.CreatedAt, &resp.UpdatedAt, &resp.Active, &resp.Role, &verifiedAt, &pending, &resp.DeletionAt)
 	if err != nil {
 		return resp, err
 	}
+	resp.EmailVerified = verifiedAt != nil
+	if pending != nil {
+		resp.PendingEmail = *pending
+	}
 
 	return resp, nil
 }
@@ -222,7 +245,7 @@ func (r *Client) InsertUserProfile(u *models.UserProfile, id int64) error {
 	defer stmt.Close()
 
 	if err != nil {
-		return fmt.Errorf("Error in insert user_profile for user id: %d", id, err.Error())
+		return fmt.Errorf("Error in insert user_profile for user id: %d %w", id, typed(err))
 	}
 	u.ID, _ = res.LastInsertId()
 
@@ -233,13 +256,31 @@ func (r *Client) InsertUserProfile(u *models.UserProfile, id int64) error {
 		}
 	}
 
+	//Update User spoken languages
+	if u.Languages != nil {
+		if err = r.updateSpokenLanguages(u.Languages, u.ID); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
-// UpdateUserProfile Updates the given user in the user_profile table
-func (r *Client) UpdateUserProfile(u *models.UserProfile) error {
+// UpdateUserProfile Updates the profile of the given User id in the user_profile table, with its interests and languages
+func (r *Client) UpdateUserProfile(u *models.UserProfile, id int64) error {
 	var err error
 	var stmt *sql.Stmt
+	var row *sql.Row
+
+	// the profile of the user, whatever the profile id it was given
+	if r.tx != nil {
+		row = r.tx.QueryRow("SELECT id FROM `user_profile` WHERE fk_user=?", id)
+	} else {
+		row = r.db.QueryRow("SELECT id FROM `user_profile` WHERE fk_user=?", id)
+	}
+	if err = row.Scan(&u.ID); err != nil {
+		return fmt.Errorf("Error in reading the user_profile of userID %d : %w", id, typed(err))
+	}
 
 	if r.tx != nil {
 		stmt, err = r.tx.Prepare("UPDATE `user_profile` SET fk_language=?,sex=?,age_range=?,updated_at=now() WHERE id=?")
@@ -247,11 +288,15 @@ func (r *Client) UpdateUserProfile(u *models.UserProfile) error {
 		stmt, err = r.db.Prepare("UPDATE `user_profile` SET fk_language=?,sex=?,age_range=?,updated_at=now() WHERE id=?")
 	}
 
+	if err != nil {
+		return fmt.Errorf("Error in update user_profile prepared statement: %s", err.Error())
+	}
+
 	_, err = stmt.Exec(u.Language.ID, u.Sex, u.AgeRange, u.ID)
 	defer stmt.Close()
 
 	if err != nil {
-		return fmt.Errorf("Error in update user_profile userID %d : %s", u.ID, err.Error())
+		return fmt.Errorf("Error in update user_profile userID %d : %w", u.ID, typed(err))
 	}
 
 	//Update User Interests
@@ -259,6 +304,11 @@ func (r *Client) UpdateUserProfile(u *models.UserProfile) error {
 		return err
 	}
 
+	//Update User spoken languages
+	if err = r.updateSpokenLanguages(u.Languages, u.ID); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -274,7 +324,7 @@ func (r *Client) GetUserProfileByUserId(id int64) (*models.UserProfile, error) {
 	}
 
 	if !found {
-		return resp, fmt.Errorf("UserProfile for user with id %d not found", id)
+		return resp, serror.ErrNotFound.WithDetail("UserProfile for user with id %d not found", id)
 	}
 
 	err = r.db.QueryRow("SELECT id,fk_language,age_range,sex,updated_at FROM user_profile WHERE fk_user=?", id).Scan(&resp.ID, &fkLanguage, &resp.AgeRange, &resp.Sex, &resp.UpdatedAt)
@@ -292,6 +342,11 @@ func (r *Client) GetUserProfileByUserId(id int64) (*models.UserProfile, error) {
 		return resp, err
 	}
 
+	resp.Languages, err = r.getSpokenLanguages(resp.ID)
+	if err != nil {
+		return resp, err
+	}
+
 	return resp, nil
 }
 
@@ -302,9 +357,9 @@ func (r *Client) InsertUserSecurity(u *models.UserSecurity, id int64) error {
 	var stmt *sql.Stmt
 
 	if r.tx != nil {
-		stmt, err = r.tx.Prepare("INSERT INTO `user_security` VALUES (null,?,?,?,?,now(),now())")
+		stmt, err = r.tx.Prepare("INSERT INTO `user_security` (fk_user,fk_login_provider,hash,last_machine,last_login_date,updated_at) VALUES (?,?,?,?,now(),now())")
 	} else {
-		stmt, err = r.db.Prepare("INSERT INTO `user_security` VALUES (null,?,?,?,?,now(),now())")
+		stmt, err = r.db.Prepare("INSERT INTO `user_security` (fk_user,fk_login_provider,hash,last_machine,last_login_date,updated_at) VALUES (?,?,?,?,now(),now())")
 	}
 
 	if err != nil {
@@ -325,43 +380,109 @@ func (r *Client) InsertUserSecurity(u *models.UserSecurity, id int64) error {
 	defer stmt.Close()
 
 	if err != nil {
-		return fmt.Errorf("Error in insert user_security for user id: %d", id, err.Error())
+		return fmt.Errorf("Error in insert user_security for user id: %d %w", id, typed(err))
 	}
 	u.ID, _ = res.LastInsertId()
 
 	return nil
 }
 
-// UpdateUserSecurity Updates the given user in the user_security table
-func (r *Client) UpdateUserSecurity(u *models.UserSecurity) error {
+// UpdateUserSecurity Updates the login provider of the given User id in the user_security table.
+// The password hash is only changed by ChangePassword
+func (r *Client) UpdateUserSecurity(u *models.UserSecurity, id int64) error {
 
 	var err error
 	var stmt *sql.Stmt
 
+	if u.Provider == nil {
+		return nil
+	}
+
 	if r.tx != nil {
-		stmt, err = r.tx.Prepare("UPDATE `user_security` SET fk_login_provider=?,hash=?,updated_at=now() WHERE id=?")
+		stmt, err = r.tx.Prepare("UPDATE `user_security` SET fk_login_provider=?,updated_at=now() WHERE fk_user=?")
 	} else {
-		stmt, err = r.db.Prepare("UPDATE `user_security` SET fk_login_provider=?,hash=?,updated_at=now() WHERE id=?")
+		stmt, err = r.db.Prepare("UPDATE `user_security` SET fk_login_provider=?,updated_at=now() WHERE fk_user=?")
 	}
 
 	if err != nil {
 		return fmt.Errorf("Error in update user security prepared statement: %s", err.Error())
 	}
 
-	_, err = stmt.Exec(u.Provider.ID, u.Hash, u.ID)
+	_, err = stmt.Exec(u.Provider.ID, id)
 	defer stmt.Close()
 
 	if err != nil {
-		return fmt.Errorf("Error in update security for userID %d : %s", u.ID, err.Error())
+		return fmt.Errorf("Error in update security for userID %d : %w", id, typed(err))
+	}
+
+	return nil
+}
+
+// ChangePassword Sets the password hash of the given user security, ending all its sessions.
+// Returns the new session version
+func (r *Client) ChangePassword(id int64, hash string) (int64, error) {
+	var version int64
+
+	stmt, err := r.db.Prepare("UPDATE `user_security` SET hash=?,session_version=session_version+1,updated_at=now() WHERE id=?")
+	if err != nil {
+		return version, fmt.Errorf("Error in change password prepared statement: %s", err.Error())
+	}
+
+	_, err = stmt.Exec(hash, id)
+	defer stmt.Close()
+
+	if err != nil {
+		return version, fmt.Errorf("Error in change password for securityID %d : %s", id, err.Error())
+	}
+
+	err = r.db.QueryRow("SELECT session_version FROM user_security WHERE id=?", id).Scan(&version)
+	if err != nil {
+		return version, fmt.Errorf("Error reading session version for securityID %d : %s", id, err.Error())
+	}
+
+	return version, nil
+}
+
+// SetUserRole Sets the role of a given User id, ending all its sessions so the tokens with the old role stop working
+func (r *Client) SetUserRole(id int64, role string) error {
+	// own transaction instead of r.tx, shared by the concurrent requests
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec("UPDATE `user` SET role=?,updated_at=now() WHERE id=?", role, id); err != nil {
+		return fmt.Errorf("Could not set the role of userID %d : %s", id, err.Error())
+	}
+
+	if _, err = tx.Exec("UPDATE `user_security` SET session_version=session_version+1,updated_at=now() WHERE fk_user=?", id); err != nil {
+		return fmt.Errorf("Could not end the sessions of userID %d : %s", id, err.Error())
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("Could not set the role of userID %d : %s", id, err.Error())
 	}
 
 	return nil
 }
 
-// UpdateLoginData Updates the Data for the login stats
+// GetSessionVersion Gets the current session version of a given User id
+func (r *Client) GetSessionVersion(userId int64) (int64, error) {
+	var version int64
+
+	err := r.db.QueryRow("SELECT session_version FROM user_security WHERE fk_user=?", userId).Scan(&version)
+	if err != nil {
+		return version, serror.ErrNotFound.WithDetail("UserSecurity for user with id %d not found", userId)
+	}
+
+	return version, nil
+}
+
+// UpdateLoginData Updates the Data for the login stats, clearing the failed attempts
 func (r *Client) UpdateLoginData(u *models.UserSecurity) error {
 
-	stmt, err := r.db.Prepare("UPDATE `user_security` SET last_login_date=now(),last_machine=? WHERE id=?")
+	stmt, err := r.db.Prepare("UPDATE `user_security` SET last_login_date=now(),last_machine=?,failed_attempts=0,locked_until=NULL WHERE id=?")
 	if err != nil {
 		return fmt.Errorf("Error in update user security prepared statement: %s", err.Error())
 	}
@@ -380,6 +501,8 @@ func (r *Client) UpdateLoginData(u *models.UserSecurity) error {
 func (r *Client) GetSecurityInfoByUserId(id int64) (*models.UserSecurity, error) {
 	var found bool
 	var fkProvider int64
+	var secret *string
+	var enabledAt *time.Time
 	resp := &models.UserSecurity{Provider: &models.LoginProvider{}}
 
 	err := r.db.QueryRow("SELECT IF(COUNT(*),'true','false') FROM user_security WHERE fk_user=?", id).Scan(&found)
@@ -388,15 +511,20 @@ func (r *Client) GetSecurityInfoByUserId(id int64) (*models.UserSecurity, error)
 	}
 
 	if !found {
-		return resp, fmt.Errorf("UserSecurity for user with id %d not found", id)
+		return resp, serror.ErrNotFound.WithDetail("UserSecurity for user with id %d not found", id)
 	}
 
-	err = r.db.QueryRow("SELECT id,fk_login_provider,hash,last_machine,last_login_date,updated_at FROM user_security WHERE fk_user=?", id).
-		Scan(&resp.ID, &fkProvider, &resp.Hash, &resp.LastMachine, &resp.LastLogin, &resp.UpdatedAt)
+	err = r.db.QueryRow("SELECT id,fk_login_provider,hash,session_version,totp_secret,totp_enabled_at,failed_attempts,last_failed_at,locked_until,last_machine,last_login_date,updated_at FROM user_security WHERE fk_user=?", id).
+		Scan(&resp.ID, &fkProvider, &resp.Hash, &resp.SessionVersion, &secret, &enabledAt, &resp.FailedAttempts, &resp.LastFailedAt, &resp.LockedUntil, &resp.LastMachine, &resp.LastLogin, &resp.UpdatedAt)
 	if err != nil {
 		return resp, err
 	}
 
+	if secret != nil {
+		resp.TotpSecret = *secret
+	}
+	resp.TwoFactor = enabledAt != nil
+
 	resp.Provider, err = r.GetLoginProviderById(fkProvider)
 	if err != nil {
 		return resp, err
@@ -416,7 +544,7 @@ func (r *Client) GetInterestById(id int64) (*models.Interest, error) {
 	}
 
 	if !found {
-		return resp, fmt.Errorf("Interest with id %d not found", id)
+		return resp, serror.ErrNotFound.WithDetail("Interest with id %d not found", id)
 	}
 
 	err = r.db.QueryRow("SELECT id, name FROM interest WHERE id=?", id).Scan(&resp.ID, &resp.Name)
@@ -486,7 +614,7 @@ func (r *Client) UpdateUserInterests(interests []*models.Interest, id int64) err
 			_, err = stmti.Exec(i.ID, id)
 			defer stmti.Close()
 			if err != nil {
-				return fmt.Errorf("Error in inserting user interests for userID %d : %s", id, err.Error())
+				return fmt.Errorf("Error in inserting user interests for userID %d : %w", id, typed(err))
 			}
 		}
 		stmti.Close()
@@ -527,7 +655,7 @@ func (r *Client) GetAllLanguage() ([]*models.Language, error) {
 
 	var resp []*models.Language
 
-	rows, err := r.db.Query("SELECT id, name, name_iso2, name_iso3 from language")
+	rows, err := r.db.Query("SELECT id, name, name_iso2, name_iso3, active from language ORDER BY name")
 	if err != nil {
 		return resp, err
 	}
@@ -535,7 +663,7 @@ func (r *Client) GetAllLanguage() ([]*models.Language, error) {
 	for rows.Next() {
 		var n = new(models.Language)
 
-		err = rows.Scan(&n.ID, &n.Name, &n.NameIso2, &n.NameIso3)
+		err = rows.Scan(&n.ID, &n.Name, &n.NameIso2, &n.NameIso3, &n.Active)
 		if err != nil {
 			defer rows.Close()
 			return resp, fmt.Errorf("Error reading rows: %s", err.Error())
@@ -564,63 +692,10 @@ func (r *Client) GetLanguageById(id int64) (*models.Language, error) {
 	}
 
 	if !found {
-		return resp, fmt.Errorf("Language with id %d not found", id)
-	}
-
-	err = r.db.QueryRow("SELECT id, name, name_iso2, name_iso3 FROM language WHERE id=?", id).Scan(&resp.ID, &resp.Name, &resp.NameIso2, &resp.NameIso3)
-	if err != nil {
-		return resp, err
-	}
-
-	return resp, nil
-}
-
-// GetAllLoginProvider Gets all login providers
-func (r *Client) GetAllLoginProvider() ([]*models.LoginProvider, error) {
-
-	var resp []*models.LoginProvider
-
-	rows, err := r.db.Query("SELECT id,name,web_clientid,web_secret,android_clientid,android_secret,iphone_clientid,iphone_secret,updated_at from language")
-	if err != nil {
-		return resp, err
-	}
-
-	for rows.Next() {
-		var n = new(models.LoginProvider)
-
-		err = rows.Scan(&n.ID, &n.Name, &n.WebClientid, &n.WebSecret, &n.AndroidClientid, &n.AndroidSecret, &n.IphoneClientid, &n.IphoneSecret, &n.UpdatedAt)
-		if err != nil {
-			return resp, fmt.Errorf("Error reading rows: %s", err.Error())
-		}
-
-		resp = append(resp, n)
-	}
-
-	rows.Close()
-	if len(resp) == 0 {
-		return resp, fmt.Errorf("No Login providers found")
-	}
-
-	return resp, nil
-}
-
-// GetLoginProviderById Gets a LoginProvider by its Id
-func (r *Client) GetLoginProviderById(id int64) (*models.LoginProvider, error) {
-
-	var found bool
-	resp := &models.LoginProvider{}
-
-	err := r.db.QueryRow("SELECT IF(COUNT(*),'true','false') FROM login_provider WHERE id=?", id).Scan(&found)
-	if err != nil {
-		return resp, err
-	}
-
-	if !found {
-		return resp, fmt.Errorf("Login Provider with id %d not found", id)
+		return resp, serror.ErrNotFound.WithDetail("Language with id %d not found", id)
 	}
 
-	err = r.db.QueryRow("SELECT id,name,web_clientid,web_secret,android_clientid,android_secret,iphone_clientid,iphone_secret,updated_at FROM login_provider WHERE id=?", id).
-		Scan(&resp.ID, &resp.Name, &resp.WebClientid, &resp.WebSecret, &resp.AndroidClientid, &resp.AndroidSecret, &resp.IphoneClientid, &resp.IphoneSecret, &resp.UpdatedAt)
+	err = r.db.QueryRow("SELECT id, name, name_iso2, name_iso3, active FROM language WHERE id=?", id).Scan(&resp.ID, &resp.Name, &resp.NameIso2, &resp.NameIso3, &resp.Active)
 	if err != nil {
 		return resp, err
 	}
@@ -631,7 +706,7 @@ func (r *Client) GetLoginProviderById(id int64) (*models.LoginProvider, error) {
 // InsertEvent Inserts and event into event table
 func (r *Client) InsertEvent(ev *models.Event) error {
 
-	stmt, err := r.db.Prepare("INSERT INTO `event` VALUES (null,now(),?,?,?,?,?,?,?)")
+	stmt, err := r.db.Prepare("INSERT INTO `event` (created_at,start_datetime,end_datetime,location,latitude,longitude,active,fk_created_by) VALUES (now(),?,?,?,?,?,?,?)")
 	if err != nil {
 		return fmt.Errorf("Error in insert event prepared statement: %s", err.Error())
 	}
@@ -640,7 +715,7 @@ func (r *Client) InsertEvent(ev *models.Event) error {
 	defer stmt.Close()
 
 	if err != nil {
-		return fmt.Errorf("Error in insert event for user id %d", ev.CreatedBy.ID, err.Error())
+		return fmt.Errorf("Error in insert event for user id %d %w", ev.CreatedBy.ID, typed(err))
 	}
 
 	ev.ID, _ = res.LastInsertId()
@@ -651,12 +726,12 @@ func (r *Client) InsertEvent(ev *models.Event) error {
 // UpdateEvent Update the given event in event table
 func (r *Client) UpdateEvent(ev *models.Event) error {
 
-	stmt, err := r.tx.Prepare("UPDATE `event` SET location=?,latitude=?,longitude=?,start_datetime=?,end_datetime=?,active=? WHERE id=?")
+	stmt, err := r.db.Prepare("UPDATE `event` SET location=?,latitude=?,longitude=?,start_datetime=?,end_datetime=?,active=? WHERE id=?")
 	if err != nil {
-		return fmt.Errorf("Error in update user prepared statement: %s", err.Error())
+		return fmt.Errorf("Error in update event prepared statement: %s", err.Error())
 	}
 
-	_, err = stmt.Exec(ev.Location, ev.Latitude, ev.Longitude, ev.StartDate, ev.EndDate, ev.StartDate, ev.Active, ev.ID)
+	_, err = stmt.Exec(ev.Location, ev.Latitude, ev.Longitude, ev.StartDate, ev.EndDate, ev.Active, ev.ID)
 	defer stmt.Close()
 
 	if err != nil {
@@ -679,11 +754,11 @@ func (r *Client) GetEventById(id int64) (*models.Event, error) {
 	}
 
 	if !found {
-		return ev, fmt.Errorf("Event with id %d not found", id)
+		return ev, serror.ErrNotFound.WithDetail("Event with id %d not found", id)
 	}
 
-	err = r.db.QueryRow("SELECT id,created_at,start_datetime,end_datetime,location,latitude,longitude,active,fk_created_by FROM event WHERE id=?", id).
-		Scan(&ev.ID, &ev.CreatedAt, &ev.StartDate, &ev.EndDate, &ev.Location, &ev.Latitude, &ev.Longitude, &ev.Active, &fkCreatedBy)
+	err = r.db.QueryRow("SELECT id,created_at,start_datetime,end_datetime,location,latitude,longitude,active,fk_created_by,completed_at FROM event WHERE id=?", id).
+		Scan(&ev.ID, &ev.CreatedAt, &ev.StartDate, &ev.EndDate, &ev.Location, &ev.Latitude, &ev.Longitude, &ev.Active, &fkCreatedBy, &ev.CompletedAt)
 	if err != nil {
 		return ev, err
 	}
@@ -696,7 +771,7 @@ func (r *Client) GetEventById(id int64) (*models.Event, error) {
 	// Get the users in the event
 	ev.Users = []*models.User{}
 
-	rows, err := r.db.Query("SELECT u.id,u.email,u.name,u.created_at,u.updated_at,u.active,up.ID as pid,up.age_range,up.sex,up.updated_at as udate,la.ID as lid,la.name as lname,la.name_iso2 as lname2,la.name_iso3 as lname3 FROM event_users as eu INNER JOIN user as u on eu.fk_user=u.id LEFT JOIN user_profile up on u.ID=up.fk_user LEFT JOIN language la on up.fk_language=la.ID WHERE eu.fk_event=?", id)
+	rows, err := r.db.Query("SELECT u.id,u.email,u.name,u.created_at,u.updated_at,u.active,up.ID as pid,up.age_range,up.sex,up.updated_at as udate,la.ID as lid,la.name as lname,la.name_iso2 as lname2,la.name_iso3 as lname3,la.active as lactive FROM event_users as eu INNER JOIN user as u on eu.fk_user=u.id LEFT JOIN user_profile up on u.ID=up.fk_user LEFT JOIN language la on up.fk_language=la.ID WHERE eu.fk_event=?", id)
 	if err != nil {
 		// there are no users at the events
 		return ev, nil
@@ -708,7 +783,7 @@ func (r *Client) GetEventById(id int64) (*models.Event, error) {
 		var p = new(models.UserProfile)
 		var l = new(models.Language)
 
-		err = rows.Scan(&u.ID, &u.Email, &u.Name, &u.CreatedAt, &u.UpdatedAt, &u.Active, &p.ID, &p.AgeRange, &p.Sex, &p.UpdatedAt, &l.ID, &l.Name, &l.NameIso2, &l.NameIso3)
+		err = rows.Scan(&u.ID, &u.Email, &u.Name, &u.CreatedAt, &u.UpdatedAt, &u.Active, &p.ID, &p.AgeRange, &p.Sex, &p.UpdatedAt, &l.ID, &l.Name, &l.NameIso2, &l.NameIso3, &l.Active)
 		if err != nil {
 			defer rows.Close()
 			return ev, fmt.Errorf("Error reading rows: %s", err.Error())
@@ -779,7 +854,25 @@ func (r *Client) AddUserToEvent(idEvent int64, idUser int64) error {
 		return err
 	}
 	if found {
-		return fmt.Errorf("%d", serror.ErrorCantAddUSerToEvent)
+		return serror.ErrCantAddUserToEvent
+	}
+
+	// finished events can't be joined
+	err = r.db.QueryRow("SELECT IF(COUNT(*),'true','false') FROM event WHERE id=? and (end_datetime<=now() or completed_at IS NOT NULL)", idEvent).Scan(&found)
+	if err != nil {
+		return err
+	}
+	if found {
+		return serror.ErrEventFinished
+	}
+
+	// the event creator and the user can't have blocked each other
+	err = r.db.QueryRow("SELECT IF(COUNT(*),'true','false') FROM event e INNER JOIN user_block ub ON (ub.fk_blocker=e.fk_created_by AND ub.fk_blocked=?) OR (ub.fk_blocker=? AND ub.fk_blocked=e.fk_created_by) WHERE e.id=?", idUser, idUser, idEvent).Scan(&found)
+	if err != nil {
+		return err
+	}
+	if found {
+		return serror.ErrUserBlocked.WithDetail("Can't join this event")
 	}
 
 	stmt, err := r.db.Prepare("INSERT INTO `event_users` VALUES (?,?)")
@@ -791,7 +884,7 @@ func (r *Client) AddUserToEvent(idEvent int64, idUser int64) error {
 	defer stmt.Close()
 
 	if err != nil {
-		return fmt.Errorf("Error adding user %d to event %d - %s", idUser, idEvent, err.Error())
+		return fmt.Errorf("Error adding user %d to event %d - %w", idUser, idEvent, typed(err))
 	}
 
 	return nil
@@ -815,20 +908,17 @@ func (r *Client) RemoveUserFromEvent(idEvent int64, idUser int64) error {
 	return nil
 }
 
-// Health Endpoint of the Client
-func (r *Client) Health() error {
+// Health Pings the mysql server, failing when it can not be reached before the context is done
+func (r *Client) Health(ctx context.Context) error {
 
-	str, err := r.buildStringConnection()
-	if err != nil {
-		return err
+	if r.db == nil {
+		return fmt.Errorf("Database not connected")
 	}
 
-	db, err := sql.Open("mysql", str)
-	if err != nil {
-		return err
+	if err := r.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("Error in database ping: %s", err.Error())
 	}
 
-	db.Close()
 	return nil
 }
 
@@ -861,7 +951,7 @@ func (r *Client) buildStringConnection() (string, error) {
 	return stringConn, nil
 }
 
-//deferRollback default defer to rollback transactions on error
+// deferRollback default defer to rollback transactions on error
 func (r *Client) deferRollback() {
 	if r.tx != nil {
 		r.tx.Rollback()
@@ -869,7 +959,7 @@ func (r *Client) deferRollback() {
 	}
 }
 
-//commit commits and set the transaction to nil
+// commit commits and set the transaction to nil
 func (r *Client) commit() {
 	if r.tx != nil {
 		r.tx.Commit()
@@ -0,0 +1,54 @@
+package mysql
+
+import (
+	"errors"
+	"github.com/pintobikez/popmeet/api/models"
+	serror "github.com/pintobikez/popmeet/errors"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+/* Test for UpdateUser method, the security of the user only changes its login provider */
+func TestUpdateUserSecurity(t *testing.T) {
+
+	r, err := newFakeClient()
+	assert.Nil(t, err)
+	fakeStatements()
+
+	u := &models.User{ID: 1, Name: "test", Security: &models.UserSecurity{ID: 99, Provider: &models.LoginProvider{ID: 2}}}
+	err = r.UpdateUser(u)
+
+	var security []string
+	for _, stmt := range fakeStatements() {
+		if strings.Contains(stmt, "`user_security`") {
+			security = append(security, stmt)
+		}
+	}
+
+	// Assertions
+	assert.Nil(t, err)
+	if assert.Len(t, security, 1) {
+		assert.NotContains(t, security[0], "hash")
+		assert.Contains(t, security[0], "WHERE fk_user=?")
+	}
+}
+
+/* Test for UpdateUserProfile method, the profile id given is ignored for the one of the user */
+func TestUpdateUserProfileOfUser(t *testing.T) {
+
+	r, err := newFakeClient()
+	assert.Nil(t, err)
+	fakeStatements()
+
+	// the fake database has no profile for the user, the profile 99 of another user is not written
+	p := &models.UserProfile{ID: 99, Language: &models.Language{ID: 1}, Sex: "male", AgeRange: "18-25"}
+	err = r.UpdateUserProfile(p, 1)
+	statements := fakeStatements()
+
+	// Assertions
+	assert.True(t, errors.Is(err, serror.ErrNotFound))
+	if assert.Len(t, statements, 1) {
+		assert.Contains(t, statements[0], "WHERE fk_user=?")
+	}
+}
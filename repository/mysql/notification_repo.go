@@ -0,0 +1,157 @@
+package mysql
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pintobikez/popmeet/api/models"
+)
+
+// InsertNotification Inserts a notification into the user inbox
+func (r *Client) InsertNotification(n *models.Notification) error {
+
+	var data []byte
+	var err error
+
+	if len(n.Data) > 0 {
+		if data, err = json.Marshal(n.Data); err != nil {
+			return err
+		}
+	}
+
+	stmt, err := r.db.Prepare("INSERT INTO `notification` (fk_user,type,title,body,data,created_at) VALUES (?,?,?,?,?,now())")
+	if err != nil {
+		return fmt.Errorf("Error in insert notification prepared statement: %s", err.Error())
+	}
+
+	res, err := stmt.Exec(n.UserID, n.Type, n.Title, n.Body, string(data))
+	defer stmt.Close()
+
+	if err != nil {
+		return fmt.Errorf("Error in insert notification for user id %d: %s", n.UserID, err.Error())
+	}
+
+	n.ID, _ = res.LastInsertId()
+
+	return r.db.QueryRow("SELECT created_at FROM notification WHERE id=?", n.ID).Scan(&n.CreatedAt)
+}
+
+// GetNotificationsByUserId Gets the notifications of a given user, newest first
+func (r *Client) GetNotificationsByUserId(id int64, unreadOnly bool, limit int) ([]*models.Notification, error) {
+
+	resp := []*models.Notification{}
+
+	query := "SELECT id,fk_user,type,title,body,data,created_at,read_at FROM notification WHERE fk_user=?"
+	if unreadOnly {
+		query += " AND read_at IS NULL"
+	}
+	query += " ORDER BY id DESC LIMIT ?"
+
+	rows, err := r.db.Query(query, id, limit)
+	if err != nil {
+		return resp, err
+	}
+
+	for rows.Next() {
+		var data *string
+		var n = new(models.Notification)
+
+		err = rows.Scan(&n.ID, &n.UserID, &n.Type, &n.Title, &n.Body, &data, &n.CreatedAt, &n.ReadAt)
+		if err != nil {
+			defer rows.Close()
+			return resp, fmt.Errorf("Error reading rows: %s", err.Error())
+		}
+
+		if data != nil && *data != "" {
+			if err = json.Unmarshal([]byte(*data), &n.Data); err != nil {
+				defer rows.Close()
+				return resp, fmt.Errorf("Error reading notification %d data: %s", n.ID, err.Error())
+			}
+		}
+
+		resp = append(resp, n)
+	}
+
+	rows.Close()
+
+	return resp, nil
+}
+
+// MarkNotificationsAsRead Sets the read date of a notification of a given user, or of all of them when id is zero
+func (r *Client) MarkNotificationsAsRead(idUser int64, id int64) error {
+
+	query := "UPDATE `notification` SET read_at=now() WHERE fk_user=? AND read_at IS NULL"
+	args := []interface{}{idUser}
+	if id > 0 {
+		query += " AND id=?"
+		args = append(args, id)
+	}
+
+	stmt, err := r.db.Prepare(query)
+	if err != nil {
+		return fmt.Errorf("Error in mark notifications as read prepared statement: %s", err.Error())
+	}
+
+	_, err = stmt.Exec(args...)
+	defer stmt.Close()
+
+	if err != nil {
+		return fmt.Errorf("Error marking notifications of user %d as read: %s", idUser, err.Error())
+	}
+
+	return nil
+}
+
+// GetNotificationPreferencesByUserId Gets the notification channel preferences of a given user
+func (r *Client) GetNotificationPreferencesByUserId(id int64) ([]*models.NotificationPreference, error) {
+
+	resp := []*models.NotificationPreference{}
+
+	rows, err := r.db.Query("SELECT type,channel,enabled FROM notification_preference WHERE fk_user=?", id)
+	if err != nil {
+		return resp, err
+	}
+
+	for rows.Next() {
+		var n = new(models.NotificationPreference)
+
+		err = rows.Scan(&n.Type, &n.Channel, &n.Enabled)
+		if err != nil {
+			defer rows.Close()
+			return resp, fmt.Errorf("Error reading rows: %s", err.Error())
+		}
+
+		resp = append(resp, n)
+	}
+
+	rows.Close()
+
+	return resp, nil
+}
+
+// UpdateNotificationPreferences Inserts or updates the given notification channel preferences of a user
+func (r *Client) UpdateNotificationPreferences(id int64, ps []*models.NotificationPreference) error {
+	// its own transaction, the client is used by many requests at once
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare("INSERT INTO `notification_preference` VALUES (?,?,?,?,now()) ON DUPLICATE KEY UPDATE enabled=VALUES(enabled),updated_at=now()")
+	if err != nil {
+		return fmt.Errorf("Error in update notification preferences prepared statement: %s", err.Error())
+	}
+	defer stmt.Close()
+
+	for _, p := range ps {
+		if _, err = stmt.Exec(id, p.Type, p.Channel, p.Enabled); err != nil {
+			return fmt.Errorf("Error updating notification preferences for userID %d : %s", id, err.Error())
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("Error updating notification preferences for userID %d : %s", id, err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,220 @@
+package mysql
+
+import (
+	"fmt"
+	"github.com/pintobikez/popmeet/api/models"
+	serror "github.com/pintobikez/popmeet/errors"
+	"time"
+)
+
+// InsertReport Inserts a report into the report table
+func (r *Client) InsertReport(rp *models.Report) error {
+
+	var idUser, idEvent int64
+	if rp.User != nil {
+		idUser = rp.User.ID
+	}
+	if rp.Event != nil {
+		idEvent = rp.Event.ID
+	}
+
+	stmt, err := r.db.Prepare("INSERT INTO `report` (fk_reporter,fk_user,fk_event,reason,status,created_at,updated_at) VALUES (?,?,?,?,?,now(),now())")
+	if err != nil {
+		return fmt.Errorf("Error in insert report prepared statement: %s", err.Error())
+	}
+
+	res, err := stmt.Exec(rp.Reporter.ID, nullInt64(idUser), nullInt64(idEvent), rp.Reason, models.ReportStatusOpen)
+	defer stmt.Close()
+
+	if err != nil {
+		return fmt.Errorf("Error in insert report for user id %d: %w", rp.Reporter.ID, typed(err))
+	}
+
+	rp.ID, _ = res.LastInsertId()
+	rp.Status = models.ReportStatusOpen
+
+	return r.db.QueryRow("SELECT created_at FROM report WHERE id=?", rp.ID).Scan(&rp.CreatedAt)
+}
+
+// nullInt64 converts a zero id into a NULL value
+func nullInt64(v int64) interface{} {
+	if v <= 0 {
+		return nil
+	}
+	return v
+}
+
+const reportSelect = "SELECT rp.id,rp.reason,rp.status,rp.created_at,rp.updated_at,rr.id,rr.name," +
+	"u.id,u.name,u.active,e.id,e.location,e.start_datetime,e.end_datetime,e.active " +
+	"FROM report rp INNER JOIN user rr ON rp.fk_reporter=rr.id " +
+	"LEFT JOIN user u ON rp.fk_user=u.id LEFT JOIN event e ON rp.fk_event=e.id "
+
+// GetReportById Gets a report by its id with the complete reported user or event
+func (r *Client) GetReportById(id int64) (*models.Report, error) {
+
+	resp, err := r.queryReports(reportSelect+"WHERE rp.id=?", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp) == 0 {
+		return nil, serror.ErrNotFound.WithDetail("Report with id %d not found", id)
+	}
+	rp := resp[0]
+
+	if rp.User != nil {
+		if rp.User, err = r.GetUserById(rp.User.ID); err != nil {
+			return nil, err
+		}
+		// the profile is optional
+		rp.User.Profile, _ = r.GetUserProfileByUserId(rp.User.ID)
+	}
+
+	if rp.Event != nil {
+		if rp.Event, err = r.GetEventById(rp.Event.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return rp, nil
+}
+
+// GetReportsByStatus Gets all the reports with a given status, oldest first
+func (r *Client) GetReportsByStatus(status string) ([]*models.Report, error) {
+	return r.queryReports(reportSelect+"WHERE rp.status=? ORDER BY rp.id ASC", status)
+}
+
+// GetReportsByTarget Gets all the reports about a given user or event
+func (r *Client) GetReportsByTarget(idUser int64, idEvent int64) ([]*models.Report, error) {
+	return r.queryReports(reportSelect+"WHERE rp.fk_user=? OR rp.fk_event=? ORDER BY rp.id DESC", idUser, idEvent)
+}
+
+// ResolveReport Records a moderation action, applies it and updates the report status.
+// Fails with a conflict when the report is no longer open, resolved by another moderator
+func (r *Client) ResolveReport(ac *models.ModerationAction) error {
+	var idUser, idEvent *int64
+	var current string
+
+	status, ok := models.ModerationReportStatus[ac.Action]
+	if !ok {
+		return fmt.Errorf("Invalid moderation action %s", ac.Action)
+	}
+
+	// a transaction of its own, the client is shared with the requests
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// the status is read under the lock, only one of the moderators resolving at once applies the action
+	err = tx.QueryRow("SELECT fk_user,fk_event,status FROM report WHERE id=? FOR UPDATE", ac.ReportID).Scan(&idUser, &idEvent, &current)
+	if err != nil {
+		return fmt.Errorf("Error reading report %d: %w", ac.ReportID, typed(err))
+	}
+	if current != models.ReportStatusOpen {
+		return serror.ErrConflict.WithDetail("Report %d is already resolved", ac.ReportID)
+	}
+
+	res, err := tx.Exec("INSERT INTO `moderation_action` (fk_report,fk_moderator,action,reason,created_at) VALUES (?,?,?,?,now())", ac.ReportID, ac.Moderator.ID, ac.Action, ac.Reason)
+	if err != nil {
+		return fmt.Errorf("Error in insert moderation action for report %d: %s", ac.ReportID, err.Error())
+	}
+	ac.ID, _ = res.LastInsertId()
+
+	switch ac.Action {
+	case models.ModerationDeactivateUser:
+		if idUser == nil {
+			return fmt.Errorf("Report %d has no reported user", ac.ReportID)
+		}
+		if _, err = tx.Exec("UPDATE `user` SET active=0,updated_at=now() WHERE id=?", *idUser); err != nil {
+			return fmt.Errorf("Could not deactivate userID %d : %s", *idUser, err.Error())
+		}
+		// the tokens already issued are revoked
+		if _, err = tx.Exec("UPDATE `user_security` SET session_version=session_version+1,updated_at=now() WHERE fk_user=?", *idUser); err != nil {
+			return fmt.Errorf("Could not end the sessions of userID %d : %s", *idUser, err.Error())
+		}
+	case models.ModerationDeactivateEvent:
+		if idEvent == nil {
+			return fmt.Errorf("Report %d has no reported event", ac.ReportID)
+		}
+		if _, err = tx.Exec("UPDATE `event` SET active=0 WHERE id=?", *idEvent); err != nil {
+			return fmt.Errorf("Could not deactivate eventID %d : %s", *idEvent, err.Error())
+		}
+	}
+
+	if _, err = tx.Exec("UPDATE `report` SET status=?,updated_at=now() WHERE id=?", status, ac.ReportID); err != nil {
+		return fmt.Errorf("Could not update reportID %d : %s", ac.ReportID, err.Error())
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("Could not resolve reportID %d : %s", ac.ReportID, err.Error())
+	}
+
+	return nil
+}
+
+// GetModerationActionsByReportId Gets the moderation actions taken on a given report
+func (r *Client) GetModerationActionsByReportId(id int64) ([]*models.ModerationAction, error) {
+
+	resp := []*models.ModerationAction{}
+
+	rows, err := r.db.Query("SELECT ma.id,ma.fk_report,ma.action,ma.reason,ma.created_at,u.id,u.name FROM moderation_action ma INNER JOIN user u ON ma.fk_moderator=u.id WHERE ma.fk_report=? ORDER BY ma.id ASC", id)
+	if err != nil {
+		return resp, err
+	}
+
+	for rows.Next() {
+		var n = &models.ModerationAction{Moderator: new(models.User)}
+
+		err = rows.Scan(&n.ID, &n.ReportID, &n.Action, &n.Reason, &n.CreatedAt, &n.Moderator.ID, &n.Moderator.Name)
+		if err != nil {
+			defer rows.Close()
+			return resp, fmt.Errorf("Error reading rows: %s", err.Error())
+		}
+
+		resp = append(resp, n)
+	}
+
+	rows.Close()
+
+	return resp, nil
+}
+
+// queryReports runs a report query based on reportSelect and fills in the results
+func (r *Client) queryReports(query string, args ...interface{}) ([]*models.Report, error) {
+
+	resp := []*models.Report{}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return resp, err
+	}
+
+	for rows.Next() {
+		var uID, eID *int64
+		var uName, eLocation *string
+		var uActive, eActive *bool
+		var eStart, eEnd *time.Time
+		var n = &models.Report{Reporter: new(models.User)}
+
+		err = rows.Scan(&n.ID, &n.Reason, &n.Status, &n.CreatedAt, &n.UpdatedAt, &n.Reporter.ID, &n.Reporter.Name,
+			&uID, &uName, &uActive, &eID, &eLocation, &eStart, &eEnd, &eActive)
+		if err != nil {
+			defer rows.Close()
+			return resp, fmt.Errorf("Error reading rows: %s", err.Error())
+		}
+
+		if uID != nil {
+			n.User = &models.User{ID: *uID, Name: *uName, Active: *uActive}
+		}
+		if eID != nil {
+			n.Event = &models.Event{ID: *eID, Location: *eLocation, StartDate: *eStart, EndDate: *eEnd, Active: *eActive}
+		}
+
+		resp = append(resp, n)
+	}
+
+	rows.Close()
+
+	return resp, nil
+}
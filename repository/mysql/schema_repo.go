@@ -0,0 +1,156 @@
+package mysql
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	driver "github.com/go-sql-driver/mysql"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SchemaVersion is the version of the database schema expected by this build.
+// Every change to the schema is a new file in dbutil/migrations that bumps it
+const SchemaVersion = 9
+
+// migrationFile is the name of a migration file, the version followed by what it changes
+var migrationFile = regexp.MustCompile(`^(\d+)_(\w+)\.sql$`)
+
+// Migration is a change to the schema, its statements are applied in order
+type Migration struct {
+	Version    int
+	Name       string
+	Statements []string
+}
+
+// LoadMigrations Reads the migration files of the folder, sorted by version
+func LoadMigrations(fsys fs.FS) ([]*Migration, error) {
+
+	files, err := fs.Glob(fsys, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	ms := make([]*Migration, 0, len(files))
+	for _, file := range files {
+
+		parts := migrationFile.FindStringSubmatch(path.Base(file))
+		if parts == nil {
+			return nil, fmt.Errorf("Migration file %s is not named <version>_<name>.sql", file)
+		}
+		version, _ := strconv.Atoi(parts[1])
+
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+
+		m := &Migration{Version: version, Name: parts[2]}
+		var stmt strings.Builder
+		scanner := bufio.NewScanner(strings.NewReader(string(content)))
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "--") {
+				continue
+			}
+			stmt.WriteString(scanner.Text())
+			stmt.WriteString("\n")
+			// a statement ends with the line that ends with a semicolon
+			if strings.HasSuffix(line, ";") {
+				m.Statements = append(m.Statements, strings.TrimSuffix(strings.TrimSpace(stmt.String()), ";"))
+				stmt.Reset()
+			}
+		}
+		if strings.TrimSpace(stmt.String()) != "" {
+			return nil, fmt.Errorf("Migration file %s does not end its last statement with a semicolon", file)
+		}
+
+		ms = append(ms, m)
+	}
+
+	sort.Slice(ms, func(i, j int) bool { return ms[i].Version < ms[j].Version })
+	for i := 1; i < len(ms); i++ {
+		if ms[i].Version == ms[i-1].Version {
+			return nil, fmt.Errorf("Migrations %s and %s have the same version %d", ms[i-1].Name, ms[i].Name, ms[i].Version)
+		}
+	}
+
+	return ms, nil
+}
+
+// Migrate Applies the migrations newer than the version of the database, in order.
+// A version is only recorded once all the statements of its migration succeeded, a failed migration
+// is applied again on the next start. Returns the versions applied
+func (r *Client) Migrate(ctx context.Context, ms []*Migration) ([]int, error) {
+
+	// a connection of its own, the lock belongs to it and keeps the other instances waiting
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var locked int
+	if err = conn.QueryRowContext(ctx, "SELECT GET_LOCK('popmeet_schema_migration', 60)").Scan(&locked); err != nil {
+		return nil, fmt.Errorf("Error in locking the schema migration: %s", err.Error())
+	}
+	if locked != 1 {
+		return nil, fmt.Errorf("Error in locking the schema migration: another migration is running")
+	}
+	defer conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK('popmeet_schema_migration')")
+
+	_, err = conn.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS `schema_migration` (`version` int(11) unsigned NOT NULL, `applied_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP, PRIMARY KEY (`version`)) ENGINE=InnoDB DEFAULT CHARSET=utf8")
+	if err != nil {
+		return nil, fmt.Errorf("Error in creating the schema_migration table: %s", err.Error())
+	}
+
+	var current int
+	if err = conn.QueryRowContext(ctx, "SELECT COALESCE(MAX(version),0) FROM `schema_migration`").Scan(&current); err != nil {
+		return nil, fmt.Errorf("Error in reading the schema version: %s", err.Error())
+	}
+
+	applied := make([]int, 0)
+	for _, m := range ms {
+		if m.Version <= current {
+			continue
+		}
+		for i, stmt := range m.Statements {
+			if _, err = conn.ExecContext(ctx, stmt); err != nil && !alreadyApplied(err) {
+				return applied, fmt.Errorf("Error in migration %d %s, statement %d: %s", m.Version, m.Name, i+1, err.Error())
+			}
+		}
+		if _, err = conn.ExecContext(ctx, "INSERT INTO schema_migration (version) VALUES (?)", m.Version); err != nil {
+			return applied, fmt.Errorf("Error in recording the migration %d %s: %s", m.Version, m.Name, err.Error())
+		}
+		applied = append(applied, m.Version)
+	}
+
+	return applied, nil
+}
+
+// alreadyApplied Checks if the error is of a change already made, by a migration that stopped midway
+func alreadyApplied(err error) bool {
+	if me, ok := err.(*driver.MySQLError); ok {
+		switch me.Number {
+		case errTableExists, errDuplicateColumn, errDuplicateKey, errCantDropKey, errDuplicateFk:
+			return true
+		}
+	}
+	return false
+}
+
+// SchemaVersion Gets the last migration applied to the database, 0 when none was applied
+func (r *Client) SchemaVersion(ctx context.Context) (int, error) {
+
+	var version int
+	err := r.db.QueryRowContext(ctx, "SELECT COALESCE(MAX(version),0) FROM `schema_migration`").Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("Error in reading the schema version: %s", err.Error())
+	}
+
+	return version, nil
+}
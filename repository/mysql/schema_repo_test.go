@@ -0,0 +1,52 @@
+package mysql
+
+import (
+	"github.com/pintobikez/popmeet/dbutil"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+/* Test for LoadMigrations method, the migrations shipped are numbered up to the version expected */
+func TestLoadMigrations(t *testing.T) {
+
+	ms, err := LoadMigrations(dbutil.Migrations)
+
+	// Assertions
+	assert.Nil(t, err)
+	assert.Len(t, ms, SchemaVersion)
+	for i, m := range ms {
+		assert.Equal(t, i+1, m.Version)
+		assert.NotEmpty(t, m.Statements)
+		for _, stmt := range m.Statements {
+			assert.False(t, strings.HasSuffix(stmt, ";"))
+			assert.NotContains(t, stmt, "--")
+		}
+	}
+}
+
+/*
+Provider struct for LoadMigrations method with invalid files
+*/
+type providerLoadMigrationsInvalid struct {
+	name    string
+	content string
+}
+
+var testProviderLoadMigrationsInvalid = []providerLoadMigrationsInvalid{
+	{"migrations/first.sql", "ALTER TABLE `user` ADD COLUMN `a` int;"},    // no version
+	{"migrations/001_first.sql", "ALTER TABLE `user` ADD COLUMN `a` int"}, // no semicolon
+}
+
+/* Test for LoadMigrations method with invalid files */
+func TestLoadMigrationsInvalid(t *testing.T) {
+
+	for _, pair := range testProviderLoadMigrationsInvalid {
+
+		_, err := LoadMigrations(fstest.MapFS{pair.name: &fstest.MapFile{Data: []byte(pair.content)}})
+
+		// Assertions
+		assert.NotNil(t, err)
+	}
+}
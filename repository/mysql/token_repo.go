@@ -0,0 +1,157 @@
+package mysql
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/pintobikez/popmeet/api/models"
+	serror "github.com/pintobikez/popmeet/errors"
+)
+
+// SetEmailVerified Marks the email of a given user as verified
+func (r *Client) SetEmailVerified(id int64) error {
+
+	stmt, err := r.db.Prepare("UPDATE `user` SET email_verified_at=now(),updated_at=now() WHERE id=? AND email_verified_at IS NULL")
+	if err != nil {
+		return fmt.Errorf("Error in verify email prepared statement: %s", err.Error())
+	}
+
+	_, err = stmt.Exec(id)
+	defer stmt.Close()
+
+	if err != nil {
+		return fmt.Errorf("Could not verify email of userID %d : %s", id, err.Error())
+	}
+
+	return nil
+}
+
+// SetPendingEmail Sets the target of the given token as the new email of its user, waiting for its confirmation,
+// and inserts the token invalidating the previous ones. Both change together, a token only confirms its own target
+func (r *Client) SetPendingEmail(t *models.UserToken) error {
+	// a local transaction, concurrent requests share the client
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec("UPDATE `user` SET pending_email=?,updated_at=now() WHERE id=?", t.Target, t.UserID); err != nil {
+		return fmt.Errorf("Could not set the pending email of userID %d : %s", t.UserID, err.Error())
+	}
+
+	if err = insertUserToken(tx, t); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("Could not set the pending email of userID %d : %s", t.UserID, err.Error())
+	}
+
+	return nil
+}
+
+// ConfirmPendingEmail Makes the pending email of a given user its verified email, when it is the given one.
+// Fails with a conflict when another user has the email by then
+func (r *Client) ConfirmPendingEmail(id int64, email string) error {
+
+	stmt, err := r.db.Prepare("UPDATE `user` SET email=pending_email,pending_email=NULL,email_verified_at=now(),updated_at=now() WHERE id=? AND pending_email=?")
+	if err != nil {
+		return fmt.Errorf("Error in confirm pending email prepared statement: %s", err.Error())
+	}
+
+	res, err := stmt.Exec(id, email)
+	defer stmt.Close()
+
+	if err != nil {
+		return fmt.Errorf("Could not confirm the pending email of userID %d : %w", id, typed(err))
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return serror.ErrNotFound.WithDetail("User with id %d has no pending email %s", id, email)
+	}
+
+	return nil
+}
+
+// InsertUserToken Inserts a token, invalidating the unused tokens of the same purpose of the user
+func (r *Client) InsertUserToken(t *models.UserToken) error {
+	// a local transaction, concurrent requests share the client
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = insertUserToken(tx, t); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("Error in insert %s token for user id %d: %s", t.Purpose, t.UserID, err.Error())
+	}
+
+	return nil
+}
+
+// insertUserToken Inserts a token in the given transaction, invalidating the unused tokens of the same purpose of the user
+func insertUserToken(tx *sql.Tx, t *models.UserToken) error {
+
+	_, err := tx.Exec("UPDATE `user_token` SET used_at=now() WHERE fk_user=? AND purpose=? AND used_at IS NULL", t.UserID, t.Purpose)
+	if err != nil {
+		return fmt.Errorf("Error invalidating %s tokens for user id %d: %s", t.Purpose, t.UserID, err.Error())
+	}
+
+	var target interface{}
+	if t.Target != "" {
+		target = t.Target
+	}
+
+	res, err := tx.Exec("INSERT INTO `user_token` (fk_user,purpose,target,token_hash,expires_at,created_at) VALUES (?,?,?,?,?,now())", t.UserID, t.Purpose, target, t.Hash, t.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("Error in insert %s token for user id %d: %s", t.Purpose, t.UserID, err.Error())
+	}
+
+	t.ID, _ = res.LastInsertId()
+
+	return nil
+}
+
+// GetUserTokenByHash Gets a token by its purpose and hash
+func (r *Client) GetUserTokenByHash(purpose string, hash string) (*models.UserToken, error) {
+
+	resp := &models.UserToken{}
+
+	err := r.db.QueryRow("SELECT id,fk_user,purpose,COALESCE(target,''),token_hash,expires_at,used_at FROM user_token WHERE purpose=? AND token_hash=?", purpose, hash).
+		Scan(&resp.ID, &resp.UserID, &resp.Purpose, &resp.Target, &resp.Hash, &resp.ExpiresAt, &resp.UsedAt)
+	if err == sql.ErrNoRows {
+		return nil, serror.ErrNotFound.WithDetail("Token not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error reading %s token %s", purpose, err.Error())
+	}
+
+	return resp, nil
+}
+
+// UseUserToken Marks a token as used. Returns false when it was already used
+func (r *Client) UseUserToken(id int64) (bool, error) {
+
+	stmt, err := r.db.Prepare("UPDATE `user_token` SET used_at=now() WHERE id=? AND used_at IS NULL")
+	if err != nil {
+		return false, fmt.Errorf("Error in use token prepared statement: %s", err.Error())
+	}
+
+	res, err := stmt.Exec(id)
+	defer stmt.Close()
+
+	if err != nil {
+		return false, fmt.Errorf("Could not use tokenID %d : %s", id, err.Error())
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return n == 1, nil
+}
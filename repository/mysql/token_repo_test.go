@@ -0,0 +1,47 @@
+package mysql
+
+import (
+	"github.com/pintobikez/popmeet/api/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+/* Test for SetPendingEmail method, the pending email and the token sent to it are stored together */
+func TestSetPendingEmail(t *testing.T) {
+
+	r, err := newFakeClient()
+	assert.Nil(t, err)
+	fakeStatements()
+
+	tk := &models.UserToken{UserID: 1, Purpose: models.TokenChangeEmail, Hash: "hash", ExpiresAt: time.Now(), Target: "new@popmeet.com"}
+	err = r.SetPendingEmail(tk)
+	statements := fakeStatements()
+
+	// Assertions
+	assert.Nil(t, err)
+	if assert.Len(t, statements, 5) {
+		assert.Equal(t, "BEGIN", statements[0])
+		assert.Contains(t, statements[1], "SET pending_email=?")
+		assert.Contains(t, statements[2], "UPDATE `user_token` SET used_at=now()")
+		assert.Contains(t, statements[3], "INSERT INTO `user_token` (fk_user,purpose,target,")
+		assert.Equal(t, "COMMIT", statements[4])
+	}
+}
+
+/* Test for ConfirmPendingEmail method, only the email the token was sent to is confirmed */
+func TestConfirmPendingEmail(t *testing.T) {
+
+	r, err := newFakeClient()
+	assert.Nil(t, err)
+	fakeStatements()
+
+	err = r.ConfirmPendingEmail(1, "new@popmeet.com")
+	statements := fakeStatements()
+
+	// Assertions
+	assert.Nil(t, err)
+	if assert.Len(t, statements, 1) {
+		assert.Contains(t, statements[0], "WHERE id=? AND pending_email=?")
+	}
+}
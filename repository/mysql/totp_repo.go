@@ -0,0 +1,125 @@
+package mysql
+
+import (
+	"fmt"
+)
+
+// SetTotpSecret Stores a new TOTP secret for the user, pending confirmation
+func (r *Client) SetTotpSecret(userId int64, secret string) error {
+
+	stmt, err := r.db.Prepare("UPDATE `user_security` SET totp_secret=?,totp_enabled_at=NULL,totp_last_step=NULL,updated_at=now() WHERE fk_user=? AND totp_enabled_at IS NULL")
+	if err != nil {
+		return fmt.Errorf("Error in set totp secret prepared statement: %s", err.Error())
+	}
+
+	_, err = stmt.Exec(secret, userId)
+	defer stmt.Close()
+
+	if err != nil {
+		return fmt.Errorf("Could not set totp secret of userID %d : %s", userId, err.Error())
+	}
+
+	return nil
+}
+
+// EnableTotp Enables the TOTP of the user replacing its recovery codes with the given ones
+func (r *Client) EnableTotp(userId int64, codeHashes []string) error {
+	// not the shared r.tx, other requests use the client at the same time
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("UPDATE `user_security` SET totp_enabled_at=now(),updated_at=now() WHERE fk_user=? AND totp_secret IS NOT NULL", userId)
+	if err != nil {
+		return fmt.Errorf("Error enabling totp for user id %d: %s", userId, err.Error())
+	}
+
+	if _, err = tx.Exec("DELETE FROM `user_recovery_code` WHERE fk_user=?", userId); err != nil {
+		return fmt.Errorf("Error deleting recovery codes for user id %d: %s", userId, err.Error())
+	}
+
+	for _, h := range codeHashes {
+		if _, err = tx.Exec("INSERT INTO `user_recovery_code` (fk_user,code_hash,created_at) VALUES (?,?,now())", userId, h); err != nil {
+			return fmt.Errorf("Error in insert recovery code for user id %d: %s", userId, err.Error())
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("Error enabling totp for user id %d: %s", userId, err.Error())
+	}
+
+	return nil
+}
+
+// DisableTotp Removes the TOTP secret and the recovery codes of the user
+func (r *Client) DisableTotp(userId int64) error {
+	// not the shared r.tx, other requests use the client at the same time
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("UPDATE `user_security` SET totp_secret=NULL,totp_enabled_at=NULL,totp_last_step=NULL,updated_at=now() WHERE fk_user=?", userId)
+	if err != nil {
+		return fmt.Errorf("Error disabling totp for user id %d: %s", userId, err.Error())
+	}
+
+	if _, err = tx.Exec("DELETE FROM `user_recovery_code` WHERE fk_user=?", userId); err != nil {
+		return fmt.Errorf("Error deleting recovery codes for user id %d: %s", userId, err.Error())
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("Error disabling totp for user id %d: %s", userId, err.Error())
+	}
+
+	return nil
+}
+
+// UseTotpStep Records the time step of a used TOTP code. Returns false when it, or a later one, was already used
+func (r *Client) UseTotpStep(userId int64, step int64) (bool, error) {
+
+	stmt, err := r.db.Prepare("UPDATE `user_security` SET totp_last_step=? WHERE fk_user=? AND (totp_last_step IS NULL OR totp_last_step<?)")
+	if err != nil {
+		return false, fmt.Errorf("Error in use totp step prepared statement: %s", err.Error())
+	}
+
+	res, err := stmt.Exec(step, userId, step)
+	defer stmt.Close()
+
+	if err != nil {
+		return false, fmt.Errorf("Could not use totp step of userID %d : %s", userId, err.Error())
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return n == 1, nil
+}
+
+// UseRecoveryCode Marks a recovery code of the user as used. Returns false when it doesn't exist or was already used
+func (r *Client) UseRecoveryCode(userId int64, hash string) (bool, error) {
+
+	stmt, err := r.db.Prepare("UPDATE `user_recovery_code` SET used_at=now() WHERE fk_user=? AND code_hash=? AND used_at IS NULL")
+	if err != nil {
+		return false, fmt.Errorf("Error in use recovery code prepared statement: %s", err.Error())
+	}
+
+	res, err := stmt.Exec(userId, hash)
+	defer stmt.Close()
+
+	if err != nil {
+		return false, fmt.Errorf("Could not use recovery code of userID %d : %s", userId, err.Error())
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return n == 1, nil
+}
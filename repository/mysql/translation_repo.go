@@ -0,0 +1,103 @@
+package mysql
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/pintobikez/popmeet/api/models"
+	serror "github.com/pintobikez/popmeet/errors"
+	"strings"
+	"time"
+)
+
+// InsertLanguage Inserts a new language, its ISO 639-1 code is unique
+func (r *Client) InsertLanguage(l *models.Language) error {
+
+	stmt, err := r.db.Prepare("INSERT INTO `language` (name,name_iso2,name_iso3) VALUES (?,?,?)")
+	if err != nil {
+		return fmt.Errorf("Error in insert language prepared statement: %s", err.Error())
+	}
+
+	res, err := stmt.Exec(l.Name, strings.ToUpper(l.NameIso2), strings.ToUpper(l.NameIso3))
+	defer stmt.Close()
+
+	if err != nil {
+		return fmt.Errorf("Error in insert language %s: %w", l.NameIso2, typed(err))
+	}
+
+	l.ID, _ = res.LastInsertId()
+
+	return nil
+}
+
+// GetUserLanguage Gets the ISO 639-1 code of the profile language of a user, in lower case. Empty when the user has no profile
+func (r *Client) GetUserLanguage(userId int64) (string, error) {
+
+	var lang string
+	err := r.db.QueryRow("SELECT la.name_iso2 FROM user_profile up INNER JOIN language la ON up.fk_language=la.id WHERE up.fk_user=?", userId).Scan(&lang)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return strings.ToLower(lang), nil
+}
+
+// GetAllTranslations Gets the translations managed by the admins
+func (r *Client) GetAllTranslations() ([]*models.Translation, error) {
+
+	resp := []*models.Translation{}
+
+	rows, err := r.db.Query("SELECT la.name_iso2,t.`key`,t.text,t.updated_at FROM translation t INNER JOIN language la ON t.fk_language=la.id")
+	if err != nil {
+		return resp, err
+	}
+
+	for rows.Next() {
+		var n = new(models.Translation)
+
+		err = rows.Scan(&n.Language, &n.Key, &n.Text, &n.UpdatedAt)
+		if err != nil {
+			defer rows.Close()
+			return resp, fmt.Errorf("Error reading rows: %s", err.Error())
+		}
+
+		n.Language = strings.ToLower(n.Language)
+		resp = append(resp, n)
+	}
+
+	rows.Close()
+
+	return resp, nil
+}
+
+// SaveTranslation Inserts or replaces the text of a key in a language
+func (r *Client) SaveTranslation(t *models.Translation) error {
+
+	var idLanguage int64
+	err := r.db.QueryRow("SELECT id FROM language WHERE name_iso2=?", t.Language).Scan(&idLanguage)
+	if err == sql.ErrNoRows {
+		return serror.ErrNotFound.WithDetail("Language %s not found", t.Language)
+	}
+	if err != nil {
+		return err
+	}
+
+	stmt, err := r.db.Prepare("INSERT INTO `translation` (fk_language,`key`,text,updated_at) VALUES (?,?,?,?) ON DUPLICATE KEY UPDATE text=VALUES(text),updated_at=VALUES(updated_at)")
+	if err != nil {
+		return fmt.Errorf("Error in save translation prepared statement: %s", err.Error())
+	}
+
+	now := time.Now().UTC()
+	_, err = stmt.Exec(idLanguage, t.Key, t.Text, now)
+	defer stmt.Close()
+
+	if err != nil {
+		return fmt.Errorf("Error in save translation %s of language %s: %w", t.Key, t.Language, typed(err))
+	}
+
+	t.UpdatedAt = &now
+
+	return nil
+}
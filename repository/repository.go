@@ -1,11 +1,16 @@
 package repository
 
-import "github.com/pintobikez/popmeet/api/models"
+import (
+	"context"
+	"github.com/pintobikez/popmeet/api/models"
+	"time"
+)
 
 type Repository interface {
 	Connect() error
 	Disconnect()
-	Health() error
+	Health(ctx context.Context) error
+	SchemaVersion(ctx context.Context) (int, error)
 	// User - Interests
 	UpdateUserInterests(interests []*models.Interest, id int64) error
 	GetAllInterestByUserProfileId(id int64) ([]*models.Interest, error)
@@ -21,20 +26,47 @@ type Repository interface {
 	GetUserByEmail(email string) (*models.User, error)
 	// User profile methods
 	InsertUserProfile(u *models.UserProfile, id int64) error
-	UpdateUserProfile(u *models.UserProfile) error
+	UpdateUserProfile(u *models.UserProfile, id int64) error
 	GetUserProfileByUserId(id int64) (*models.UserProfile, error)
 	// Languages
 	GetLanguageById(id int64) (*models.Language, error)
 	GetAllLanguage() ([]*models.Language, error)
+	InsertLanguage(l *models.Language) error
+	SetLanguageActive(id int64, active bool) error
+	GetUserLanguage(userId int64) (string, error)
+	// Translations
+	GetAllTranslations() ([]*models.Translation, error)
+	SaveTranslation(t *models.Translation) error
+	SetEmailVerified(id int64) error
+	SetPendingEmail(t *models.UserToken) error
+	ConfirmPendingEmail(id int64, email string) error
+	// User tokens
+	InsertUserToken(t *models.UserToken) error
+	GetUserTokenByHash(purpose string, hash string) (*models.UserToken, error)
+	UseUserToken(id int64) (bool, error)
 	// UserSecurity
 	InsertUserSecurity(u *models.UserSecurity, id int64) error
-	UpdateUserSecurity(u *models.UserSecurity) error
+	UpdateUserSecurity(u *models.UserSecurity, id int64) error
+	ChangePassword(id int64, hash string) (int64, error)
+	GetSessionVersion(userId int64) (int64, error)
+	SetUserRole(id int64, role string) error
 	GetSecurityInfoByUserId(id int64) (*models.UserSecurity, error)
+	// Two factor authentication
+	SetTotpSecret(userId int64, secret string) error
+	EnableTotp(userId int64, codeHashes []string) error
+	DisableTotp(userId int64) error
+	UseTotpStep(userId int64, step int64) (bool, error)
+	UseRecoveryCode(userId int64, hash string) (bool, error)
 	// LoginProvider
 	GetLoginProviderById(id int64) (*models.LoginProvider, error)
 	GetAllLoginProvider() ([]*models.LoginProvider, error)
+	InsertLoginProvider(p *models.LoginProvider) error
+	UpdateLoginProvider(p *models.LoginProvider) error
 	//User login updates
 	UpdateLoginData(u *models.UserSecurity) error
+	RecordLoginFailure(id int64, machine string, at time.Time) (int64, error)
+	LockUserSecurity(id int64, until time.Time) error
+	ResetLoginFailures(id int64) error
 	// Event methods
 	AddUserToEvent(idEvent int64, idUser int64) error
 	RemoveUserFromEvent(idEvent int64, idUser int64) error
@@ -43,4 +75,60 @@ type Repository interface {
 	FindEventById(id int64) (bool, error)
 	GetEventById(id int64) (*models.Event, error)
 	GetUserEventsByUserId(id int64) ([]*models.Event, error)
+	CompletePastEvents(now time.Time) (int64, error)
+	ArchiveEvents(before time.Time, limit int) (int64, error)
+	// User relations
+	HaveSharedEvent(idUser int64, idOther int64) (bool, error)
+	IsUserBlocked(idUser int64, idOther int64) (bool, error)
+	BlockUser(idUser int64, idBlocked int64) error
+	UnblockUser(idUser int64, idBlocked int64) error
+	GetBlockedUsersByUserId(id int64) ([]*models.User, error)
+	GetBlockRelatedUserIds(id int64) ([]int64, error)
+	// Follows
+	FollowUser(idUser int64, idFollowed int64) error
+	UnfollowUser(idUser int64, idFollowed int64) error
+	GetFollowersByUserId(id int64) ([]*models.User, error)
+	GetFollowingByUserId(id int64) ([]*models.User, error)
+	GetFollowerIdsByUserId(id int64) ([]int64, error)
+	AreMutualFollowers(idUser int64, idOther int64) (bool, error)
+	GetFeedEventsByUserId(id int64, limit int) ([]*models.Event, error)
+	// Reports
+	InsertReport(rp *models.Report) error
+	GetReportById(id int64) (*models.Report, error)
+	GetReportsByStatus(status string) ([]*models.Report, error)
+	GetReportsByTarget(idUser int64, idEvent int64) ([]*models.Report, error)
+	// Moderation
+	ResolveReport(ac *models.ModerationAction) error
+	GetModerationActionsByReportId(id int64) ([]*models.ModerationAction, error)
+	// Messages
+	InsertMessage(m *models.Message) error
+	GetMessagesBetweenUsers(idUser int64, idPeer int64, before int64, limit int) ([]*models.Message, error)
+	GetConversationsByUserId(id int64) ([]*models.Conversation, error)
+	MarkMessagesAsRead(idUser int64, idPeer int64) error
+	CountUnreadMessages(id int64) (int64, error)
+	// Notifications
+	InsertNotification(n *models.Notification) error
+	GetNotificationsByUserId(id int64, unreadOnly bool, limit int) ([]*models.Notification, error)
+	MarkNotificationsAsRead(idUser int64, id int64) error
+	GetNotificationPreferencesByUserId(id int64) ([]*models.NotificationPreference, error)
+	UpdateNotificationPreferences(id int64, ps []*models.NotificationPreference) error
+	// Jobs
+	InsertJob(j *models.Job) error
+	ScheduleEventReminders(tp string, before time.Duration, now time.Time, window time.Duration) error
+	ClaimDueJobs(owner string, now time.Time, lease time.Time, limit int) ([]*models.Job, error)
+	RenewJobLease(id int64, owner string, lease time.Time) (bool, error)
+	CompleteJob(id int64) error
+	FailJob(id int64, msg string, retryAt time.Time, final bool) error
+	ResetExpiredJobs(now time.Time) (int64, error)
+	// Account erasure and data export
+	ScheduleUserDeletion(id int64, at time.Time, hostedEvents string) error
+	CancelUserDeletion(id int64) error
+	GetUsersToErase(now time.Time, limit int) ([]*models.AccountErasure, error)
+	EraseUser(id int64, hostedEvents string, now time.Time) ([]*models.HandedOverEvent, error)
+	GetHostedEventsByUserId(id int64) ([]*models.Event, error)
+	GetJoinedEventsByUserId(id int64) ([]*models.Event, error)
+	GetMessagesByUserId(id int64) ([]*models.Message, error)
+	// Audit log
+	InsertAuditEntry(e *models.AuditEntry) error
+	GetAuditEntries(f *models.AuditFilter) ([]*models.AuditEntry, error)
 }
@@ -0,0 +1,124 @@
+package secure
+
+import (
+	cnf "github.com/pintobikez/popmeet/config/structures"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMaxFailures    = 10
+	defaultLockoutMinutes = 30
+	defaultBackoffBase    = 1
+	defaultBackoffMax     = 300
+	defaultIpMaxFailures  = 50
+	defaultIpWindow       = 15
+	ipPruneSize           = 1000
+)
+
+type ipAttempts struct {
+	failures int
+	start    time.Time
+}
+
+// LoginGuard decides when a login can be attempted, slowing down the accounts
+// with failed attempts and throttling the IPs with too many failures
+type LoginGuard struct {
+	maxFailures   int64
+	lockout       time.Duration
+	backoffBase   time.Duration
+	backoffMax    time.Duration
+	ipMaxFailures int
+	ipWindow      time.Duration
+
+	mu  sync.Mutex
+	ips map[string]*ipAttempts
+}
+
+// NewLoginGuard Builds the guard, using the defaults for the values not configured
+func NewLoginGuard(c cnf.LoginGuardConfig) *LoginGuard {
+	return &LoginGuard{
+		maxFailures:   int64(orDefault(c.MaxFailures, defaultMaxFailures)),
+		lockout:       time.Duration(orDefault(c.LockoutMinutes, defaultLockoutMinutes)) * time.Minute,
+		backoffBase:   time.Duration(orDefault(c.BackoffBase, defaultBackoffBase)) * time.Second,
+		backoffMax:    time.Duration(orDefault(c.BackoffMax, defaultBackoffMax)) * time.Second,
+		ipMaxFailures: orDefault(c.IpMaxFailures, defaultIpMaxFailures),
+		ipWindow:      time.Duration(orDefault(c.IpWindow, defaultIpWindow)) * time.Minute,
+		ips:           make(map[string]*ipAttempts),
+	}
+}
+
+// AccountAllowed Checks if an account with the given failures can try to login at the given time.
+// Each failure doubles the wait since the last one, until the account gets locked
+func (g *LoginGuard) AccountAllowed(failures int64, lastFailed *time.Time, lockedUntil *time.Time, now time.Time) bool {
+
+	if lockedUntil != nil && now.Before(*lockedUntil) {
+		return false
+	}
+	if failures <= 0 || lastFailed == nil {
+		return true
+	}
+
+	return !now.Before(lastFailed.Add(g.backoff(failures)))
+}
+
+// LockUntil Gets the end of the lockout of an account after the given failures, nil when it isn't locked
+func (g *LoginGuard) LockUntil(failures int64, now time.Time) *time.Time {
+	if failures < g.maxFailures {
+		return nil
+	}
+	until := now.Add(g.lockout)
+	return &until
+}
+
+// IpAllowed Checks if the ip didn't reach the failures allowed in the window
+func (g *LoginGuard) IpAllowed(ip string, now time.Time) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	a, ok := g.ips[ip]
+	if !ok || now.Sub(a.start) >= g.ipWindow {
+		return true
+	}
+	return a.failures < g.ipMaxFailures
+}
+
+// IpFailed Records a failed login from the ip
+func (g *LoginGuard) IpFailed(ip string, now time.Time) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if len(g.ips) >= ipPruneSize {
+		for k, a := range g.ips {
+			if now.Sub(a.start) >= g.ipWindow {
+				delete(g.ips, k)
+			}
+		}
+	}
+
+	a, ok := g.ips[ip]
+	if !ok || now.Sub(a.start) >= g.ipWindow {
+		g.ips[ip] = &ipAttempts{failures: 1, start: now}
+		return
+	}
+	a.failures++
+}
+
+// backoff Gets the wait after the given failures
+func (g *LoginGuard) backoff(failures int64) time.Duration {
+	d := g.backoffBase
+	for i := int64(1); i < failures; i++ {
+		d *= 2
+		if d >= g.backoffMax {
+			return g.backoffMax
+		}
+	}
+	return d
+}
+
+func orDefault(v int, def int) int {
+	if v <= 0 {
+		return def
+	}
+	return v
+}
@@ -0,0 +1,75 @@
+package secure
+
+import (
+	strut "github.com/pintobikez/popmeet/config/structures"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+/*
+Provider struct for AccountAllowed method
+*/
+type providerAccountAllowed struct {
+	failures    int64
+	sinceFailed time.Duration
+	lockedFor   time.Duration
+	result      bool
+}
+
+var testProviderAccountAllowed = []providerAccountAllowed{
+	{0, 0, 0, true},                                 // no failures
+	{1, 500 * time.Millisecond, 0, false},           // first backoff
+	{1, time.Second, 0, true},                       // first backoff passed
+	{3, 3 * time.Second, 0, false},                  // backoff doubles
+	{3, 4 * time.Second, 0, true},                   // backoff doubles
+	{9, 5 * time.Minute, 0, true},                   // backoff is capped
+	{10, 10 * time.Minute, 20 * time.Minute, false}, // locked
+	{10, 10 * time.Minute, -time.Minute, true},      // lock expired
+}
+
+/* Test for AccountAllowed method */
+func TestAccountAllowed(t *testing.T) {
+
+	g := NewLoginGuard(strut.LoginGuardConfig{})
+	now := time.Date(2018, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	for _, pair := range testProviderAccountAllowed {
+
+		lastFailed := now.Add(-pair.sinceFailed)
+		var lockedUntil *time.Time
+		if pair.lockedFor != 0 {
+			until := now.Add(pair.lockedFor)
+			lockedUntil = &until
+		}
+
+		// Assertions
+		assert.Equal(t, pair.result, g.AccountAllowed(pair.failures, &lastFailed, lockedUntil, now))
+	}
+}
+
+/* Test for LockUntil method */
+func TestLockUntil(t *testing.T) {
+
+	g := NewLoginGuard(strut.LoginGuardConfig{MaxFailures: 3, LockoutMinutes: 10})
+	now := time.Date(2018, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	// Assertions
+	assert.Nil(t, g.LockUntil(2, now))
+	assert.Equal(t, now.Add(10*time.Minute), *g.LockUntil(3, now))
+}
+
+/* Test for IpAllowed method */
+func TestIpAllowed(t *testing.T) {
+
+	g := NewLoginGuard(strut.LoginGuardConfig{IpMaxFailures: 2, IpWindow: 1})
+	now := time.Date(2018, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	g.IpFailed("10.0.0.1", now)
+	assert.True(t, g.IpAllowed("10.0.0.1", now))
+
+	g.IpFailed("10.0.0.1", now)
+	assert.False(t, g.IpAllowed("10.0.0.1", now))
+	assert.True(t, g.IpAllowed("10.0.0.2", now))
+	assert.True(t, g.IpAllowed("10.0.0.1", now.Add(time.Minute)))
+}
@@ -0,0 +1,138 @@
+package secure
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	cnfs "github.com/pintobikez/popmeet/config/structures"
+	"strings"
+)
+
+const (
+	envelopePrefix = "env1"
+	dataKeySize    = 32
+)
+
+// Envelope encrypts the secrets with envelope encryption: every secret has its own random data key,
+// stored encrypted by a key of the configuration. The sealed value is env1:<key id>:<data key>:<secret>
+type Envelope struct {
+	keys    map[string]cipher.AEAD
+	current string
+}
+
+// NewEnvelope Creates the envelope with the configured keys, the first one seals the new secrets
+func NewEnvelope(ks []cnfs.SecretKeyConfig) (*Envelope, error) {
+
+	if len(ks) == 0 {
+		return nil, fmt.Errorf("At least one secret key must be configured")
+	}
+
+	e := &Envelope{keys: make(map[string]cipher.AEAD), current: ks[0].ID}
+	for _, k := range ks {
+		if k.ID == "" || strings.Contains(k.ID, ":") {
+			return nil, fmt.Errorf("Invalid secret key id %q", k.ID)
+		}
+		key, err := base64.StdEncoding.DecodeString(k.Key)
+		if err != nil || len(key) != dataKeySize {
+			return nil, fmt.Errorf("Secret key %s must be a base64 key of %d bytes", k.ID, dataKeySize)
+		}
+		if e.keys[k.ID], err = newAEAD(key); err != nil {
+			return nil, err
+		}
+	}
+
+	return e, nil
+}
+
+// Seal Encrypts a secret with a new data key, the empty secrets stay empty
+func (e *Envelope) Seal(secret string) (string, error) {
+
+	if secret == "" {
+		return "", nil
+	}
+
+	dk := make([]byte, dataKeySize)
+	if _, err := rand.Read(dk); err != nil {
+		return "", err
+	}
+	aead, err := newAEAD(dk)
+	if err != nil {
+		return "", err
+	}
+
+	wrapped, err := seal(e.keys[e.current], dk)
+	if err != nil {
+		return "", err
+	}
+	data, err := seal(aead, []byte(secret))
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Join([]string{envelopePrefix, e.current, wrapped, data}, ":"), nil
+}
+
+// Open Decrypts a sealed secret. The values not sealed are returned as they are, stored before the encryption
+func (e *Envelope) Open(sealed string) (string, error) {
+
+	parts := strings.Split(sealed, ":")
+	if len(parts) != 4 || parts[0] != envelopePrefix {
+		return sealed, nil
+	}
+
+	kek, ok := e.keys[parts[1]]
+	if !ok {
+		return "", fmt.Errorf("Secret key %s is not configured", parts[1])
+	}
+
+	dk, err := open(kek, parts[2])
+	if err != nil {
+		return "", fmt.Errorf("Error opening the data key: %s", err.Error())
+	}
+	aead, err := newAEAD(dk)
+	if err != nil {
+		return "", err
+	}
+
+	secret, err := open(aead, parts[3])
+	if err != nil {
+		return "", fmt.Errorf("Error opening the secret: %s", err.Error())
+	}
+
+	return string(secret), nil
+}
+
+// Current Checks if the value is sealed with the current key, the empty values don't need it
+func (e *Envelope) Current(value string) bool {
+	return value == "" || strings.HasPrefix(value, envelopePrefix+":"+e.current+":")
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal Encrypts the data, returning the nonce and the cipher text in base64
+func seal(aead cipher.AEAD, data []byte) (string, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.RawStdEncoding.EncodeToString(aead.Seal(nonce, nonce, data, nil)), nil
+}
+
+func open(aead cipher.AEAD, value string) ([]byte, error) {
+	b, err := base64.RawStdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < aead.NonceSize() {
+		return nil, fmt.Errorf("sealed value too short")
+	}
+	return aead.Open(nil, b[:aead.NonceSize()], b[aead.NonceSize():], nil)
+}
@@ -0,0 +1,111 @@
+package secure
+
+import (
+	strut "github.com/pintobikez/popmeet/config/structures"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+var (
+	keyOld = strut.SecretKeyConfig{ID: "2025-01", Key: "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="}
+	keyNew = strut.SecretKeyConfig{ID: "2026-10", Key: "ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA="}
+)
+
+/*
+Provider struct for NewEnvelope method
+*/
+type providerNewEnvelope struct {
+	keys   []strut.SecretKeyConfig
+	iserro bool
+}
+
+var testProviderNewEnvelope = []providerNewEnvelope{
+	{nil, true}, // no keys
+	{[]strut.SecretKeyConfig{{ID: "", Key: keyNew.Key}}, true},        // without id
+	{[]strut.SecretKeyConfig{{ID: "a:b", Key: keyNew.Key}}, true},     // id with the separator
+	{[]strut.SecretKeyConfig{{ID: "short", Key: "MTIzNDU2"}}, true},   // key too short
+	{[]strut.SecretKeyConfig{{ID: "text", Key: "not base64!"}}, true}, // key not in base64
+	{[]strut.SecretKeyConfig{keyNew, keyOld}, false},                  // OK
+}
+
+/* Test for NewEnvelope method */
+func TestNewEnvelope(t *testing.T) {
+
+	for _, pair := range testProviderNewEnvelope {
+
+		_, err := NewEnvelope(pair.keys)
+
+		// Assertions
+		assert.Equal(t, pair.iserro, (err != nil))
+	}
+}
+
+/*
+Provider struct for Seal and Open methods
+*/
+type providerEnvelopeSeal struct {
+	sealKeys []strut.SecretKeyConfig
+	openKeys []strut.SecretKeyConfig
+	secret   string
+	current  bool
+	iserro   bool
+}
+
+var testProviderEnvelopeSeal = []providerEnvelopeSeal{
+	{[]strut.SecretKeyConfig{keyNew}, []strut.SecretKeyConfig{keyNew}, "SECRET-WEB", true, false},          // same key
+	{[]strut.SecretKeyConfig{keyOld}, []strut.SecretKeyConfig{keyNew, keyOld}, "SECRET-WEB", false, false}, // older key still configured
+	{[]strut.SecretKeyConfig{keyOld}, []strut.SecretKeyConfig{keyNew}, "SECRET-WEB", false, true},          // key no longer configured
+	{[]strut.SecretKeyConfig{keyNew}, []strut.SecretKeyConfig{keyNew}, "", true, false},                    // empty secret
+}
+
+/* Test for Seal, Open and Current methods */
+func TestEnvelopeSeal(t *testing.T) {
+
+	for _, pair := range testProviderEnvelopeSeal {
+
+		se, err := NewEnvelope(pair.sealKeys)
+		assert.Nil(t, err)
+		op, err := NewEnvelope(pair.openKeys)
+		assert.Nil(t, err)
+
+		sealed, err := se.Seal(pair.secret)
+		assert.Nil(t, err)
+		assert.False(t, pair.secret != "" && strings.Contains(sealed, pair.secret))
+
+		opened, err := op.Open(sealed)
+
+		// Assertions
+		assert.Equal(t, pair.current, op.Current(sealed))
+		assert.Equal(t, pair.iserro, (err != nil))
+		if !pair.iserro {
+			assert.Equal(t, pair.secret, opened)
+		}
+	}
+}
+
+/* Test for Open method with the secrets stored before the encryption */
+func TestEnvelopeOpenPlain(t *testing.T) {
+
+	e, err := NewEnvelope([]strut.SecretKeyConfig{keyNew})
+	assert.Nil(t, err)
+
+	opened, err := e.Open("SECRET-WEB")
+
+	// Assertions
+	assert.Nil(t, err)
+	assert.Equal(t, "SECRET-WEB", opened)
+	assert.False(t, e.Current("SECRET-WEB"))
+
+	// a tampered value is not opened
+	sealed, _ := e.Seal("SECRET-WEB")
+	tampered := []byte(sealed)
+	i := len("env1:2026-10:") + 2
+	if tampered[i] == 'A' {
+		tampered[i] = 'B'
+	} else {
+		tampered[i] = 'A'
+	}
+	_, err = e.Open(string(tampered))
+	assert.NotNil(t, err)
+}
@@ -1,10 +1,12 @@
 package secure
 
 import (
+	"context"
 	"errors"
 	"github.com/dgrijalva/jwt-go"
 	cnf "github.com/pintobikez/popmeet/config/structures"
 	strut "github.com/pintobikez/popmeet/secure/structures"
+	"github.com/pintobikez/popmeet/tracing"
 	"time"
 )
 
@@ -13,6 +15,12 @@ var (
 	ErrorTokenObject   = errors.New("Invalid token content")
 	ErrorConfigFile    = errors.New("Security Config file not loaded")
 	ErrorConfigValues  = errors.New("Security Config contains errors")
+	ErrorTokenAudience = errors.New("Invalid token audience")
+)
+
+const (
+	challengeAudience   = "2fa_challenge"
+	defaultChallengeTTL = 5
 )
 
 type TokenManager struct {
@@ -57,10 +65,85 @@ func (s *TokenManager) ValidateToken(tokenString string, cipher string) (*strut.
 	}
 
 	// Grab the tokens claims and pass it into the original request
-	if claims, ok := token.Claims.(*strut.TokenClaims); ok && token.Valid {
-		return claims, nil
+	claims, ok := token.Claims.(*strut.TokenClaims)
+	if !ok || !token.Valid {
+		return nil, ErrorTokenObject
+	}
+	// challenge tokens can only be used to finish the login
+	if claims.Audience != "" {
+		return nil, ErrorTokenAudience
+	}
+	return claims, nil
+}
+
+// CreateTokenContext Generates a JWT token in a span of the context
+func (s *TokenManager) CreateTokenContext(ctx context.Context, tk *strut.TokenClaims, cipher string) (string, error) {
+	_, span := tracing.Start(ctx, "TokenManager.CreateToken")
+	token, err := s.CreateToken(tk, cipher)
+	tracing.End(span, err)
+	return token, err
+}
+
+// ValidateTokenContext Validates a JWT token in a span of the context
+func (s *TokenManager) ValidateTokenContext(ctx context.Context, tokenString string, cipher string) (*strut.TokenClaims, error) {
+	_, span := tracing.Start(ctx, "TokenManager.ValidateToken")
+	claims, err := s.ValidateToken(tokenString, cipher)
+	tracing.End(span, err)
+	return claims, err
+}
+
+// CreateSessionToken Generates the JWT token of a login, marking the methods used in the amr claim
+func (s *TokenManager) CreateSessionToken(ctx context.Context, tk *strut.TokenClaims, secondFactor bool) (string, error) {
+
+	tk.Amr = []string{strut.AmrPassword}
+	if secondFactor {
+		tk.Amr = []string{strut.AmrPassword, strut.AmrOtp, strut.AmrMfa}
+	}
+
+	return s.CreateTokenContext(ctx, tk, "")
+}
+
+// CreateChallengeToken Generates the short lived token given after the password check of an user with 2FA
+func (s *TokenManager) CreateChallengeToken(ctx context.Context, tk *strut.TokenClaims) (string, error) {
+	_, span := tracing.Start(ctx, "TokenManager.CreateChallengeToken")
+	defer span.End()
+
+	ttl := s.Config.ChallengeTTL
+	if ttl <= 0 {
+		ttl = defaultChallengeTTL
+	}
+
+	tk.Audience = challengeAudience
+	tk.Amr = []string{strut.AmrPassword}
+	tk.ExpiresAt = time.Now().Add(time.Duration(ttl) * time.Minute).Unix()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, tk)
+	return token.SignedString([]byte(s.Config.CipherKey))
+}
+
+// ValidateChallengeToken Validates a token created by CreateChallengeToken
+func (s *TokenManager) ValidateChallengeToken(ctx context.Context, tokenString string) (*strut.TokenClaims, error) {
+	_, span := tracing.Start(ctx, "TokenManager.ValidateChallengeToken")
+	defer span.End()
+
+	token, err := jwt.ParseWithClaims(tokenString, &strut.TokenClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, ErrorSigningMethod
+		}
+		return []byte(s.Config.CipherKey), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*strut.TokenClaims)
+	if !ok || !token.Valid {
+		return nil, ErrorTokenObject
+	}
+	if claims.Audience != challengeAudience {
+		return nil, ErrorTokenAudience
 	}
-	return nil, ErrorTokenObject
+	return claims, nil
 }
 
 // Health Endpoint of the Client
@@ -1,6 +1,7 @@
 package secure
 
 import (
+	"context"
 	strut "github.com/pintobikez/popmeet/config/structures"
 	. "github.com/pintobikez/popmeet/secure/structures"
 	"github.com/stretchr/testify/assert"
@@ -113,3 +114,28 @@ func TestHealth(t *testing.T) {
 		}
 	}
 }
+
+/* Test for CreateChallengeToken method */
+func TestChallengeToken(t *testing.T) {
+
+	s := &TokenManager{&strut.SecurityConfig{CipherKey: "12312321", TTL: 10}}
+
+	challenge, err := s.CreateChallengeToken(context.Background(), &TokenClaims{ID: 1})
+	assert.Nil(t, err)
+	session, err := s.CreateSessionToken(context.Background(), &TokenClaims{ID: 1}, true)
+	assert.Nil(t, err)
+
+	// Assertions
+	_, err = s.ValidateToken(challenge, "")
+	assert.Equal(t, ErrorTokenAudience, err)
+	_, err = s.ValidateChallengeToken(context.Background(), session)
+	assert.Equal(t, ErrorTokenAudience, err)
+
+	cl, err := s.ValidateChallengeToken(context.Background(), challenge)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), cl.ID)
+
+	cl, err = s.ValidateToken(session, "")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{AmrPassword, AmrOtp, AmrMfa}, cl.Amr)
+}
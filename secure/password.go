@@ -0,0 +1,67 @@
+package secure
+
+import (
+	"bufio"
+	"errors"
+	cnf "github.com/pintobikez/popmeet/config/structures"
+	"os"
+	"strings"
+)
+
+const defaultPasswordMinLength = 8
+
+var (
+	ErrorPasswordTooShort    = errors.New("Password is too short")
+	ErrorPasswordBreached    = errors.New("Password is known to be breached, please choose another one")
+	ErrorPasswordEqualsEmail = errors.New("Password can't be the email address")
+)
+
+// PasswordPolicy validates the passwords chosen by the users
+type PasswordPolicy struct {
+	minLength int
+	breached  map[string]struct{}
+}
+
+// NewPasswordPolicy Builds the policy loading the breached password list, one password per line
+func NewPasswordPolicy(c cnf.PasswordPolicyConfig) (*PasswordPolicy, error) {
+
+	p := &PasswordPolicy{minLength: c.MinLength, breached: make(map[string]struct{})}
+	if p.minLength <= 0 {
+		p.minLength = defaultPasswordMinLength
+	}
+
+	if c.BreachedFile == "" {
+		return p, nil
+	}
+
+	file, err := os.Open(c.BreachedFile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			p.breached[strings.ToLower(line)] = struct{}{}
+		}
+	}
+
+	return p, scanner.Err()
+}
+
+// Validate Checks the password of the user with the given email against the policy
+func (p *PasswordPolicy) Validate(password string, email string) error {
+
+	if len([]rune(password)) < p.minLength {
+		return ErrorPasswordTooShort
+	}
+	if strings.EqualFold(password, email) {
+		return ErrorPasswordEqualsEmail
+	}
+	if _, ok := p.breached[strings.ToLower(password)]; ok {
+		return ErrorPasswordBreached
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package secure
+
+import (
+	strut "github.com/pintobikez/popmeet/config/structures"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+/*
+Provider struct for Validate method
+*/
+type providerPasswordValidate struct {
+	minLength int
+	password  string
+	email     string
+	result    error
+}
+
+var testProviderPasswordValidate = []providerPasswordValidate{
+	{0, "1234567", "teste@popmeet.com", ErrorPasswordTooShort},              // default min length
+	{10, "123456789", "teste@popmeet.com", ErrorPasswordTooShort},           // configured min length
+	{0, "Teste@Popmeet.com", "teste@popmeet.com", ErrorPasswordEqualsEmail}, // equal to the email
+	{0, "Password1", "teste@popmeet.com", ErrorPasswordBreached},            // in the breached list
+	{0, "correct horse battery", "teste@popmeet.com", nil},                  // OK
+}
+
+/* Test for Validate method */
+func TestPasswordValidate(t *testing.T) {
+
+	file, err := ioutil.TempFile("", "breached")
+	assert.Nil(t, err)
+	defer os.Remove(file.Name())
+	file.WriteString("123456\npassword1\n")
+	file.Close()
+
+	for _, pair := range testProviderPasswordValidate {
+
+		p, err := NewPasswordPolicy(strut.PasswordPolicyConfig{MinLength: pair.minLength, BreachedFile: file.Name()})
+		assert.Nil(t, err)
+
+		// Assertions
+		assert.Equal(t, pair.result, p.Validate(pair.password, pair.email))
+	}
+}
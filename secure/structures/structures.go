@@ -7,8 +7,19 @@ type TokenManagerI interface {
 	ValidateToken(token string, cipher string) (*TokenClaims, error)
 }
 
+const (
+	AmrPassword = "pwd"
+	AmrOtp      = "otp"
+	AmrMfa      = "mfa"
+)
+
 type TokenClaims struct {
 	Email string `json:"email"`
 	ID    int64  `json:"id"`
+	Role  string `json:"role,omitempty"`
+	// SessionVersion is the version of the user sessions when the token was created
+	SessionVersion int64 `json:"sv,omitempty"`
+	// Amr lists the authentication methods the user passed to get the token
+	Amr []string `json:"amr,omitempty"`
 	jwt.StandardClaims
 }
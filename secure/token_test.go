@@ -0,0 +1,20 @@
+package secure
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+/* Test for GenerateToken method */
+func TestGenerateToken(t *testing.T) {
+
+	token, hash, err := GenerateToken()
+	other, otherHash, _ := GenerateToken()
+
+	// Assertions
+	assert.Nil(t, err)
+	assert.Equal(t, 64, len(hash))
+	assert.Equal(t, hash, HashToken(token))
+	assert.NotEqual(t, token, other)
+	assert.NotEqual(t, hash, otherHash)
+}
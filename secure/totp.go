@@ -0,0 +1,103 @@
+package secure
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	totpPeriod     = 30
+	totpDigits     = 6
+	totpSkew       = 1
+	totpSecretSize = 20
+	recoveryCodes  = 10
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTotpSecret Creates a random base32 TOTP secret
+func GenerateTotpSecret() (string, error) {
+	b := make([]byte, totpSecretSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return totpEncoding.EncodeToString(b), nil
+}
+
+// TotpURI Builds the otpauth URI used by the authenticator apps to enroll a secret
+func TotpURI(issuer string, account string, secret string) string {
+	v := url.Values{}
+	v.Set("secret", secret)
+	v.Set("issuer", issuer)
+	v.Set("digits", fmt.Sprintf("%d", totpDigits))
+	v.Set("period", fmt.Sprintf("%d", totpPeriod))
+
+	label := url.PathEscape(issuer + ":" + account)
+	return "otpauth://totp/" + label + "?" + v.Encode()
+}
+
+// ValidateTotp Checks a TOTP code at the given time, allowing one step of clock skew.
+// Returns the time step the code belongs to, so it can't be used twice
+func ValidateTotp(secret string, code string, t time.Time) (int64, bool) {
+
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil || len(code) != totpDigits {
+		return 0, false
+	}
+
+	step := t.Unix() / totpPeriod
+	for i := int64(-totpSkew); i <= totpSkew; i++ {
+		if subtle.ConstantTimeCompare([]byte(totpCode(key, step+i)), []byte(code)) == 1 {
+			return step + i, true
+		}
+	}
+
+	return 0, false
+}
+
+// totpCode Gets the code of a time step as defined in RFC 6238
+func totpCode(key []byte, step int64) string {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000)
+}
+
+// GenerateRecoveryCodes Creates the one time recovery codes, returning them and their hashes to be stored
+func GenerateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodes)
+	hashes := make([]string, recoveryCodes)
+
+	for i := range codes {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		code := hex.EncodeToString(b)
+		codes[i] = code[:5] + "-" + code[5:]
+		hashes[i] = HashRecoveryCode(codes[i])
+	}
+
+	return codes, hashes, nil
+}
+
+// HashRecoveryCode Gets the hash of a recovery code, ignoring the case and separators
+func HashRecoveryCode(code string) string {
+	return HashToken(strings.ToLower(strings.Replace(strings.TrimSpace(code), "-", "", -1)))
+}
@@ -0,0 +1,58 @@
+package secure
+
+import (
+	"encoding/base32"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+/*
+Provider struct for ValidateTotp method
+*/
+type providerValidateTotp struct {
+	unix   int64
+	at     int64
+	code   string
+	result bool
+}
+
+// codes from the RFC 6238 test vectors, truncated to 6 digits
+var testProviderValidateTotp = []providerValidateTotp{
+	{59, 59, "287082", true},                 // OK
+	{1111111109, 1111111109, "081804", true}, // OK
+	{59, 89, "287082", true},                 // one step of skew
+	{59, 119, "287082", false},               // too old
+	{59, 59, "287083", false},                // wrong code
+	{59, 59, "28708", false},                 // wrong size
+}
+
+/* Test for ValidateTotp method */
+func TestValidateTotp(t *testing.T) {
+
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+	for _, pair := range testProviderValidateTotp {
+
+		step, ok := ValidateTotp(secret, pair.code, time.Unix(pair.at, 0))
+
+		// Assertions
+		assert.Equal(t, pair.result, ok)
+		if ok {
+			assert.Equal(t, pair.unix/30, step)
+		}
+	}
+}
+
+/* Test for GenerateRecoveryCodes method */
+func TestGenerateRecoveryCodes(t *testing.T) {
+
+	codes, hashes, err := GenerateRecoveryCodes()
+
+	// Assertions
+	assert.Nil(t, err)
+	assert.Equal(t, 10, len(codes))
+	assert.Equal(t, hashes[0], HashRecoveryCode(strings.ToUpper(strings.Replace(codes[0], "-", "", -1))))
+	assert.NotEqual(t, codes[0], codes[1])
+}
@@ -0,0 +1,734 @@
+package tracing
+
+import (
+	"context"
+	"github.com/pintobikez/popmeet/api/models"
+	repo "github.com/pintobikez/popmeet/repository"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+// ContextRepository is a repository that can trace its calls under the span of a context
+type ContextRepository interface {
+	repo.Repository
+	WithContext(ctx context.Context) repo.Repository
+}
+
+// Repository creates a span for every call of the wrapped repository, once bound to a context.
+// Methods not listed here are still served by the wrapped repository, without span
+type Repository struct {
+	repo.Repository
+	ctx context.Context
+}
+
+func NewRepository(rp repo.Repository) *Repository {
+	return &Repository{Repository: rp}
+}
+
+// WithContext Gets a copy of the repository tracing its calls under the span of the context
+func (r *Repository) WithContext(ctx context.Context) repo.Repository {
+	return &Repository{Repository: r.Repository, ctx: ctx}
+}
+
+// start Starts the span of a call, a no-op span when the repository isn't bound to a context
+func (r *Repository) start(method string) trace.Span {
+	if r.ctx == nil {
+		return trace.SpanFromContext(context.Background())
+	}
+	_, span := Start(r.ctx, "Repository."+method, attribute.String("db.system", "mysql"), attribute.String("db.operation", method))
+	return span
+}
+
+func (r *Repository) UpdateUserInterests(interests []*models.Interest, id int64) error {
+	span := r.start("UpdateUserInterests")
+	err := r.Repository.UpdateUserInterests(interests, id)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) GetAllInterestByUserProfileId(id int64) ([]*models.Interest, error) {
+	span := r.start("GetAllInterestByUserProfileId")
+	resp, err := r.Repository.GetAllInterestByUserProfileId(id)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) GetInterestById(id int64) (*models.Interest, error) {
+	span := r.start("GetInterestById")
+	resp, err := r.Repository.GetInterestById(id)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) GetAllInterests() ([]*models.Interest, error) {
+	span := r.start("GetAllInterests")
+	resp, err := r.Repository.GetAllInterests()
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) InsertUser(u *models.User) error {
+	span := r.start("InsertUser")
+	err := r.Repository.InsertUser(u)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) UpdateUser(u *models.User) error {
+	span := r.start("UpdateUser")
+	err := r.Repository.UpdateUser(u)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) GetUserById(id int64) (*models.User, error) {
+	span := r.start("GetUserById")
+	resp, err := r.Repository.GetUserById(id)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) FindUserById(id int64) (bool, error) {
+	span := r.start("FindUserById")
+	resp, err := r.Repository.FindUserById(id)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) FindUserByEmail(email string) (bool, error) {
+	span := r.start("FindUserByEmail")
+	resp, err := r.Repository.FindUserByEmail(email)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) GetUserByEmail(email string) (*models.User, error) {
+	span := r.start("GetUserByEmail")
+	resp, err := r.Repository.GetUserByEmail(email)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) InsertUserProfile(u *models.UserProfile, id int64) error {
+	span := r.start("InsertUserProfile")
+	err := r.Repository.InsertUserProfile(u, id)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) UpdateUserProfile(u *models.UserProfile, id int64) error {
+	span := r.start("UpdateUserProfile")
+	err := r.Repository.UpdateUserProfile(u, id)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) GetUserProfileByUserId(id int64) (*models.UserProfile, error) {
+	span := r.start("GetUserProfileByUserId")
+	resp, err := r.Repository.GetUserProfileByUserId(id)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) GetLanguageById(id int64) (*models.Language, error) {
+	span := r.start("GetLanguageById")
+	resp, err := r.Repository.GetLanguageById(id)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) GetAllLanguage() ([]*models.Language, error) {
+	span := r.start("GetAllLanguage")
+	resp, err := r.Repository.GetAllLanguage()
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) InsertLanguage(l *models.Language) error {
+	span := r.start("InsertLanguage")
+	err := r.Repository.InsertLanguage(l)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) SetLanguageActive(id int64, active bool) error {
+	span := r.start("SetLanguageActive")
+	err := r.Repository.SetLanguageActive(id, active)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) GetUserLanguage(userId int64) (string, error) {
+	span := r.start("GetUserLanguage")
+	resp, err := r.Repository.GetUserLanguage(userId)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) GetAllTranslations() ([]*models.Translation, error) {
+	span := r.start("GetAllTranslations")
+	resp, err := r.Repository.GetAllTranslations()
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) SaveTranslation(t *models.Translation) error {
+	span := r.start("SaveTranslation")
+	err := r.Repository.SaveTranslation(t)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) SetEmailVerified(id int64) error {
+	span := r.start("SetEmailVerified")
+	err := r.Repository.SetEmailVerified(id)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) SetPendingEmail(t *models.UserToken) error {
+	span := r.start("SetPendingEmail")
+	err := r.Repository.SetPendingEmail(t)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) ConfirmPendingEmail(id int64, email string) error {
+	span := r.start("ConfirmPendingEmail")
+	err := r.Repository.ConfirmPendingEmail(id, email)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) InsertUserToken(t *models.UserToken) error {
+	span := r.start("InsertUserToken")
+	err := r.Repository.InsertUserToken(t)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) GetUserTokenByHash(purpose string, hash string) (*models.UserToken, error) {
+	span := r.start("GetUserTokenByHash")
+	resp, err := r.Repository.GetUserTokenByHash(purpose, hash)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) UseUserToken(id int64) (bool, error) {
+	span := r.start("UseUserToken")
+	resp, err := r.Repository.UseUserToken(id)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) InsertUserSecurity(u *models.UserSecurity, id int64) error {
+	span := r.start("InsertUserSecurity")
+	err := r.Repository.InsertUserSecurity(u, id)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) UpdateUserSecurity(u *models.UserSecurity, id int64) error {
+	span := r.start("UpdateUserSecurity")
+	err := r.Repository.UpdateUserSecurity(u, id)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) ChangePassword(id int64, hash string) (int64, error) {
+	span := r.start("ChangePassword")
+	resp, err := r.Repository.ChangePassword(id, hash)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) GetSessionVersion(userId int64) (int64, error) {
+	span := r.start("GetSessionVersion")
+	resp, err := r.Repository.GetSessionVersion(userId)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) SetUserRole(id int64, role string) error {
+	span := r.start("SetUserRole")
+	err := r.Repository.SetUserRole(id, role)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) GetSecurityInfoByUserId(id int64) (*models.UserSecurity, error) {
+	span := r.start("GetSecurityInfoByUserId")
+	resp, err := r.Repository.GetSecurityInfoByUserId(id)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) SetTotpSecret(userId int64, secret string) error {
+	span := r.start("SetTotpSecret")
+	err := r.Repository.SetTotpSecret(userId, secret)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) EnableTotp(userId int64, codeHashes []string) error {
+	span := r.start("EnableTotp")
+	err := r.Repository.EnableTotp(userId, codeHashes)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) DisableTotp(userId int64) error {
+	span := r.start("DisableTotp")
+	err := r.Repository.DisableTotp(userId)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) UseTotpStep(userId int64, step int64) (bool, error) {
+	span := r.start("UseTotpStep")
+	resp, err := r.Repository.UseTotpStep(userId, step)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) UseRecoveryCode(userId int64, hash string) (bool, error) {
+	span := r.start("UseRecoveryCode")
+	resp, err := r.Repository.UseRecoveryCode(userId, hash)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) GetLoginProviderById(id int64) (*models.LoginProvider, error) {
+	span := r.start("GetLoginProviderById")
+	resp, err := r.Repository.GetLoginProviderById(id)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) GetAllLoginProvider() ([]*models.LoginProvider, error) {
+	span := r.start("GetAllLoginProvider")
+	resp, err := r.Repository.GetAllLoginProvider()
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) InsertLoginProvider(p *models.LoginProvider) error {
+	span := r.start("InsertLoginProvider")
+	err := r.Repository.InsertLoginProvider(p)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) UpdateLoginProvider(p *models.LoginProvider) error {
+	span := r.start("UpdateLoginProvider")
+	err := r.Repository.UpdateLoginProvider(p)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) UpdateLoginData(u *models.UserSecurity) error {
+	span := r.start("UpdateLoginData")
+	err := r.Repository.UpdateLoginData(u)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) RecordLoginFailure(id int64, machine string, at time.Time) (int64, error) {
+	span := r.start("RecordLoginFailure")
+	resp, err := r.Repository.RecordLoginFailure(id, machine, at)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) LockUserSecurity(id int64, until time.Time) error {
+	span := r.start("LockUserSecurity")
+	err := r.Repository.LockUserSecurity(id, until)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) ResetLoginFailures(id int64) error {
+	span := r.start("ResetLoginFailures")
+	err := r.Repository.ResetLoginFailures(id)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) AddUserToEvent(idEvent int64, idUser int64) error {
+	span := r.start("AddUserToEvent")
+	err := r.Repository.AddUserToEvent(idEvent, idUser)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) RemoveUserFromEvent(idEvent int64, idUser int64) error {
+	span := r.start("RemoveUserFromEvent")
+	err := r.Repository.RemoveUserFromEvent(idEvent, idUser)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) InsertEvent(u *models.Event) error {
+	span := r.start("InsertEvent")
+	err := r.Repository.InsertEvent(u)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) UpdateEvent(u *models.Event) error {
+	span := r.start("UpdateEvent")
+	err := r.Repository.UpdateEvent(u)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) FindEventById(id int64) (bool, error) {
+	span := r.start("FindEventById")
+	resp, err := r.Repository.FindEventById(id)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) GetEventById(id int64) (*models.Event, error) {
+	span := r.start("GetEventById")
+	resp, err := r.Repository.GetEventById(id)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) GetUserEventsByUserId(id int64) ([]*models.Event, error) {
+	span := r.start("GetUserEventsByUserId")
+	resp, err := r.Repository.GetUserEventsByUserId(id)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) CompletePastEvents(now time.Time) (int64, error) {
+	span := r.start("CompletePastEvents")
+	resp, err := r.Repository.CompletePastEvents(now)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) ArchiveEvents(before time.Time, limit int) (int64, error) {
+	span := r.start("ArchiveEvents")
+	resp, err := r.Repository.ArchiveEvents(before, limit)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) HaveSharedEvent(idUser int64, idOther int64) (bool, error) {
+	span := r.start("HaveSharedEvent")
+	resp, err := r.Repository.HaveSharedEvent(idUser, idOther)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) IsUserBlocked(idUser int64, idOther int64) (bool, error) {
+	span := r.start("IsUserBlocked")
+	resp, err := r.Repository.IsUserBlocked(idUser, idOther)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) BlockUser(idUser int64, idBlocked int64) error {
+	span := r.start("BlockUser")
+	err := r.Repository.BlockUser(idUser, idBlocked)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) UnblockUser(idUser int64, idBlocked int64) error {
+	span := r.start("UnblockUser")
+	err := r.Repository.UnblockUser(idUser, idBlocked)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) GetBlockedUsersByUserId(id int64) ([]*models.User, error) {
+	span := r.start("GetBlockedUsersByUserId")
+	resp, err := r.Repository.GetBlockedUsersByUserId(id)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) GetBlockRelatedUserIds(id int64) ([]int64, error) {
+	span := r.start("GetBlockRelatedUserIds")
+	resp, err := r.Repository.GetBlockRelatedUserIds(id)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) FollowUser(idUser int64, idFollowed int64) error {
+	span := r.start("FollowUser")
+	err := r.Repository.FollowUser(idUser, idFollowed)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) UnfollowUser(idUser int64, idFollowed int64) error {
+	span := r.start("UnfollowUser")
+	err := r.Repository.UnfollowUser(idUser, idFollowed)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) GetFollowersByUserId(id int64) ([]*models.User, error) {
+	span := r.start("GetFollowersByUserId")
+	resp, err := r.Repository.GetFollowersByUserId(id)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) GetFollowingByUserId(id int64) ([]*models.User, error) {
+	span := r.start("GetFollowingByUserId")
+	resp, err := r.Repository.GetFollowingByUserId(id)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) GetFollowerIdsByUserId(id int64) ([]int64, error) {
+	span := r.start("GetFollowerIdsByUserId")
+	resp, err := r.Repository.GetFollowerIdsByUserId(id)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) AreMutualFollowers(idUser int64, idOther int64) (bool, error) {
+	span := r.start("AreMutualFollowers")
+	resp, err := r.Repository.AreMutualFollowers(idUser, idOther)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) GetFeedEventsByUserId(id int64, limit int) ([]*models.Event, error) {
+	span := r.start("GetFeedEventsByUserId")
+	resp, err := r.Repository.GetFeedEventsByUserId(id, limit)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) InsertReport(rp *models.Report) error {
+	span := r.start("InsertReport")
+	err := r.Repository.InsertReport(rp)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) GetReportById(id int64) (*models.Report, error) {
+	span := r.start("GetReportById")
+	resp, err := r.Repository.GetReportById(id)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) GetReportsByStatus(status string) ([]*models.Report, error) {
+	span := r.start("GetReportsByStatus")
+	resp, err := r.Repository.GetReportsByStatus(status)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) GetReportsByTarget(idUser int64, idEvent int64) ([]*models.Report, error) {
+	span := r.start("GetReportsByTarget")
+	resp, err := r.Repository.GetReportsByTarget(idUser, idEvent)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) ResolveReport(ac *models.ModerationAction) error {
+	span := r.start("ResolveReport")
+	err := r.Repository.ResolveReport(ac)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) GetModerationActionsByReportId(id int64) ([]*models.ModerationAction, error) {
+	span := r.start("GetModerationActionsByReportId")
+	resp, err := r.Repository.GetModerationActionsByReportId(id)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) InsertMessage(m *models.Message) error {
+	span := r.start("InsertMessage")
+	err := r.Repository.InsertMessage(m)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) GetMessagesBetweenUsers(idUser int64, idPeer int64, before int64, limit int) ([]*models.Message, error) {
+	span := r.start("GetMessagesBetweenUsers")
+	resp, err := r.Repository.GetMessagesBetweenUsers(idUser, idPeer, before, limit)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) GetConversationsByUserId(id int64) ([]*models.Conversation, error) {
+	span := r.start("GetConversationsByUserId")
+	resp, err := r.Repository.GetConversationsByUserId(id)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) MarkMessagesAsRead(idUser int64, idPeer int64) error {
+	span := r.start("MarkMessagesAsRead")
+	err := r.Repository.MarkMessagesAsRead(idUser, idPeer)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) CountUnreadMessages(id int64) (int64, error) {
+	span := r.start("CountUnreadMessages")
+	resp, err := r.Repository.CountUnreadMessages(id)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) InsertNotification(n *models.Notification) error {
+	span := r.start("InsertNotification")
+	err := r.Repository.InsertNotification(n)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) GetNotificationsByUserId(id int64, unreadOnly bool, limit int) ([]*models.Notification, error) {
+	span := r.start("GetNotificationsByUserId")
+	resp, err := r.Repository.GetNotificationsByUserId(id, unreadOnly, limit)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) MarkNotificationsAsRead(idUser int64, id int64) error {
+	span := r.start("MarkNotificationsAsRead")
+	err := r.Repository.MarkNotificationsAsRead(idUser, id)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) GetNotificationPreferencesByUserId(id int64) ([]*models.NotificationPreference, error) {
+	span := r.start("GetNotificationPreferencesByUserId")
+	resp, err := r.Repository.GetNotificationPreferencesByUserId(id)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) UpdateNotificationPreferences(id int64, ps []*models.NotificationPreference) error {
+	span := r.start("UpdateNotificationPreferences")
+	err := r.Repository.UpdateNotificationPreferences(id, ps)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) InsertJob(j *models.Job) error {
+	span := r.start("InsertJob")
+	err := r.Repository.InsertJob(j)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) ScheduleEventReminders(tp string, before time.Duration, now time.Time, window time.Duration) error {
+	span := r.start("ScheduleEventReminders")
+	err := r.Repository.ScheduleEventReminders(tp, before, now, window)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) ClaimDueJobs(owner string, now time.Time, lease time.Time, limit int) ([]*models.Job, error) {
+	span := r.start("ClaimDueJobs")
+	resp, err := r.Repository.ClaimDueJobs(owner, now, lease, limit)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) CompleteJob(id int64) error {
+	span := r.start("CompleteJob")
+	err := r.Repository.CompleteJob(id)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) FailJob(id int64, msg string, retryAt time.Time, final bool) error {
+	span := r.start("FailJob")
+	err := r.Repository.FailJob(id, msg, retryAt, final)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) RenewJobLease(id int64, owner string, lease time.Time) (bool, error) {
+	span := r.start("RenewJobLease")
+	resp, err := r.Repository.RenewJobLease(id, owner, lease)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) ResetExpiredJobs(now time.Time) (int64, error) {
+	span := r.start("ResetExpiredJobs")
+	resp, err := r.Repository.ResetExpiredJobs(now)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) ScheduleUserDeletion(id int64, at time.Time, hostedEvents string) error {
+	span := r.start("ScheduleUserDeletion")
+	err := r.Repository.ScheduleUserDeletion(id, at, hostedEvents)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) CancelUserDeletion(id int64) error {
+	span := r.start("CancelUserDeletion")
+	err := r.Repository.CancelUserDeletion(id)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) GetUsersToErase(now time.Time, limit int) ([]*models.AccountErasure, error) {
+	span := r.start("GetUsersToErase")
+	resp, err := r.Repository.GetUsersToErase(now, limit)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) EraseUser(id int64, hostedEvents string, now time.Time) ([]*models.HandedOverEvent, error) {
+	span := r.start("EraseUser")
+	resp, err := r.Repository.EraseUser(id, hostedEvents, now)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) GetHostedEventsByUserId(id int64) ([]*models.Event, error) {
+	span := r.start("GetHostedEventsByUserId")
+	resp, err := r.Repository.GetHostedEventsByUserId(id)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) GetJoinedEventsByUserId(id int64) ([]*models.Event, error) {
+	span := r.start("GetJoinedEventsByUserId")
+	resp, err := r.Repository.GetJoinedEventsByUserId(id)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) GetMessagesByUserId(id int64) ([]*models.Message, error) {
+	span := r.start("GetMessagesByUserId")
+	resp, err := r.Repository.GetMessagesByUserId(id)
+	End(span, err)
+	return resp, err
+}
+
+func (r *Repository) InsertAuditEntry(e *models.AuditEntry) error {
+	span := r.start("InsertAuditEntry")
+	err := r.Repository.InsertAuditEntry(e)
+	End(span, err)
+	return err
+}
+
+func (r *Repository) GetAuditEntries(f *models.AuditFilter) ([]*models.AuditEntry, error) {
+	span := r.start("GetAuditEntries")
+	resp, err := r.Repository.GetAuditEntries(f)
+	End(span, err)
+	return resp, err
+}